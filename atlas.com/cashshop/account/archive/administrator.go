@@ -0,0 +1,38 @@
+package archive
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// createEntity persists a snapshot for an account
+func createEntity(db *gorm.DB, tenantId uuid.UUID, accountId uint32, s Snapshot, now time.Time, retention time.Duration) (Entity, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return Entity{}, err
+	}
+	entity := Entity{
+		TenantId:  tenantId,
+		AccountId: accountId,
+		Snapshot:  string(b),
+		CreatedAt: now,
+		ExpiresAt: now.Add(retention),
+	}
+	if err = db.Create(&entity).Error; err != nil {
+		return Entity{}, err
+	}
+	return entity, nil
+}
+
+// deleteEntity deletes an archive by ID
+func deleteEntity(db *gorm.DB, tenantId uuid.UUID, id uuid.UUID) error {
+	return db.Where("tenant_id = ? AND id = ?", tenantId, id).Delete(&Entity{}).Error
+}
+
+// deleteAllExpired deletes every archive past its retention regardless of tenant
+func deleteAllExpired(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("expires_at <= ?", now).Delete(&Entity{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,44 @@
+package archive
+
+import (
+	"atlas-cashshop/database"
+	"encoding/json"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Entity represents an account archive in the database
+type Entity struct {
+	Id        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId  uuid.UUID `gorm:"not null;index:idx_cash_account_archives_account"`
+	AccountId uint32    `gorm:"not null;index:idx_cash_account_archives_account"`
+	Snapshot  string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_account_archives"
+}
+
+// BeforeCreate assigns the ID, so it does not depend on a database generated default
+func (e *Entity) BeforeCreate(_ *gorm.DB) error {
+	return database.AssignId(&e.Id)
+}
+
+// Make converts an Entity to a Model
+func Make(e Entity) (Model, error) {
+	var s Snapshot
+	if err := json.Unmarshal([]byte(e.Snapshot), &s); err != nil {
+		return Model{}, err
+	}
+	return Model{
+		id:        e.Id,
+		accountId: e.AccountId,
+		createdAt: e.CreatedAt,
+		expiresAt: e.ExpiresAt,
+		snapshot:  s,
+	}, nil
+}
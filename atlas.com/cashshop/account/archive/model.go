@@ -0,0 +1,60 @@
+package archive
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/wallet"
+	"atlas-cashshop/wishlist"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Snapshot is the persisted state of an account's cash shop data at the time it was deleted
+type Snapshot struct {
+	Wallets      []wallet.Entity      `json:"wallets"`
+	Compartments []compartment.Entity `json:"compartments"`
+	Assets       []asset.Entity       `json:"assets"`
+	Items        []item.Entity        `json:"items"`
+	Wishlists    []wishlist.Entity    `json:"wishlists"`
+	Characters   []uint32             `json:"characterIds"`
+}
+
+// CharacterIds returns the characters of the account at the time it was archived
+func (s Snapshot) CharacterIds() []uint32 {
+	return s.Characters
+}
+
+// Model is a restorable archive of a deleted account
+type Model struct {
+	id        uuid.UUID
+	accountId uint32
+	createdAt time.Time
+	expiresAt time.Time
+	snapshot  Snapshot
+}
+
+// Id returns the unique identifier of the archive
+func (m Model) Id() uuid.UUID {
+	return m.id
+}
+
+// AccountId returns the account the archive was taken from
+func (m Model) AccountId() uint32 {
+	return m.accountId
+}
+
+// CreatedAt returns when the archive was taken
+func (m Model) CreatedAt() time.Time {
+	return m.createdAt
+}
+
+// ExpiresAt returns when the archive will be purged
+func (m Model) ExpiresAt() time.Time {
+	return m.expiresAt
+}
+
+// Snapshot returns the archived account data
+func (m Model) Snapshot() Snapshot {
+	return m.snapshot
+}
@@ -0,0 +1,147 @@
+package archive
+
+import (
+	"atlas-cashshop/database"
+	"context"
+	"errors"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrAccountExists = errors.New("account already has cash shop data")
+
+// Processor manages restorable snapshots of deleted accounts
+type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
+	ByIdProvider(id uuid.UUID) model.Provider[Model]
+	GetById(id uuid.UUID) (Model, error)
+	ByAccountIdProvider(accountId uint32) model.Provider[[]Model]
+	GetByAccountId(accountId uint32) ([]Model, error)
+	Create(accountId uint32, characterIds []uint32) (Model, error)
+	Restore(id uuid.UUID) (Model, error)
+	Delete(id uuid.UUID) error
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l         logrus.FieldLogger
+	ctx       context.Context
+	db        *gorm.DB
+	t         tenant.Model
+	retention time.Duration
+}
+
+// NewProcessor creates a new Processor using the configured retention
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:         l,
+		ctx:       ctx,
+		db:        db,
+		t:         tenant.MustFromContext(ctx),
+		retention: Retention(),
+	}
+}
+
+// WithTransaction returns a new Processor with the given transaction
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:         p.l,
+		ctx:       p.ctx,
+		db:        tx,
+		t:         p.t,
+		retention: p.retention,
+	}
+}
+
+// ByIdProvider retrieves an archive by ID
+func (p *ProcessorImpl) ByIdProvider(id uuid.UUID) model.Provider[Model] {
+	return model.Map(Make)(getByIdProvider(p.t.Id())(id)(p.db))
+}
+
+// GetById retrieves an archive by ID
+func (p *ProcessorImpl) GetById(id uuid.UUID) (Model, error) {
+	return p.ByIdProvider(id)()
+}
+
+// ByAccountIdProvider retrieves all archives for an account
+func (p *ProcessorImpl) ByAccountIdProvider(accountId uint32) model.Provider[[]Model] {
+	return model.SliceMap(Make)(getByAccountIdProvider(p.t.Id())(accountId)(p.db))(model.ParallelMap())
+}
+
+// GetByAccountId retrieves all archives for an account
+func (p *ProcessorImpl) GetByAccountId(accountId uint32) ([]Model, error) {
+	return p.ByAccountIdProvider(accountId)()
+}
+
+// Create snapshots the current cash shop data of an account, including the wishlists of its characters
+func (p *ProcessorImpl) Create(accountId uint32, characterIds []uint32) (Model, error) {
+	p.l.Debugf("Archiving cash shop data for account [%d]. Retained for [%s].", accountId, p.retention)
+	var result Model
+	txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+		s, err := capture(tx, p.t.Id(), accountId, characterIds)
+		if err != nil {
+			return err
+		}
+		e, err := createEntity(tx, p.t.Id(), accountId, s, time.Now(), p.retention)
+		if err != nil {
+			return err
+		}
+		result, err = Make(e)
+		return err
+	})
+	if txErr != nil {
+		p.l.WithError(txErr).Errorf("Unable to archive cash shop data for account [%d].", accountId)
+		return Model{}, txErr
+	}
+	return result, nil
+}
+
+// Restore re-inserts an archived account and removes the archive
+// Restoring fails with ErrAccountExists when the account has been provisioned again since the archive was taken
+func (p *ProcessorImpl) Restore(id uuid.UUID) (Model, error) {
+	var result Model
+	txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+		m, err := model.Map(Make)(getByIdProvider(p.t.Id())(id)(tx))()
+		if err != nil {
+			return err
+		}
+		p.l.Debugf("Restoring cash shop data for account [%d] from archive [%s].", m.AccountId(), id)
+
+		found, err := exists(tx, p.t.Id(), m.AccountId())
+		if err != nil {
+			return err
+		}
+		if found {
+			return ErrAccountExists
+		}
+
+		// A provisioning racing the restore is caught by the unique indexes
+		err = restore(tx, m.Snapshot())
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrAccountExists
+		}
+		if err != nil {
+			return err
+		}
+		if err = deleteEntity(tx, p.t.Id(), id); err != nil {
+			return err
+		}
+		result = m
+		return nil
+	})
+	if txErr != nil {
+		p.l.WithError(txErr).Errorf("Unable to restore archive [%s].", id)
+		return Model{}, txErr
+	}
+	return result, nil
+}
+
+// Delete purges an archive ahead of its retention
+func (p *ProcessorImpl) Delete(id uuid.UUID) error {
+	p.l.Debugf("Purging archive [%s].", id)
+	return deleteEntity(p.db, p.t.Id(), id)
+}
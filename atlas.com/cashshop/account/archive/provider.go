@@ -0,0 +1,34 @@
+package archive
+
+import (
+	"atlas-cashshop/database"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// getByIdProvider retrieves an archive by ID
+func getByIdProvider(tenantId uuid.UUID) func(id uuid.UUID) database.EntityProvider[Entity] {
+	return func(id uuid.UUID) database.EntityProvider[Entity] {
+		return func(db *gorm.DB) model.Provider[Entity] {
+			return func() (Entity, error) {
+				var entity Entity
+				result := db.Where("tenant_id = ? AND id = ?", tenantId, id).First(&entity)
+				return entity, result.Error
+			}
+		}
+	}
+}
+
+// getByAccountIdProvider retrieves all archives for an account, newest first
+func getByAccountIdProvider(tenantId uuid.UUID) func(accountId uint32) database.EntityProvider[[]Entity] {
+	return func(accountId uint32) database.EntityProvider[[]Entity] {
+		return func(db *gorm.DB) model.Provider[[]Entity] {
+			return func() ([]Entity, error) {
+				var entities []Entity
+				result := db.Where("tenant_id = ? AND account_id = ?", tenantId, accountId).Order("created_at desc").Find(&entities)
+				return entities, result.Error
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package archive
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RestModel represents an account archive for the REST API
+type RestModel struct {
+	Id               uuid.UUID `json:"-"`
+	AccountId        uint32    `json:"accountId"`
+	CreatedAt        time.Time `json:"createdAt"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	CompartmentCount int       `json:"compartmentCount"`
+	AssetCount       int       `json:"assetCount"`
+	WishlistCount    int       `json:"wishlistCount"`
+}
+
+// GetName returns the resource name
+func (r RestModel) GetName() string {
+	return "archives"
+}
+
+// GetID returns the resource ID
+func (r RestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID
+func (r *RestModel) SetID(strId string) error {
+	id, err := uuid.Parse(strId)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// Transform converts a Model to a RestModel
+func Transform(m Model) (RestModel, error) {
+	return RestModel{
+		Id:               m.Id(),
+		AccountId:        m.AccountId(),
+		CreatedAt:        m.CreatedAt(),
+		ExpiresAt:        m.ExpiresAt(),
+		CompartmentCount: len(m.Snapshot().Compartments),
+		AssetCount:       len(m.Snapshot().Assets),
+		WishlistCount:    len(m.Snapshot().Wishlists),
+	}, nil
+}
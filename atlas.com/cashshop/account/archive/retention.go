@@ -0,0 +1,55 @@
+package archive
+
+import (
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"os"
+	"time"
+)
+
+const (
+	EnvRetention          = "ACCOUNT_ARCHIVE_RETENTION"
+	DefaultRetention      = 30 * 24 * time.Hour
+	RetentionTaskInterval = time.Hour
+)
+
+// Retention returns how long archives are kept, falling back to DefaultRetention
+func Retention() time.Duration {
+	if v, ok := os.LookupEnv(EnvRetention); ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultRetention
+}
+
+// RetentionTask periodically purges archives which are past their retention
+type RetentionTask struct {
+	l        logrus.FieldLogger
+	db       *gorm.DB
+	interval time.Duration
+}
+
+// NewRetentionTask creates a task which purges expired archives every interval
+func NewRetentionTask(l logrus.FieldLogger, db *gorm.DB, interval time.Duration) *RetentionTask {
+	return &RetentionTask{
+		l:        l,
+		db:       db,
+		interval: interval,
+	}
+}
+
+func (t *RetentionTask) Run() {
+	count, err := deleteAllExpired(t.db, time.Now())
+	if err != nil {
+		t.l.WithError(err).Errorf("Unable to purge expired account archives.")
+		return
+	}
+	if count > 0 {
+		t.l.Debugf("Purged [%d] expired account archives.", count)
+	}
+}
+
+func (t *RetentionTask) SleepTime() time.Duration {
+	return t.interval
+}
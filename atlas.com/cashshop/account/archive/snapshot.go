@@ -0,0 +1,92 @@
+package archive
+
+import (
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/wallet"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// capture reads every row belonging to an account's cash shop data, with the wishlists of the given characters
+func capture(db *gorm.DB, tenantId uuid.UUID, accountId uint32, characterIds []uint32) (Snapshot, error) {
+	s := Snapshot{Characters: characterIds}
+	if len(characterIds) > 0 {
+		if err := db.Where("tenant_id = ? AND character_id IN ?", tenantId, characterIds).Find(&s.Wishlists).Error; err != nil {
+			return Snapshot{}, err
+		}
+	}
+	if err := db.Where("tenant_id = ? AND account_id = ?", tenantId, accountId).Find(&s.Wallets).Error; err != nil {
+		return Snapshot{}, err
+	}
+	if err := db.Where("tenant_id = ? AND account_id = ?", tenantId, accountId).Find(&s.Compartments).Error; err != nil {
+		return Snapshot{}, err
+	}
+	if len(s.Compartments) == 0 {
+		return s, nil
+	}
+
+	compartmentIds := make([]uuid.UUID, 0, len(s.Compartments))
+	for _, c := range s.Compartments {
+		compartmentIds = append(compartmentIds, c.Id)
+	}
+	if err := db.Where("tenant_id = ? AND compartment_id IN ?", tenantId, compartmentIds).Find(&s.Assets).Error; err != nil {
+		return Snapshot{}, err
+	}
+	if len(s.Assets) == 0 {
+		return s, nil
+	}
+
+	itemIds := make([]uint32, 0, len(s.Assets))
+	for _, a := range s.Assets {
+		itemIds = append(itemIds, a.ItemId)
+	}
+	if err := db.Where("tenant_id = ? AND id IN ?", tenantId, itemIds).Find(&s.Items).Error; err != nil {
+		return Snapshot{}, err
+	}
+	return s, nil
+}
+
+// restore re-inserts the rows of a snapshot with their original identifiers
+func restore(db *gorm.DB, s Snapshot) error {
+	if len(s.Wallets) > 0 {
+		if err := db.Create(&s.Wallets).Error; err != nil {
+			return err
+		}
+	}
+	if len(s.Compartments) > 0 {
+		if err := db.Create(&s.Compartments).Error; err != nil {
+			return err
+		}
+	}
+	if len(s.Items) > 0 {
+		if err := db.Create(&s.Items).Error; err != nil {
+			return err
+		}
+	}
+	if len(s.Assets) > 0 {
+		if err := db.Create(&s.Assets).Error; err != nil {
+			return err
+		}
+	}
+	if len(s.Wishlists) > 0 {
+		if err := db.Create(&s.Wishlists).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exists reports whether the account has live cash shop data which a restore would collide with
+func exists(db *gorm.DB, tenantId uuid.UUID, accountId uint32) (bool, error) {
+	var count int64
+	if err := db.Model(&wallet.Entity{}).Where("tenant_id = ? AND account_id = ?", tenantId, accountId).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.Model(&compartment.Entity{}).Where("tenant_id = ? AND account_id = ?", tenantId, accountId).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
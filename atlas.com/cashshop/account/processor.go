@@ -0,0 +1,243 @@
+package account
+
+import (
+	"atlas-cashshop/account/archive"
+	"atlas-cashshop/cashshop/inventory"
+	"atlas-cashshop/character"
+	"atlas-cashshop/correlation"
+	"atlas-cashshop/database"
+	"atlas-cashshop/kafka/message"
+	compartmentMsg "atlas-cashshop/kafka/message/cashshop/compartment"
+	inventoryMsg "atlas-cashshop/kafka/message/cashshop/inventory"
+	walletMsg "atlas-cashshop/kafka/message/wallet"
+	inventoryProducer "atlas-cashshop/kafka/producer/cashshop/inventory"
+	compartmentProducer "atlas-cashshop/kafka/producer/cashshop/inventory/compartment"
+	walletProducer "atlas-cashshop/kafka/producer/wallet"
+	"atlas-cashshop/outbox"
+	"atlas-cashshop/wallet"
+	"atlas-cashshop/wishlist"
+	"context"
+	"errors"
+	"github.com/Chronicle20/atlas-rest/requests"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Processor coordinates the lifecycle of all cash shop data owned by an account
+type Processor interface {
+	Delete(mb *message.Buffer) func(accountId uint32) (archive.Model, error)
+	DeleteAndEmit(accountId uint32) (archive.Model, error)
+	Restore(mb *message.Buffer) func(archiveId uuid.UUID) (archive.Model, error)
+	RestoreAndEmit(archiveId uuid.UUID) (archive.Model, error)
+	Provision(mb *message.Buffer) func(accountId uint32) (bool, error)
+	ProvisionAndEmit(accountId uint32) (bool, error)
+	Backfill() (BackfillResult, error)
+}
+
+// BackfillResult summarizes a provisioning backfill
+type BackfillResult struct {
+	Scanned     int
+	Provisioned int
+	Failed      int
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l    logrus.FieldLogger
+	ctx  context.Context
+	db   *gorm.DB
+	t    tenant.Model
+	arcP archive.Processor
+	walP wallet.Processor
+	invP inventory.Processor
+	wisP wishlist.Processor
+	chaP character.Processor
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:    l,
+		ctx:  ctx,
+		db:   db,
+		t:    tenant.MustFromContext(ctx),
+		arcP: archive.NewProcessor(l, ctx, db),
+		walP: wallet.NewProcessor(l, ctx, db),
+		invP: inventory.NewProcessor(l, ctx, db),
+		wisP: wishlist.NewProcessor(l, ctx, db),
+		chaP: character.NewProcessor(l, ctx),
+	}
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
+	return &ProcessorImpl{
+		l:    p.l,
+		ctx:  p.ctx,
+		db:   tx,
+		t:    p.t,
+		arcP: p.arcP.WithTransaction(tx),
+		walP: p.walP.WithTransaction(tx),
+		invP: p.invP.WithTransaction(tx),
+		wisP: p.wisP.WithTransaction(tx),
+		chaP: p.chaP,
+	}
+}
+
+// Delete archives and then purges the wallet, compartments, assets, items and wishlists of an account in one transaction
+// Wishlists are removed for the characters of the account, as listed by the character service
+func (p *ProcessorImpl) Delete(mb *message.Buffer) func(accountId uint32) (archive.Model, error) {
+	return func(accountId uint32) (archive.Model, error) {
+		p.l.Debugf("Account [%d] was deleted. Archiving and purging cash shop data...", accountId)
+		characterIds, err := p.chaP.GetIdsByAccountId(accountId)
+		if err != nil {
+			p.l.WithError(err).Errorf("Unable to retrieve characters of account [%d].", accountId)
+			return archive.Model{}, err
+		}
+
+		var result archive.Model
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			am, err := p.arcP.WithTransaction(tx).Create(accountId, characterIds)
+			if err != nil {
+				return err
+			}
+
+			if err = p.walP.WithTransaction(tx).Delete(mb)(accountId); err != nil {
+				p.l.WithError(err).Errorf("Could not delete wallet for account [%d].", accountId)
+				return err
+			}
+
+			if err = p.invP.WithTransaction(tx).Delete(mb)(accountId); err != nil {
+				p.l.WithError(err).Errorf("Could not delete inventory for account [%d].", accountId)
+				return err
+			}
+
+			for _, characterId := range characterIds {
+				if err = p.wisP.WithTransaction(tx).DeleteAll(mb)(characterId); err != nil {
+					p.l.WithError(err).Errorf("Could not delete wishlist for character [%d].", characterId)
+					return err
+				}
+			}
+			result = am
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to delete cash shop data for account [%d].", accountId)
+			return archive.Model{}, txErr
+		}
+		p.l.Debugf("Cash shop data for account [%d] archived as [%s] until [%s].", accountId, result.Id(), result.ExpiresAt())
+		return result, nil
+	}
+}
+
+// DeleteAndEmit deletes the cash shop data of an account and emits the resulting events
+func (p *ProcessorImpl) DeleteAndEmit(accountId uint32) (archive.Model, error) {
+	return outbox.EmitWithResult[archive.Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (archive.Model, error) {
+		return p.withTransaction(tx).Delete(buf)(accountId)
+	})
+}
+
+// Restore re-creates the cash shop data of an account from an archive
+func (p *ProcessorImpl) Restore(mb *message.Buffer) func(archiveId uuid.UUID) (archive.Model, error) {
+	return func(archiveId uuid.UUID) (archive.Model, error) {
+		m, err := p.arcP.Restore(archiveId)
+		if err != nil {
+			return archive.Model{}, err
+		}
+
+		s := m.Snapshot()
+		for _, w := range s.Wallets {
+			_ = mb.Put(walletMsg.EnvEventTopicStatus, walletProducer.CreateStatusEventProvider(correlation.RequestId(p.ctx), w.AccountId, w.Credit, w.Points, w.Prepaid))
+		}
+		for _, c := range s.Compartments {
+			_ = mb.Put(compartmentMsg.EnvEventTopicStatus, compartmentProducer.CreateStatusEventProvider(correlation.RequestId(p.ctx), c.Id, c.Type, c.Capacity))
+		}
+		if len(s.Compartments) > 0 {
+			_ = mb.Put(inventoryMsg.EnvEventTopicStatus, inventoryProducer.CreateStatusEventProvider(m.AccountId()))
+		}
+		p.l.Debugf("Restored cash shop data for account [%d] from archive [%s].", m.AccountId(), archiveId)
+		return m, nil
+	}
+}
+
+// RestoreAndEmit restores an archive and emits the resulting events
+func (p *ProcessorImpl) RestoreAndEmit(archiveId uuid.UUID) (archive.Model, error) {
+	return outbox.EmitWithResult[archive.Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (archive.Model, error) {
+		return p.withTransaction(tx).Restore(buf)(archiveId)
+	})
+}
+
+// Provision ensures the account has a wallet and every default compartment
+// Anything which already exists is left untouched, so replaying the call is safe. The flag reports whether anything was created
+func (p *ProcessorImpl) Provision(mb *message.Buffer) func(accountId uint32) (bool, error) {
+	return func(accountId uint32) (bool, error) {
+		p.l.Debugf("Provisioning cash shop information for account [%d].", accountId)
+		created := false
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			walP := p.walP.WithTransaction(tx)
+			_, err := walP.GetByAccountId(accountId)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				_, err = walP.Create(mb)(accountId)(0)(0)(0)
+				created = true
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not provision wallet for account [%d].", accountId)
+				return err
+			}
+
+			_, compartmentsCreated, err := p.invP.WithTransaction(tx).Create(mb)(accountId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not provision inventory for account [%d].", accountId)
+				return err
+			}
+			created = created || compartmentsCreated
+			return nil
+		})
+		if txErr != nil {
+			return false, txErr
+		}
+		return created, nil
+	}
+}
+
+// ProvisionAndEmit provisions an account and emits the resulting events
+func (p *ProcessorImpl) ProvisionAndEmit(accountId uint32) (bool, error) {
+	return outbox.EmitWithResult[bool](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (bool, error) {
+		return p.withTransaction(tx).Provision(buf)(accountId)
+	})
+}
+
+// Backfill provisions every account known to the account service or to this service which is missing a wallet or compartment
+func (p *ProcessorImpl) Backfill() (BackfillResult, error) {
+	local, err := getKnownAccountIdsProvider(p.t.Id())(p.db)()
+	if err != nil {
+		return BackfillResult{}, err
+	}
+	remote, err := requests.Provider[[]RestModel, []uint32](p.l, p.ctx)(requestAll(), ExtractIds)()
+	if err != nil {
+		p.l.WithError(err).Warnf("Unable to retrieve accounts from the account service. Backfilling known accounts only.")
+	}
+
+	seen := make(map[uint32]struct{})
+	result := BackfillResult{}
+	for _, accountId := range append(remote, local...) {
+		if _, ok := seen[accountId]; ok {
+			continue
+		}
+		seen[accountId] = struct{}{}
+		result.Scanned++
+
+		created, err := p.ProvisionAndEmit(accountId)
+		if err != nil {
+			p.l.WithError(err).Errorf("Unable to backfill account [%d].", accountId)
+			result.Failed++
+			continue
+		}
+		if created {
+			result.Provisioned++
+		}
+	}
+	p.l.Infof("Backfill scanned [%d] accounts. Provisioned [%d]. Failed [%d].", result.Scanned, result.Provisioned, result.Failed)
+	return result, nil
+}
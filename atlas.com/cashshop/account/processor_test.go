@@ -0,0 +1,69 @@
+package account_test
+
+import (
+	"atlas-cashshop/account"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/character"
+	"atlas-cashshop/database/fixture"
+	"atlas-cashshop/rest/stub"
+	"atlas-cashshop/wishlist"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeleteClearsWishlistsOfAccountCharacters(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+
+	// 2000 is a character of the account which never bought anything, 3000 belongs to another account but bought an item now held here
+	characters := stub.NewServer()
+	t.Cleanup(characters.Close)
+	t.Setenv("CHARACTERS", characters.BaseUrl())
+	if err := characters.Put(character.Resource, []character.RestModel{{Id: 2000, AccountId: fixture.AccountId}}); err != nil {
+		t.Fatalf("Unable to stub characters: %v", err)
+	}
+
+	cp := compartment.NewProcessor(l, ctx, db)
+	cm, err := cp.GetByAccountIdAndType(fixture.AccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	im, err := item.NewProcessor(l, ctx, db).CreateAndEmit(5000000, 1, 0, 3000, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unable to create item: %v", err)
+	}
+	if err = cp.AcceptAndEmit(fixture.AccountId, cm.Id(), cm.Type(), im.Id(), uuid.New()); err != nil {
+		t.Fatalf("Unable to accept item: %v", err)
+	}
+
+	wp := wishlist.NewProcessor(l, ctx, db)
+	for _, characterId := range []uint32{2000, 3000} {
+		if _, err = wp.AddAndEmit(characterId, 10000001); err != nil {
+			t.Fatalf("Unable to add wishlist item for character [%d]: %v", characterId, err)
+		}
+	}
+
+	am, err := account.NewProcessor(l, ctx, db).DeleteAndEmit(fixture.AccountId)
+	if err != nil {
+		t.Fatalf("Unable to delete account: %v", err)
+	}
+	if ids := am.Snapshot().CharacterIds(); !slices.Equal(ids, []uint32{2000}) {
+		t.Errorf("Expected the archive to list the characters of the account, got %v", ids)
+	}
+	if n := len(am.Snapshot().Wishlists); n != 1 {
+		t.Errorf("Expected the archive to hold 1 wishlist item, got [%d]", n)
+	}
+
+	for characterId, expected := range map[uint32]int{2000: 0, 3000: 1} {
+		ws, err := wp.GetByCharacterId(characterId)
+		if err != nil {
+			t.Fatalf("Unable to retrieve wishlist of character [%d]: %v", characterId, err)
+		}
+		if len(ws) != expected {
+			t.Errorf("Expected [%d] wishlist items for character [%d], got [%d]", expected, characterId, len(ws))
+		}
+	}
+}
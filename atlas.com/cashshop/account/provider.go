@@ -0,0 +1,27 @@
+package account
+
+import (
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/database"
+	"atlas-cashshop/wallet"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// getKnownAccountIdsProvider retrieves every account which has a wallet or a compartment in the tenant
+func getKnownAccountIdsProvider(tenantId uuid.UUID) database.EntityProvider[[]uint32] {
+	return func(db *gorm.DB) model.Provider[[]uint32] {
+		return func() ([]uint32, error) {
+			var walletIds []uint32
+			if err := db.Model(&wallet.Entity{}).Where("tenant_id = ?", tenantId).Distinct().Pluck("account_id", &walletIds).Error; err != nil {
+				return nil, err
+			}
+			var compartmentIds []uint32
+			if err := db.Model(&compartment.Entity{}).Where("tenant_id = ?", tenantId).Distinct().Pluck("account_id", &compartmentIds).Error; err != nil {
+				return nil, err
+			}
+			return append(walletIds, compartmentIds...), nil
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package account
+
+import (
+	"atlas-cashshop/rest"
+	"github.com/Chronicle20/atlas-rest/requests"
+)
+
+const (
+	Resource = "accounts/"
+)
+
+func getBaseRequest() string {
+	return requests.RootUrl("ACCOUNTS")
+}
+
+func requestAll() requests.Request[[]RestModel] {
+	return rest.MakeGetRequest[[]RestModel](getBaseRequest() + Resource)
+}
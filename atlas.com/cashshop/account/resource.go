@@ -0,0 +1,145 @@
+package account
+
+import (
+	"atlas-cashshop/account/archive"
+	"atlas-cashshop/rest"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/Chronicle20/atlas-rest/server"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// InitResource initializes the account archive and provisioning resources
+func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
+	return func(db *gorm.DB) server.RouteInitializer {
+		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(archive.ErrAccountExists, http.StatusConflict, "ACCOUNT_EXISTS", "Account already has cash shop data")
+
+			register := rest.RegisterHandler(l)(si)
+			r := router.PathPrefix("/accounts/{accountId}/cash-shop/archives").Subrouter()
+			r.HandleFunc("", register("get_account_archives", handleGetArchives(db))).Methods(http.MethodGet)
+			r.HandleFunc("/{archiveId}/restore", register("restore_account_archive", handleRestoreArchive(db))).Methods(http.MethodPost)
+			r.HandleFunc("/{archiveId}", register("delete_account_archive", handleDeleteArchive(db))).Methods(http.MethodDelete)
+			router.HandleFunc("/accounts/{accountId}/cash-shop/provision", register("provision_account", handleProvisionAccount(db))).Methods(http.MethodPost)
+			router.HandleFunc("/cash-shop/backfill", register("backfill_accounts", handleBackfill(db))).Methods(http.MethodPost)
+		}
+	}
+}
+
+// handleGetArchives handles the GET request for the archives of an account
+func handleGetArchives(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				res, err := model.SliceMap(archive.Transform)(archive.NewProcessor(d.Logger(), d.Context(), db).ByAccountIdProvider(accountId))(model.ParallelMap())()
+				if err != nil {
+					d.Logger().WithError(err).Errorf("Creating REST model.")
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+
+				query := r.URL.Query()
+				queryParams := jsonapi.ParseQueryFields(&query)
+				server.MarshalResponse[[]archive.RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(res)
+			}
+		})
+	}
+}
+
+// handleRestoreArchive handles the POST request which restores an archived account
+func handleRestoreArchive(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return rest.ParseArchiveId(d.Logger(), func(archiveId uuid.UUID) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					am, err := archive.NewProcessor(d.Logger(), d.Context(), db).GetById(archiveId)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					if am.AccountId() != accountId {
+						rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("archive does not belong to account"))
+						return
+					}
+
+					_, err = NewProcessor(d.Logger(), d.Context(), db).RestoreAndEmit(archiveId)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					w.WriteHeader(http.StatusNoContent)
+				}
+			})
+		})
+	}
+}
+
+// handleDeleteArchive handles the DELETE request which purges an archive ahead of its retention
+func handleDeleteArchive(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return rest.ParseArchiveId(d.Logger(), func(archiveId uuid.UUID) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					ap := archive.NewProcessor(d.Logger(), d.Context(), db)
+					am, err := ap.GetById(archiveId)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					if am.AccountId() != accountId {
+						rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("archive does not belong to account"))
+						return
+					}
+
+					if err = ap.Delete(archiveId); err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					w.WriteHeader(http.StatusNoContent)
+				}
+			})
+		})
+	}
+}
+
+// handleProvisionAccount handles the POST request which creates any missing wallet or compartment of an account
+func handleProvisionAccount(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				created, err := NewProcessor(d.Logger(), d.Context(), db).ProvisionAndEmit(accountId)
+				if err != nil {
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+				if created {
+					w.WriteHeader(http.StatusCreated)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}
+		})
+	}
+}
+
+// handleBackfill handles the POST request which provisions every known account missing cash shop data
+func handleBackfill(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			res, err := NewProcessor(d.Logger(), d.Context(), db).Backfill()
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to backfill accounts.")
+				rest.WriteError(d.Logger())(w)(err)
+				return
+			}
+
+			query := r.URL.Query()
+			queryParams := jsonapi.ParseQueryFields(&query)
+			server.MarshalResponse[BackfillRestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(TransformBackfill(res))
+		}
+	}
+}
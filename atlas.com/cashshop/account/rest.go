@@ -0,0 +1,75 @@
+package account
+
+import (
+	"github.com/google/uuid"
+	"strconv"
+)
+
+// RestModel is the representation of an account served by the account service
+type RestModel struct {
+	Id   uint32 `json:"-"`
+	Name string `json:"name"`
+}
+
+// GetName returns the resource name
+func (r RestModel) GetName() string {
+	return "accounts"
+}
+
+// GetID returns the resource ID
+func (r RestModel) GetID() string {
+	return strconv.Itoa(int(r.Id))
+}
+
+// SetID sets the resource ID
+func (r *RestModel) SetID(strId string) error {
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		return err
+	}
+	r.Id = uint32(id)
+	return nil
+}
+
+// ExtractIds converts the account service representation into account ids
+func ExtractIds(rms []RestModel) ([]uint32, error) {
+	ids := make([]uint32, 0, len(rms))
+	for _, rm := range rms {
+		ids = append(ids, rm.Id)
+	}
+	return ids, nil
+}
+
+// BackfillRestModel reports the outcome of a provisioning backfill
+type BackfillRestModel struct {
+	Id          string `json:"-"`
+	Scanned     int    `json:"scanned"`
+	Provisioned int    `json:"provisioned"`
+	Failed      int    `json:"failed"`
+}
+
+// GetName returns the resource name
+func (r BackfillRestModel) GetName() string {
+	return "backfills"
+}
+
+// GetID returns the resource ID
+func (r BackfillRestModel) GetID() string {
+	return r.Id
+}
+
+// SetID sets the resource ID
+func (r *BackfillRestModel) SetID(strId string) error {
+	r.Id = strId
+	return nil
+}
+
+// TransformBackfill converts a backfill result into its REST representation
+func TransformBackfill(r BackfillResult) BackfillRestModel {
+	return BackfillRestModel{
+		Id:          uuid.New().String(),
+		Scanned:     r.Scanned,
+		Provisioned: r.Provisioned,
+		Failed:      r.Failed,
+	}
+}
@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// createEntity records an audit entry
+func createEntity(db *gorm.DB, tenantId uuid.UUID, subjectType SubjectType, subjectId uint32, action Action, details string) (Entity, error) {
+	entity := Entity{
+		Id:          uuid.New(),
+		TenantId:    tenantId,
+		SubjectType: string(subjectType),
+		SubjectId:   subjectId,
+		Action:      string(action),
+		Details:     details,
+		CreatedAt:   time.Now(),
+	}
+	if err := db.Create(&entity).Error; err != nil {
+		return Entity{}, err
+	}
+	return entity, nil
+}
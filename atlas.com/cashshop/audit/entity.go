@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Entity represents an audit entry in the database
+type Entity struct {
+	Id          uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId    uuid.UUID `gorm:"type:uuid;not null;index:idx_cash_audit_subject,priority:1"`
+	SubjectType string    `gorm:"not null;index:idx_cash_audit_subject,priority:2"`
+	SubjectId   uint32    `gorm:"not null;index:idx_cash_audit_subject,priority:3"`
+	Action      string    `gorm:"not null"`
+	Details     string    `gorm:"type:text;not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_audit_entries"
+}
+
+// Make converts an Entity to a Model
+func Make(e Entity) (Model, error) {
+	return Model{
+		id:          e.Id,
+		subjectType: SubjectType(e.SubjectType),
+		subjectId:   e.SubjectId,
+		action:      Action(e.Action),
+		details:     e.Details,
+		createdAt:   e.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// SubjectType identifies what an audit entry is about
+type SubjectType string
+
+const (
+	SubjectTypeCharacter = SubjectType("CHARACTER")
+	SubjectTypeAccount   = SubjectType("ACCOUNT")
+)
+
+// Action identifies what happened to the subject
+type Action string
+
+const (
+	ActionCharacterDeleted = Action("CHARACTER_DELETED")
+	ActionItemGranted      = Action("ITEM_GRANTED")
+)
+
+// Model represents a recorded audit entry
+type Model struct {
+	id          uuid.UUID
+	subjectType SubjectType
+	subjectId   uint32
+	action      Action
+	details     string
+	createdAt   time.Time
+}
+
+// Id returns the audit entry ID
+func (m Model) Id() uuid.UUID {
+	return m.id
+}
+
+// SubjectType returns what the entry is about
+func (m Model) SubjectType() SubjectType {
+	return m.subjectType
+}
+
+// SubjectId returns the ID of the subject
+func (m Model) SubjectId() uint32 {
+	return m.subjectId
+}
+
+// Action returns what happened
+func (m Model) Action() Action {
+	return m.action
+}
+
+// Details returns the JSON encoded details of the entry
+func (m Model) Details() string {
+	return m.details
+}
+
+// CreatedAt returns when the entry was recorded
+func (m Model) CreatedAt() time.Time {
+	return m.createdAt
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Processor records and retrieves audit entries
+type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
+	BySubjectProvider(subjectType SubjectType, subjectId uint32) model.Provider[[]Model]
+	GetBySubject(subjectType SubjectType, subjectId uint32) ([]Model, error)
+	Record(subjectType SubjectType, subjectId uint32, action Action, details interface{}) (Model, error)
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	db  *gorm.DB
+	t   tenant.Model
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		db:  db,
+		t:   tenant.MustFromContext(ctx),
+	}
+}
+
+// WithTransaction returns a new Processor with the given transaction
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   p.l,
+		ctx: p.ctx,
+		db:  tx,
+		t:   p.t,
+	}
+}
+
+// BySubjectProvider retrieves the audit entries of a subject, newest first
+func (p *ProcessorImpl) BySubjectProvider(subjectType SubjectType, subjectId uint32) model.Provider[[]Model] {
+	return model.SliceMap(Make)(getBySubjectProvider(p.t.Id())(subjectType)(subjectId)(p.db))(model.ParallelMap())
+}
+
+// GetBySubject retrieves the audit entries of a subject, newest first
+func (p *ProcessorImpl) GetBySubject(subjectType SubjectType, subjectId uint32) ([]Model, error) {
+	return p.BySubjectProvider(subjectType, subjectId)()
+}
+
+// Record stores an audit entry. The details are stored as JSON
+func (p *ProcessorImpl) Record(subjectType SubjectType, subjectId uint32, action Action, details interface{}) (Model, error) {
+	b, err := json.Marshal(details)
+	if err != nil {
+		return Model{}, err
+	}
+	e, err := createEntity(p.db, p.t.Id(), subjectType, subjectId, action, string(b))
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to record audit entry [%s] for [%s] [%d].", action, subjectType, subjectId)
+		return Model{}, err
+	}
+	return Make(e)
+}
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"atlas-cashshop/database"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// getBySubjectProvider retrieves the audit entries of a subject, newest first
+func getBySubjectProvider(tenantId uuid.UUID) func(subjectType SubjectType) func(subjectId uint32) database.EntityProvider[[]Entity] {
+	return func(subjectType SubjectType) func(subjectId uint32) database.EntityProvider[[]Entity] {
+		return func(subjectId uint32) database.EntityProvider[[]Entity] {
+			return func(db *gorm.DB) model.Provider[[]Entity] {
+				return func() ([]Entity, error) {
+					var entities []Entity
+					result := db.Where("tenant_id = ? AND subject_type = ? AND subject_id = ?", tenantId, string(subjectType), subjectId).Order("created_at DESC").Find(&entities)
+					return entities, result.Error
+				}
+			}
+		}
+	}
+}
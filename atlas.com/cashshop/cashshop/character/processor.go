@@ -0,0 +1,151 @@
+package character
+
+import (
+	"atlas-cashshop/audit"
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/correlation"
+	"atlas-cashshop/database"
+	"atlas-cashshop/kafka/message"
+	compartmentMsg "atlas-cashshop/kafka/message/cashshop/compartment"
+	compartmentProducer "atlas-cashshop/kafka/producer/cashshop/inventory/compartment"
+	"atlas-cashshop/outbox"
+	"atlas-cashshop/wishlist"
+	"context"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Processor coordinates the cash shop state owned by a character
+type Processor interface {
+	Delete(mb *message.Buffer) func(characterId uint32) error
+	DeleteAndEmit(characterId uint32) error
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l    logrus.FieldLogger
+	ctx  context.Context
+	db   *gorm.DB
+	t    tenant.Model
+	resP reservation.Processor
+	astP asset.Processor
+	cicP compartment.Processor
+	itmP item.Processor
+	wisP wishlist.Processor
+	audP audit.Processor
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:    l,
+		ctx:  ctx,
+		db:   db,
+		t:    tenant.MustFromContext(ctx),
+		resP: reservation.NewProcessor(l, ctx, db),
+		astP: asset.NewProcessor(l, ctx, db),
+		cicP: compartment.NewProcessor(l, ctx, db),
+		itmP: item.NewProcessor(l, ctx, db),
+		wisP: wishlist.NewProcessor(l, ctx, db),
+		audP: audit.NewProcessor(l, ctx, db),
+	}
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
+	return &ProcessorImpl{
+		l:    p.l,
+		ctx:  p.ctx,
+		db:   tx,
+		t:    p.t,
+		resP: p.resP.WithTransaction(tx),
+		astP: p.astP.WithTransaction(tx),
+		cicP: p.cicP.WithTransaction(tx),
+		itmP: p.itmP.WithTransaction(tx),
+		wisP: p.wisP.WithTransaction(tx),
+		audP: p.audP.WithTransaction(tx),
+	}
+}
+
+// deletionDetails is the audit record of a character deletion
+type deletionDetails struct {
+	ReleasedReservations []uint32 `json:"releasedReservations"`
+	FlaggedItems         int64    `json:"flaggedItems"`
+}
+
+// Delete cleans up the cash shop state of a deleted character
+// Reservations held by the character are released and its wishlist cleared. Items it purchased stay with the account and are flagged for support
+// A character whose deletion was already audited is not cleaned up again, so a redelivered event changes nothing
+func (p *ProcessorImpl) Delete(mb *message.Buffer) func(characterId uint32) error {
+	return func(characterId uint32) error {
+		p.l.Debugf("Character [%d] was deleted. Cleaning up cash shop state...", characterId)
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			es, err := p.audP.WithTransaction(tx).GetBySubject(audit.SubjectTypeCharacter, characterId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not retrieve audit entries of character [%d].", characterId)
+				return err
+			}
+			for _, e := range es {
+				if e.Action() == audit.ActionCharacterDeleted {
+					p.l.Debugf("Cash shop state of character [%d] was already cleaned up.", characterId)
+					return nil
+				}
+			}
+
+			released, err := p.resP.WithTransaction(tx).ReleaseAllByOwner(characterId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not release reservations held by character [%d].", characterId)
+				return err
+			}
+			details := deletionDetails{ReleasedReservations: make([]uint32, 0, len(released))}
+			for _, r := range released {
+				details.ReleasedReservations = append(details.ReleasedReservations, r.ItemId())
+				p.putReservationCancelled(tx, mb, r)
+			}
+
+			if err = p.wisP.WithTransaction(tx).DeleteAll(mb)(characterId); err != nil {
+				p.l.WithError(err).Errorf("Could not delete wishlist for character [%d].", characterId)
+				return err
+			}
+
+			details.FlaggedItems, err = p.itmP.WithTransaction(tx).FlagPurchaserDeleted(characterId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not flag items purchased by character [%d].", characterId)
+				return err
+			}
+
+			_, err = p.audP.WithTransaction(tx).Record(audit.SubjectTypeCharacter, characterId, audit.ActionCharacterDeleted, details)
+			return err
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to clean up cash shop state for character [%d].", characterId)
+			return txErr
+		}
+		return nil
+	}
+}
+
+// putReservationCancelled announces a released reservation on the compartment which holds the asset
+func (p *ProcessorImpl) putReservationCancelled(tx *gorm.DB, mb *message.Buffer, r reservation.Model) {
+	a, err := p.astP.WithTransaction(tx).GetByItemId(r.ItemId())
+	if err != nil {
+		p.l.WithError(err).Warnf("Released reservation of item [%d] which is no longer held by a compartment.", r.ItemId())
+		return
+	}
+	c, err := p.cicP.WithTransaction(tx).GetById(a.CompartmentId())
+	if err != nil {
+		p.l.WithError(err).Warnf("Unable to locate compartment [%s] of item [%d].", a.CompartmentId(), r.ItemId())
+		return
+	}
+	_ = mb.Put(compartmentMsg.EnvEventTopicStatus, compartmentProducer.ReservationCancelledStatusEventProvider(correlation.RequestId(p.ctx), c.Id(), byte(c.Type()), r.ItemId(), r.TransactionId()))
+}
+
+// DeleteAndEmit cleans up the cash shop state of a deleted character and emits the resulting events
+func (p *ProcessorImpl) DeleteAndEmit(characterId uint32) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Delete(buf)(characterId)
+	})
+}
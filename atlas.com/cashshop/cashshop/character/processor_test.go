@@ -0,0 +1,126 @@
+package character_test
+
+import (
+	"atlas-cashshop/audit"
+	"atlas-cashshop/cashshop/character"
+	"atlas-cashshop/cashshop/grant"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/database/fixture"
+	"atlas-cashshop/kafka/message"
+	compartmentMessage "atlas-cashshop/kafka/message/cashshop/compartment"
+	"atlas-cashshop/wishlist"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testCharacterId = uint32(2000)
+
+func TestDelete(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(fixture.AccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	gm := grant.NewBuilder(5000000).SetExpiration(time.Now().Add(time.Hour)).SetReason("test").Build()
+	am, err := grant.NewProcessor(l, ctx, db).GrantAndEmit(fixture.AccountId, cm.Id(), gm)
+	if err != nil {
+		t.Fatalf("Unable to grant item: %v", err)
+	}
+	transactionId := uuid.New()
+	resP := reservation.NewProcessor(l, ctx, db)
+	if _, err = resP.Reserve(am.Item().Id(), testCharacterId, transactionId); err != nil {
+		t.Fatalf("Unable to reserve asset: %v", err)
+	}
+	if _, err = wishlist.NewProcessor(l, ctx, db).AddAndEmit(testCharacterId, 10000000); err != nil {
+		t.Fatalf("Unable to add to wishlist: %v", err)
+	}
+	itmP := item.NewProcessor(l, ctx, db)
+	purchased, err := itmP.CreateAndEmit(5000000, 1, 0, testCharacterId, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unable to create item: %v", err)
+	}
+	other, err := itmP.CreateAndEmit(5000000, 1, 0, testCharacterId+1, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unable to create item: %v", err)
+	}
+
+	cp := character.NewProcessor(l, ctx, db)
+	buf := message.NewBuffer()
+	if err = cp.Delete(buf)(testCharacterId); err != nil {
+		t.Fatalf("Unable to delete character: %v", err)
+	}
+
+	if _, err = resP.GetByItemId(am.Item().Id()); !errors.Is(err, reservation.ErrNotFound) {
+		t.Errorf("Expected reservation of asset [%d] to be released, got %v", am.Item().Id(), err)
+	}
+	ms := buf.GetAll()[compartmentMessage.EnvEventTopicStatus]
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 status event, got [%d]", len(ms))
+	}
+	var e compartmentMessage.StatusEvent[compartmentMessage.StatusEventReservationCancelledBody]
+	if err = json.Unmarshal(ms[0].Value, &e); err != nil {
+		t.Fatalf("Unable to decode status event: %v", err)
+	}
+	if e.Type != compartmentMessage.StatusEventTypeReservationCancelled || e.CompartmentId != cm.Id() || e.Body.AssetId != am.Item().Id() || e.Body.TransactionId != transactionId {
+		t.Errorf("Unexpected status event %+v", e)
+	}
+
+	ws, err := wishlist.NewProcessor(l, ctx, db).GetByCharacterId(testCharacterId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve wishlist: %v", err)
+	}
+	if len(ws) != 0 {
+		t.Errorf("Expected wishlist to be cleared, got [%d] items", len(ws))
+	}
+
+	im, err := itmP.GetById(purchased.Id())
+	if err != nil {
+		t.Fatalf("Unable to retrieve item: %v", err)
+	}
+	if !im.PurchaserDeleted() || im.PurchaserDeletedAt() == nil {
+		t.Errorf("Expected item [%d] to be flagged as bought by a deleted character", im.Id())
+	}
+	flaggedAt := *im.PurchaserDeletedAt()
+	if om, err := itmP.GetById(other.Id()); err != nil || om.PurchaserDeleted() {
+		t.Errorf("Expected item [%d] of another character to be left alone: %v", other.Id(), err)
+	}
+
+	es, err := audit.NewProcessor(l, ctx, db).GetBySubject(audit.SubjectTypeCharacter, testCharacterId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve audit entries: %v", err)
+	}
+	if len(es) != 1 || es[0].Action() != audit.ActionCharacterDeleted {
+		t.Fatalf("Expected 1 [%s] audit entry, got [%d]", audit.ActionCharacterDeleted, len(es))
+	}
+	var details struct {
+		ReleasedReservations []uint32 `json:"releasedReservations"`
+		FlaggedItems         int64    `json:"flaggedItems"`
+	}
+	if err = json.Unmarshal([]byte(es[0].Details()), &details); err != nil {
+		t.Fatalf("Unable to decode audit details: %v", err)
+	}
+	if len(details.ReleasedReservations) != 1 || details.ReleasedReservations[0] != am.Item().Id() || details.FlaggedItems != 1 {
+		t.Errorf("Unexpected audit details %+v", details)
+	}
+
+	// A redelivered deletion changes nothing
+	buf = message.NewBuffer()
+	if err = cp.Delete(buf)(testCharacterId); err != nil {
+		t.Fatalf("Unable to delete character again: %v", err)
+	}
+	if ms = buf.GetAll()[compartmentMessage.EnvEventTopicStatus]; len(ms) != 0 {
+		t.Errorf("Expected no status events on a second deletion, got [%d]", len(ms))
+	}
+	if es, err = audit.NewProcessor(l, ctx, db).GetBySubject(audit.SubjectTypeCharacter, testCharacterId); err != nil || len(es) != 1 {
+		t.Errorf("Expected a second deletion to leave 1 audit entry, got [%d]: %v", len(es), err)
+	}
+	if im, err = itmP.GetById(purchased.Id()); err != nil || im.PurchaserDeletedAt() == nil || !im.PurchaserDeletedAt().Equal(flaggedAt) {
+		t.Errorf("Expected a second deletion to keep item [%d] flagged at [%s]: %v", purchased.Id(), flaggedAt, err)
+	}
+}
@@ -2,11 +2,14 @@ package commodity
 
 import (
 	"context"
+	"errors"
 	"github.com/Chronicle20/atlas-rest/requests"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
 )
 
+var ErrNotFound = errors.New("commodity not found")
+
 type Processor interface {
 	GetById(itemId uint32) (Model, error)
 }
@@ -26,6 +29,11 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
 	return p
 }
 
+// GetById retrieves the commodity with the serial number, or ErrNotFound when DATA has none
 func (p *ProcessorImpl) GetById(itemId uint32) (Model, error) {
-	return requests.Provider[RestModel, Model](p.l, p.ctx)(requestById(itemId), Extract)()
+	m, err := requests.Provider[RestModel, Model](p.l, p.ctx)(requestById(itemId), Extract)()
+	if errors.Is(err, requests.ErrNotFound) {
+		return Model{}, ErrNotFound
+	}
+	return m, err
 }
@@ -0,0 +1,60 @@
+package cashshop
+
+import (
+	"atlas-cashshop/cashshop/commodity"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/character"
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/retry"
+	"errors"
+)
+
+// Error rejects a cash shop request for a reason the client is told about through its error code
+type Error struct {
+	code    string
+	message string
+}
+
+func newError(code string, message string) *Error {
+	return &Error{code: code, message: message}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Code returns the error code reported in the error status event
+func (e *Error) Code() string {
+	return e.code
+}
+
+var ErrInsufficientFunds = newError(cashshop.ErrorCodeNotEnoughCash, "insufficient funds")
+var ErrMaxSlots = newError(cashshop.ErrorCodeInventorySlotLimit, "max slots")
+var ErrInventoryFull = newError(cashshop.ErrorCodeInventoryFull, "cash inventory full")
+var ErrNotSupported = newError(cashshop.ErrorCodeRequestNotSupported, "request not supported")
+
+// IsRejection reports whether err rejects the request on its merits, so trying again cannot change the outcome
+// Besides cash shop errors, a request is rejected by a commodity or character which does not exist, or a compartment which refuses the item
+func IsRejection(err error) bool {
+	var e *Error
+	return errors.As(err, &e) || errors.Is(err, commodity.ErrNotFound) || errors.Is(err, character.ErrNotFound) || compartment.IsRejection(err)
+}
+
+// ErrorCode translates the error a request failed with into the code reported to the client
+// Requests which kept failing until their retries ran out are reported as timed out, so the player may try again
+func ErrorCode(err error) string {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e.Code()
+	case errors.Is(err, commodity.ErrNotFound):
+		return cashshop.ErrorCodeCommodityNotFound
+	case errors.Is(err, character.ErrNotFound):
+		return cashshop.ErrorCodeCharacterNotFound
+	case errors.Is(err, compartment.ErrCompartmentFull):
+		return cashshop.ErrorCodeInventoryFull
+	case errors.Is(err, retry.ErrMaxRetries):
+		return cashshop.ErrorCodeRequestTimedOut
+	}
+	return cashshop.ErrorCodeUnknown
+}
@@ -0,0 +1,51 @@
+package cashshop
+
+import (
+	"atlas-cashshop/cashshop/commodity"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/character"
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/retry"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	cause := errors.New("connection refused")
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"insufficient funds", ErrInsufficientFunds, cashshop.ErrorCodeNotEnoughCash},
+		{"wrapped insufficient funds", fmt.Errorf("purchase: %w", ErrInsufficientFunds), cashshop.ErrorCodeNotEnoughCash},
+		{"max slots", ErrMaxSlots, cashshop.ErrorCodeInventorySlotLimit},
+		{"inventory full", ErrInventoryFull, cashshop.ErrorCodeInventoryFull},
+		{"not supported", ErrNotSupported, cashshop.ErrorCodeRequestNotSupported},
+		{"commodity not found", fmt.Errorf("purchase: %w", commodity.ErrNotFound), cashshop.ErrorCodeCommodityNotFound},
+		{"character not found", character.ErrNotFound, cashshop.ErrorCodeCharacterNotFound},
+		{"compartment full", compartment.ErrCompartmentFull, cashshop.ErrorCodeInventoryFull},
+		{"retries exhausted", fmt.Errorf("%w: %w", retry.ErrMaxRetries, cause), cashshop.ErrorCodeRequestTimedOut},
+		{"unclassified", cause, cashshop.ErrorCodeUnknown},
+	}
+	for _, c := range cases {
+		if code := ErrorCode(c.err); code != c.expected {
+			t.Errorf("%s: expected code [%s], got [%s]", c.name, c.expected, code)
+		}
+	}
+}
+
+func TestIsRejection(t *testing.T) {
+	if !IsRejection(fmt.Errorf("purchase: %w", ErrMaxSlots)) {
+		t.Errorf("Expected a wrapped cash shop error to be a rejection.")
+	}
+	for _, err := range []error{commodity.ErrNotFound, character.ErrNotFound, compartment.ErrCompartmentMismatch} {
+		if !IsRejection(err) {
+			t.Errorf("Expected [%v] to be a rejection.", err)
+		}
+	}
+	if IsRejection(errors.New("connection refused")) {
+		t.Errorf("Expected an unclassified error not to be a rejection.")
+	}
+}
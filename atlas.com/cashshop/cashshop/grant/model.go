@@ -0,0 +1,100 @@
+package grant
+
+import "time"
+
+// Model represents an item granted by support staff, along with who granted it and why
+type Model struct {
+	templateId uint32
+	quantity   uint32
+	flag       uint16
+	expiration time.Time
+	reason     string
+	grantedBy  string
+}
+
+// TemplateId returns the template of the granted item
+func (m Model) TemplateId() uint32 {
+	return m.templateId
+}
+
+// Quantity returns the quantity of the granted item
+func (m Model) Quantity() uint32 {
+	return m.quantity
+}
+
+// Flag returns the flags of the granted item
+func (m Model) Flag() uint16 {
+	return m.flag
+}
+
+// Expiration returns when the granted item expires
+func (m Model) Expiration() time.Time {
+	return m.expiration
+}
+
+// Reason returns why the item was granted
+func (m Model) Reason() string {
+	return m.reason
+}
+
+// GrantedBy returns the support staff member who granted the item
+func (m Model) GrantedBy() string {
+	return m.grantedBy
+}
+
+// Builder is a builder for the Model
+type Builder struct {
+	templateId uint32
+	quantity   uint32
+	flag       uint16
+	expiration time.Time
+	reason     string
+	grantedBy  string
+}
+
+// NewBuilder creates a builder for a grant of the template
+func NewBuilder(templateId uint32) *Builder {
+	return &Builder{templateId: templateId, quantity: 1}
+}
+
+// SetQuantity sets the quantity of the granted item
+func (b *Builder) SetQuantity(quantity uint32) *Builder {
+	b.quantity = quantity
+	return b
+}
+
+// SetFlag sets the flags of the granted item
+func (b *Builder) SetFlag(flag uint16) *Builder {
+	b.flag = flag
+	return b
+}
+
+// SetExpiration sets when the granted item expires
+func (b *Builder) SetExpiration(expiration time.Time) *Builder {
+	b.expiration = expiration
+	return b
+}
+
+// SetReason sets why the item was granted
+func (b *Builder) SetReason(reason string) *Builder {
+	b.reason = reason
+	return b
+}
+
+// SetGrantedBy sets the support staff member who granted the item
+func (b *Builder) SetGrantedBy(grantedBy string) *Builder {
+	b.grantedBy = grantedBy
+	return b
+}
+
+// Build creates the Model
+func (b *Builder) Build() Model {
+	return Model{
+		templateId: b.templateId,
+		quantity:   b.quantity,
+		flag:       b.flag,
+		expiration: b.expiration,
+		reason:     b.reason,
+		grantedBy:  b.grantedBy,
+	}
+}
@@ -0,0 +1,139 @@
+package grant
+
+import (
+	"atlas-cashshop/audit"
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/database"
+	"atlas-cashshop/kafka/message"
+	"atlas-cashshop/outbox"
+	"context"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Processor places items granted by support staff into the compartments of accounts
+type Processor interface {
+	Grant(mb *message.Buffer) func(accountId uint32, compartmentId uuid.UUID, m Model) (asset.Model, error)
+	GrantAndEmit(accountId uint32, compartmentId uuid.UUID, m Model) (asset.Model, error)
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l    logrus.FieldLogger
+	ctx  context.Context
+	db   *gorm.DB
+	t    tenant.Model
+	cicP compartment.Processor
+	itmP item.Processor
+	astP asset.Processor
+	audP audit.Processor
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:    l,
+		ctx:  ctx,
+		db:   db,
+		t:    tenant.MustFromContext(ctx),
+		cicP: compartment.NewProcessor(l, ctx, db),
+		itmP: item.NewProcessor(l, ctx, db),
+		astP: asset.NewProcessor(l, ctx, db),
+		audP: audit.NewProcessor(l, ctx, db),
+	}
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
+	return &ProcessorImpl{
+		l:    p.l,
+		ctx:  p.ctx,
+		db:   tx,
+		t:    p.t,
+		cicP: p.cicP.WithTransaction(tx),
+		itmP: p.itmP.WithTransaction(tx),
+		astP: p.astP.WithTransaction(tx),
+		audP: p.audP.WithTransaction(tx),
+	}
+}
+
+// grantDetails is the audit record of a granted item
+type grantDetails struct {
+	CompartmentId uuid.UUID `json:"compartmentId"`
+	AssetId       uuid.UUID `json:"assetId"`
+	ItemId        uint32    `json:"itemId"`
+	CashId        int64     `json:"cashId"`
+	TemplateId    uint32    `json:"templateId"`
+	Quantity      uint32    `json:"quantity"`
+	Flag          uint16    `json:"flag"`
+	Expiration    time.Time `json:"expiration"`
+	Reason        string    `json:"reason"`
+	GrantedBy     string    `json:"grantedBy"`
+}
+
+// Grant places the item into the compartment of the account without charging its wallet, and records an audit entry for the account naming who granted it
+func (p *ProcessorImpl) Grant(mb *message.Buffer) func(accountId uint32, compartmentId uuid.UUID, m Model) (asset.Model, error) {
+	return func(accountId uint32, compartmentId uuid.UUID, m Model) (asset.Model, error) {
+		var result asset.Model
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			ccm, err := p.cicP.WithTransaction(tx).GetById(compartmentId)
+			if err != nil {
+				return err
+			}
+			if ccm.AccountId() != accountId {
+				return compartment.ErrCompartmentMismatch
+			}
+			if ccm.Capacity() <= uint32(len(ccm.Assets())) {
+				p.l.Debugf("Compartment [%s] of account [%d] is full. Capacity [%d].", ccm.Id(), accountId, ccm.Capacity())
+				return compartment.ErrCompartmentFull
+			}
+
+			im, err := p.itmP.WithTransaction(tx).Create(mb)(m.TemplateId())(m.Quantity())(m.Flag())(0)(m.Expiration())
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to create granted item [%d] for account [%d].", m.TemplateId(), accountId)
+				return err
+			}
+			am, err := p.astP.WithTransaction(tx).Create(mb)(ccm.Id())(im.Id())
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to create asset for granted item [%d] for account [%d].", m.TemplateId(), accountId)
+				return err
+			}
+
+			details := grantDetails{
+				CompartmentId: ccm.Id(),
+				AssetId:       am.Id(),
+				ItemId:        im.Id(),
+				CashId:        im.CashId(),
+				TemplateId:    im.TemplateId(),
+				Quantity:      im.Quantity(),
+				Flag:          im.Flag(),
+				Expiration:    im.Expiration(),
+				Reason:        m.Reason(),
+				GrantedBy:     m.GrantedBy(),
+			}
+			_, err = p.audP.WithTransaction(tx).Record(audit.SubjectTypeAccount, accountId, audit.ActionItemGranted, details)
+			if err != nil {
+				return err
+			}
+
+			p.l.Infof("[%s] granted item [%d] to compartment [%s] of account [%d]. Reason [%s].", m.GrantedBy(), m.TemplateId(), ccm.Id(), accountId, m.Reason())
+			result = am
+			return nil
+		})
+		if txErr != nil {
+			return asset.Model{}, txErr
+		}
+		return result, nil
+	}
+}
+
+// GrantAndEmit grants the item and emits the resulting events
+func (p *ProcessorImpl) GrantAndEmit(accountId uint32, compartmentId uuid.UUID, m Model) (asset.Model, error) {
+	return outbox.EmitWithResult[asset.Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (asset.Model, error) {
+		return p.withTransaction(tx).Grant(buf)(accountId, compartmentId, m)
+	})
+}
@@ -0,0 +1,99 @@
+package grant_test
+
+import (
+	"atlas-cashshop/audit"
+	"atlas-cashshop/cashshop/grant"
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/database/fixture"
+	"atlas-cashshop/wallet"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testAccountId = fixture.AccountId
+
+func TestGrant(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	if _, err := wallet.NewProcessor(l, ctx, db).UpdateAndEmit(testAccountId, 500, 0, 0); err != nil {
+		t.Fatalf("Unable to fund wallet: %v", err)
+	}
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(testAccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+
+	expiration := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	gm := grant.NewBuilder(5000000).SetQuantity(2).SetFlag(1).SetExpiration(expiration).SetReason("compensation for ticket 42").SetGrantedBy("gm-alice").Build()
+	am, err := grant.NewProcessor(l, ctx, db).GrantAndEmit(testAccountId, cm.Id(), gm)
+	if err != nil {
+		t.Fatalf("Unable to grant item: %v", err)
+	}
+
+	a, err := asset.NewProcessor(l, ctx, db).GetById(am.Id())
+	if err != nil {
+		t.Fatalf("Unable to retrieve granted asset: %v", err)
+	}
+	if a.CompartmentId() != cm.Id() || a.TemplateId() != 5000000 || a.Quantity() != 2 || a.Item().Flag() != 1 || !a.Expiration().Equal(expiration) {
+		t.Errorf("Unexpected granted asset in [%s] of template [%d] quantity [%d] flag [%d] expiring [%s]", a.CompartmentId(), a.TemplateId(), a.Quantity(), a.Item().Flag(), a.Expiration())
+	}
+
+	w, err := wallet.NewProcessor(l, ctx, db).GetByAccountId(testAccountId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve wallet: %v", err)
+	}
+	if w.Credit() != 500 {
+		t.Errorf("Expected wallet to be untouched, got credit [%d]", w.Credit())
+	}
+
+	es, err := audit.NewProcessor(l, ctx, db).GetBySubject(audit.SubjectTypeAccount, testAccountId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve audit entries: %v", err)
+	}
+	if len(es) != 1 || es[0].Action() != audit.ActionItemGranted {
+		t.Fatalf("Expected 1 [%s] audit entry, got [%d]", audit.ActionItemGranted, len(es))
+	}
+	var details struct {
+		AssetId   uuid.UUID `json:"assetId"`
+		Reason    string    `json:"reason"`
+		GrantedBy string    `json:"grantedBy"`
+	}
+	if err = json.Unmarshal([]byte(es[0].Details()), &details); err != nil {
+		t.Fatalf("Unable to decode audit details: %v", err)
+	}
+	if details.AssetId != am.Id() || details.Reason != "compensation for ticket 42" || details.GrantedBy != "gm-alice" {
+		t.Errorf("Unexpected audit details %+v", details)
+	}
+}
+
+func TestGrantRejected(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(testAccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	gm := grant.NewBuilder(5000000).SetExpiration(time.Now().Add(time.Hour)).SetReason("test").Build()
+
+	if _, err = grant.NewProcessor(l, ctx, db).GrantAndEmit(testAccountId+1, cm.Id(), gm); !errors.Is(err, compartment.ErrCompartmentMismatch) {
+		t.Errorf("Expected a grant into the compartment of another account to be rejected, got [%v]", err)
+	}
+
+	if _, err = compartment.NewProcessor(l, ctx, db).UpdateCapacityAndEmit(cm.Id(), 0); err != nil {
+		t.Fatalf("Unable to shrink compartment: %v", err)
+	}
+	if _, err = grant.NewProcessor(l, ctx, db).GrantAndEmit(testAccountId, cm.Id(), gm); !errors.Is(err, compartment.ErrCompartmentFull) {
+		t.Errorf("Expected a grant into a full compartment to be rejected, got [%v]", err)
+	}
+
+	es, err := audit.NewProcessor(l, ctx, db).GetBySubject(audit.SubjectTypeAccount, testAccountId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve audit entries: %v", err)
+	}
+	if len(es) != 0 {
+		t.Errorf("Expected rejected grants not to be audited, got [%d] entries", len(es))
+	}
+}
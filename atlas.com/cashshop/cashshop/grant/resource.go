@@ -0,0 +1,86 @@
+package grant
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/rest"
+	"errors"
+	"github.com/Chronicle20/atlas-rest/server"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+// InitResource initializes the item grant resource used by support staff
+// The service does not authenticate callers itself. Grants are mounted under the admin prefix, which the gateway must only expose to support tooling
+func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
+	return func(db *gorm.DB) server.RouteInitializer {
+		return func(router *mux.Router, l logrus.FieldLogger) {
+			r := router.PathPrefix(AdminPrefix + "/accounts/{accountId}/cash-shop/inventory/compartments/{compartmentId}/grants").Subrouter()
+			r.HandleFunc("", rest.RegisterInputHandler[RestModel](l)(si)("grant_cash_item", handleGrant(db))).Methods(http.MethodPost)
+		}
+	}
+}
+
+// AdminPrefix is the path prefix of routes reserved for support staff
+const AdminPrefix = "/admin"
+
+// handleGrant handles the POST request which places an item into the compartment without charging the wallet
+func handleGrant(db *gorm.DB) rest.InputHandler[RestModel] {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext, input RestModel) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return rest.ParseCompartmentId(d.Logger(), func(compartmentId uuid.UUID) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					if input.TemplateId == 0 {
+						rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("templateId", "a grant needs a template"))
+						return
+					}
+					if input.Quantity == 0 {
+						rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("quantity", "a grant needs a quantity of at least 1"))
+						return
+					}
+					if strings.TrimSpace(input.Reason) == "" {
+						rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("reason", "a grant needs a reason for the audit log"))
+						return
+					}
+					if strings.TrimSpace(input.GrantedBy) == "" {
+						rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("grantedBy", "a grant needs the support staff member making it for the audit log"))
+						return
+					}
+
+					gm, err := Extract(input)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(rest.InvalidBody(err))
+						return
+					}
+
+					am, err := NewProcessor(d.Logger(), d.Context(), db).GrantAndEmit(accountId, compartmentId, gm)
+					if errors.Is(err, compartment.ErrCompartmentMismatch) {
+						d.Logger().Errorf("Compartment [%s] not found for account [%d].", compartmentId, accountId)
+						rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("compartment does not belong to account"))
+						return
+					}
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+
+					res, err := asset.Transform(am)
+					if err != nil {
+						d.Logger().WithError(err).Errorf("Creating REST model.")
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+
+					query := r.URL.Query()
+					queryParams := jsonapi.ParseQueryFields(&query)
+					server.MarshalResponse[asset.RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(res)
+				}
+			})
+		})
+	}
+}
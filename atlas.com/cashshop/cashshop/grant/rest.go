@@ -0,0 +1,58 @@
+package grant
+
+import (
+	"atlas-cashshop/cashshop/item"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// RestModel represents a request to grant an item for REST API
+type RestModel struct {
+	Id         uuid.UUID  `json:"-"`
+	TemplateId uint32     `json:"templateId"`
+	Quantity   uint32     `json:"quantity"`
+	Flag       uint16     `json:"flag"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	Reason     string     `json:"reason"`
+	GrantedBy  string     `json:"grantedBy"`
+}
+
+// GetName returns the resource name
+func (r RestModel) GetName() string {
+	return "grants"
+}
+
+// GetID returns the resource ID
+func (r RestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID. A grant request leaves it out
+func (r *RestModel) SetID(strId string) error {
+	if strId == "" {
+		r.Id = uuid.Nil
+		return nil
+	}
+	id, err := uuid.Parse(strId)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// Extract converts a RestModel to a Model. Items granted without an expiration get the expiration of a purchased item
+func Extract(rm RestModel) (Model, error) {
+	expiration := item.DefaultExpiration()
+	if rm.Expiration != nil {
+		expiration = *rm.Expiration
+	}
+	return NewBuilder(rm.TemplateId).
+		SetQuantity(rm.Quantity).
+		SetFlag(rm.Flag).
+		SetExpiration(expiration).
+		SetReason(rm.Reason).
+		SetGrantedBy(strings.TrimSpace(rm.GrantedBy)).
+		Build(), nil
+}
@@ -1,6 +1,7 @@
 package asset
 
 import (
+	"errors"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -16,7 +17,11 @@ func create(db *gorm.DB) func(tenantId uuid.UUID) func(compartmentId uuid.UUID)
 					ItemId:        itemId,
 				}
 
-				if err := db.Create(&entity).Error; err != nil {
+				err := db.Create(&entity).Error
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return Entity{}, ErrAssetExists
+				}
+				if err != nil {
 					return Entity{}, err
 				}
 
@@ -30,3 +35,25 @@ func create(db *gorm.DB) func(tenantId uuid.UUID) func(compartmentId uuid.UUID)
 func deleteByItemId(db *gorm.DB, tenantId uuid.UUID, itemId uint32) error {
 	return db.Where("tenant_id = ? AND item_id = ?", tenantId, itemId).Delete(&Entity{}).Error
 }
+
+// updateCompartmentId moves the asset holding an item to another compartment
+func updateCompartmentId(db *gorm.DB, tenantId uuid.UUID, itemId uint32, compartmentId uuid.UUID) (Entity, error) {
+	var entity Entity
+	if err := db.Where("tenant_id = ? AND item_id = ?", tenantId, itemId).First(&entity).Error; err != nil {
+		return Entity{}, err
+	}
+	entity.CompartmentId = compartmentId
+	err := db.Save(&entity).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return Entity{}, ErrAssetExists
+	}
+	if err != nil {
+		return Entity{}, err
+	}
+	return entity, nil
+}
+
+// deleteAllByCompartmentId deletes every asset entity in a compartment
+func deleteAllByCompartmentId(db *gorm.DB, tenantId uuid.UUID, compartmentId uuid.UUID) error {
+	return db.Where("tenant_id = ? AND compartment_id = ?", tenantId, compartmentId).Delete(&Entity{}).Error
+}
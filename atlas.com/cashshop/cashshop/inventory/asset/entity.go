@@ -2,21 +2,17 @@ package asset
 
 import (
 	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// Migration sets up the database table for assets
-func Migration(db *gorm.DB) error {
-	return db.AutoMigrate(&Entity{})
-}
-
 // Entity represents a cash shop inventory asset in the database
 type Entity struct {
-	Id            uuid.UUID `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	TenantId      uuid.UUID `gorm:"not null"`
-	CompartmentId uuid.UUID `gorm:"not null"`
-	ItemId        uint32    `gorm:"not null"`
+	Id            uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId      uuid.UUID `gorm:"not null;uniqueIndex:idx_cash_assets_tenant_compartment_item;index:idx_cash_assets_tenant_item"`
+	CompartmentId uuid.UUID `gorm:"not null;uniqueIndex:idx_cash_assets_tenant_compartment_item"`
+	ItemId        uint32    `gorm:"not null;uniqueIndex:idx_cash_assets_tenant_compartment_item;index:idx_cash_assets_tenant_item"`
 }
 
 // TableName returns the database table name for this entity
@@ -24,6 +20,26 @@ func (e Entity) TableName() string {
 	return "cash_assets"
 }
 
+// BeforeCreate assigns the ID, so it does not depend on a database generated default
+func (e *Entity) BeforeCreate(_ *gorm.DB) error {
+	return database.AssignId(&e.Id)
+}
+
+// itemEntity is an asset read together with its item
+type itemEntity struct {
+	Entity `gorm:"embedded"`
+	Item   item.Entity `gorm:"embedded;embeddedPrefix:items_"`
+}
+
+// makeWithItem converts an itemEntity to a Model holding the item
+func makeWithItem(e itemEntity) (Model, error) {
+	im, err := item.Make(e.Item)
+	if err != nil {
+		return Model{}, err
+	}
+	return NewBuilder(e.Id, e.CompartmentId, im).Build(), nil
+}
+
 // Make converts an Entity to a Model
 func Make(e Entity) (Model, error) {
 	return NewBuilder(
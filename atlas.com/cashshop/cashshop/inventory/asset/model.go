@@ -8,9 +8,11 @@ import (
 
 // Model represents a cash shop inventory asset
 type Model struct {
-	id            uuid.UUID
-	compartmentId uuid.UUID
-	item          item.Model
+	id                    uuid.UUID
+	compartmentId         uuid.UUID
+	item                  item.Model
+	reservedBy            uint32
+	reservationExpiration time.Time
 }
 
 // Id returns the unique identifier of this asset
@@ -43,20 +45,39 @@ func (m Model) Expiration() time.Time {
 	return m.item.Expiration()
 }
 
+// Reserved returns true if a character currently holds a reservation on this asset
+func (m Model) Reserved() bool {
+	return !m.reservationExpiration.IsZero() && time.Now().Before(m.reservationExpiration)
+}
+
+// ReservedBy returns the character holding the reservation on this asset
+func (m Model) ReservedBy() uint32 {
+	return m.reservedBy
+}
+
+// ReservationExpiration returns the time at which the reservation on this asset lapses
+func (m Model) ReservationExpiration() time.Time {
+	return m.reservationExpiration
+}
+
 // Clone creates a builder from this model
 func Clone(m Model) *ModelBuilder {
 	return &ModelBuilder{
-		id:            m.id,
-		compartmentId: m.compartmentId,
-		item:          m.item,
+		id:                    m.id,
+		compartmentId:         m.compartmentId,
+		item:                  m.item,
+		reservedBy:            m.reservedBy,
+		reservationExpiration: m.reservationExpiration,
 	}
 }
 
 // ModelBuilder is a builder for the Model
 type ModelBuilder struct {
-	id            uuid.UUID
-	compartmentId uuid.UUID
-	item          item.Model
+	id                    uuid.UUID
+	compartmentId         uuid.UUID
+	item                  item.Model
+	reservedBy            uint32
+	reservationExpiration time.Time
 }
 
 // NewBuilder creates a new ModelBuilder
@@ -74,11 +95,20 @@ func (b *ModelBuilder) SetItem(item item.Model) *ModelBuilder {
 	return b
 }
 
+// SetReservation sets the character holding a reservation on this asset and when it lapses
+func (b *ModelBuilder) SetReservation(characterId uint32, expiration time.Time) *ModelBuilder {
+	b.reservedBy = characterId
+	b.reservationExpiration = expiration
+	return b
+}
+
 // Build creates a Model from this builder
 func (b *ModelBuilder) Build() Model {
 	return Model{
-		id:            b.id,
-		compartmentId: b.compartmentId,
-		item:          b.item,
+		id:                    b.id,
+		compartmentId:         b.compartmentId,
+		item:                  b.item,
+		reservedBy:            b.reservedBy,
+		reservationExpiration: b.reservationExpiration,
 	}
 }
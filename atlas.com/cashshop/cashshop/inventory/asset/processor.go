@@ -1,11 +1,13 @@
 package asset
 
 import (
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
 	"atlas-cashshop/cashshop/item"
 	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
-	"atlas-cashshop/kafka/producer"
+	"atlas-cashshop/outbox"
 	"context"
+	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
@@ -13,16 +15,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrAssetExists is returned when a compartment already holds an asset for the item
+var ErrAssetExists = errors.New("compartment already holds the item")
+
 // Processor provides functions to manipulate assets
 type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
 	ByIdProvider(id uuid.UUID) model.Provider[Model]
 	GetById(id uuid.UUID) (Model, error)
+	ByItemIdProvider(itemId uint32) model.Provider[Model]
+	GetByItemId(itemId uint32) (Model, error)
 	ByCompartmentIdProvider(compartmentId uuid.UUID) model.Provider[[]Model]
 	GetByCompartmentId(compartmentId uuid.UUID) ([]Model, error)
+	ByQueryProvider(compartmentId uuid.UUID, q Query) model.Provider[[]Model]
+	CountByQuery(compartmentId uuid.UUID, q Query) (int64, error)
 	Create(mb *message.Buffer) func(compartmentId uuid.UUID) func(itemId uint32) (Model, error)
 	CreateAndEmit(compartmentId uuid.UUID, itemId uint32) (Model, error)
 	Release(mb *message.Buffer) func(cashItemId uint32) error
 	ReleaseAndEmit(cashItemId uint32) error
+	Move(mb *message.Buffer) func(cashItemId uint32) func(compartmentId uuid.UUID) (Model, error)
+	DeleteAllByCompartmentId(mb *message.Buffer) func(compartmentId uuid.UUID) error
 }
 
 // ProcessorImpl implements the Processor interface
@@ -31,8 +43,8 @@ type ProcessorImpl struct {
 	ctx  context.Context
 	db   *gorm.DB
 	t    tenant.Model
-	p    producer.Provider
 	itmP item.Processor
+	resP reservation.Processor
 }
 
 // NewProcessor creates a new Processor
@@ -42,16 +54,32 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 		ctx:  ctx,
 		db:   db,
 		t:    tenant.MustFromContext(ctx),
-		p:    producer.ProviderImpl(l)(ctx),
 		itmP: item.NewProcessor(l, ctx, db),
+		resP: reservation.NewProcessor(l, ctx, db),
 	}
 	return p
 }
 
+// WithTransaction returns a new Processor with the given transaction
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return p.withTransaction(tx)
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
+	return &ProcessorImpl{
+		l:    p.l,
+		ctx:  p.ctx,
+		db:   tx,
+		t:    p.t,
+		itmP: p.itmP.WithTransaction(tx),
+		resP: p.resP.WithTransaction(tx),
+	}
+}
+
 // ByIdProvider retrieves an asset by ID
 func (p *ProcessorImpl) ByIdProvider(id uuid.UUID) model.Provider[Model] {
-	ap := model.Map(Make)(getByIdProvider(p.t.Id())(id)(p.db))
-	return model.Map(model.Decorate(model.Decorators(p.DecorateItem)))(ap)
+	ap := model.Map(Make)(getByIdProvider(p.t.Id())(id)(database.Read(p.ctx, p.db)))
+	return model.Map(model.Decorate(model.Decorators(p.DecorateItem, p.DecorateReservation)))(ap)
 }
 
 // GetById retrieves an asset by ID
@@ -59,10 +87,21 @@ func (p *ProcessorImpl) GetById(id uuid.UUID) (Model, error) {
 	return p.ByIdProvider(id)()
 }
 
+// ByItemIdProvider retrieves the asset holding a cash item
+func (p *ProcessorImpl) ByItemIdProvider(itemId uint32) model.Provider[Model] {
+	ap := model.Map(Make)(getByItemIdProvider(p.t.Id())(itemId)(database.Read(p.ctx, p.db)))
+	return model.Map(model.Decorate(model.Decorators(p.DecorateItem, p.DecorateReservation)))(ap)
+}
+
+// GetByItemId retrieves the asset holding a cash item
+func (p *ProcessorImpl) GetByItemId(itemId uint32) (Model, error) {
+	return p.ByItemIdProvider(itemId)()
+}
+
 // ByCompartmentIdProvider retrieves all assets for a compartment
 func (p *ProcessorImpl) ByCompartmentIdProvider(compartmentId uuid.UUID) model.Provider[[]Model] {
-	ap := model.SliceMap(Make)(getByCompartmentIdProvider(p.t.Id())(compartmentId)(p.db))(model.ParallelMap())
-	return model.SliceMap(model.Decorate(model.Decorators(p.DecorateItem)))(ap)(model.ParallelMap())
+	ap := model.SliceMap(Make)(getByCompartmentIdProvider(p.t.Id())(compartmentId)(database.Read(p.ctx, p.db)))(model.ParallelMap())
+	return model.SliceMap(model.Decorate(model.Decorators(p.DecorateItem, p.DecorateReservation)))(ap)(model.ParallelMap())
 }
 
 // GetByCompartmentId retrieves all assets for a compartment
@@ -70,6 +109,18 @@ func (p *ProcessorImpl) GetByCompartmentId(compartmentId uuid.UUID) ([]Model, er
 	return p.ByCompartmentIdProvider(compartmentId)()
 }
 
+// ByQueryProvider retrieves the page of the assets of a compartment selected by the query
+// The items are read in the same query and the reservations of the page in one lookup. The assets are mapped in turn, so they keep the order of the query
+func (p *ProcessorImpl) ByQueryProvider(compartmentId uuid.UUID, q Query) model.Provider[[]Model] {
+	ap := model.SliceMap(makeWithItem)(getByQueryProvider(p.t.Id())(compartmentId)(q)(database.Read(p.ctx, p.db)))()
+	return p.decorateReservations(ap)
+}
+
+// CountByQuery counts the assets of a compartment matched by the query, regardless of its page
+func (p *ProcessorImpl) CountByQuery(compartmentId uuid.UUID, q Query) (int64, error) {
+	return countByQuery(database.Read(p.ctx, p.db), p.t.Id(), compartmentId, q)
+}
+
 func (p *ProcessorImpl) DecorateItem(m Model) Model {
 	im, err := p.itmP.GetById(m.Item().Id())
 	if err != nil {
@@ -78,6 +129,50 @@ func (p *ProcessorImpl) DecorateItem(m Model) Model {
 	return Clone(m).SetItem(im).Build()
 }
 
+// DecorateReservation attaches the active reservation of the asset's item, if any
+// A failed lookup is logged and leaves the asset shown as unreserved
+func (p *ProcessorImpl) DecorateReservation(m Model) Model {
+	rm, err := p.resP.GetByItemId(m.Item().Id())
+	if errors.Is(err, reservation.ErrNotFound) {
+		return m
+	}
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to retrieve reservation for asset [%s] of item [%d].", m.Id(), m.Item().Id())
+		return m
+	}
+	return Clone(m).SetReservation(rm.OwnerId(), rm.Expiration()).Build()
+}
+
+// decorateReservations attaches the active reservations of the assets' items, looked up together
+// A failed lookup is logged and leaves the assets shown as unreserved
+func (p *ProcessorImpl) decorateReservations(ap model.Provider[[]Model]) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		ms, err := ap()
+		if err != nil || len(ms) == 0 {
+			return ms, err
+		}
+		itemIds := make([]uint32, 0, len(ms))
+		for _, m := range ms {
+			itemIds = append(itemIds, m.Item().Id())
+		}
+		rms, err := p.resP.GetByItemIds(itemIds)
+		if err != nil {
+			p.l.WithError(err).Errorf("Unable to retrieve reservations for [%d] assets.", len(ms))
+			return ms, nil
+		}
+		byItemId := make(map[uint32]reservation.Model, len(rms))
+		for _, rm := range rms {
+			byItemId[rm.ItemId()] = rm
+		}
+		for i, m := range ms {
+			if rm, ok := byItemId[m.Item().Id()]; ok {
+				ms[i] = Clone(m).SetReservation(rm.OwnerId(), rm.Expiration()).Build()
+			}
+		}
+		return ms, nil
+	}
+}
+
 // Create creates a new asset
 func (p *ProcessorImpl) Create(mb *message.Buffer) func(compartmentId uuid.UUID) func(itemId uint32) (Model, error) {
 	return func(compartmentId uuid.UUID) func(itemId uint32) (Model, error) {
@@ -113,7 +208,9 @@ func (p *ProcessorImpl) Create(mb *message.Buffer) func(compartmentId uuid.UUID)
 
 // CreateAndEmit creates a new asset and emits Kafka messages
 func (p *ProcessorImpl) CreateAndEmit(compartmentId uuid.UUID, itemId uint32) (Model, error) {
-	return message.EmitWithResult[Model, uint32](p.p)(model.Flip(p.Create)(compartmentId))(itemId)
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.withTransaction(tx).Create(buf)(compartmentId)(itemId)
+	})
 }
 
 func (p *ProcessorImpl) Release(mb *message.Buffer) func(cashItemId uint32) error {
@@ -139,7 +236,51 @@ func (p *ProcessorImpl) Release(mb *message.Buffer) func(cashItemId uint32) erro
 }
 
 func (p *ProcessorImpl) ReleaseAndEmit(cashItemId uint32) error {
-	return message.Emit(p.p)(func(buf *message.Buffer) error {
-		return p.Release(buf)(cashItemId)
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Release(buf)(cashItemId)
 	})
 }
+
+// Move reassigns the asset holding an item to another compartment
+func (p *ProcessorImpl) Move(mb *message.Buffer) func(cashItemId uint32) func(compartmentId uuid.UUID) (Model, error) {
+	return func(cashItemId uint32) func(compartmentId uuid.UUID) (Model, error) {
+		return func(compartmentId uuid.UUID) (Model, error) {
+			p.l.Debugf("Moving asset with item Id [%d] to compartment [%s].", cashItemId, compartmentId)
+			entity, err := updateCompartmentId(p.db, p.t.Id(), cashItemId, compartmentId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to move asset with item Id [%d] to compartment [%s].", cashItemId, compartmentId)
+				return Model{}, err
+			}
+			m, err := Make(entity)
+			if err != nil {
+				return Model{}, err
+			}
+			return p.DecorateItem(m), nil
+		}
+	}
+}
+
+// DeleteAllByCompartmentId deletes every asset in a compartment along with the items and reservations they hold
+func (p *ProcessorImpl) DeleteAllByCompartmentId(mb *message.Buffer) func(compartmentId uuid.UUID) error {
+	return func(compartmentId uuid.UUID) error {
+		p.l.Debugf("Deleting all assets in compartment [%s].", compartmentId)
+		return database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			tp := p.withTransaction(tx)
+			ams, err := tp.GetByCompartmentId(compartmentId)
+			if err != nil {
+				return err
+			}
+			for _, am := range ams {
+				if err = tp.resP.Release(am.Item().Id()); err != nil {
+					p.l.WithError(err).Errorf("Unable to release reservation on item [%d].", am.Item().Id())
+					return err
+				}
+				if err = tp.itmP.Delete(mb)(am.Item().Id()); err != nil {
+					p.l.WithError(err).Errorf("Unable to delete item [%d] held by asset [%s].", am.Item().Id(), am.Id())
+					return err
+				}
+			}
+			return deleteAllByCompartmentId(tx, p.t.Id(), compartmentId)
+		})
+	}
+}
@@ -20,6 +20,19 @@ func getByIdProvider(tenantId uuid.UUID) func(id uuid.UUID) database.EntityProvi
 	}
 }
 
+// getByItemIdProvider retrieves the asset holding a cash item
+func getByItemIdProvider(tenantId uuid.UUID) func(itemId uint32) database.EntityProvider[Entity] {
+	return func(itemId uint32) database.EntityProvider[Entity] {
+		return func(db *gorm.DB) model.Provider[Entity] {
+			return func() (Entity, error) {
+				var entity Entity
+				result := db.Where("item_id = ? AND tenant_id = ?", itemId, tenantId).First(&entity)
+				return entity, result.Error
+			}
+		}
+	}
+}
+
 // getByCompartmentIdProvider retrieves all assets for a compartment
 func getByCompartmentIdProvider(tenantId uuid.UUID) func(compartmentId uuid.UUID) database.EntityProvider[[]Entity] {
 	return func(compartmentId uuid.UUID) database.EntityProvider[[]Entity] {
@@ -32,3 +45,73 @@ func getByCompartmentIdProvider(tenantId uuid.UUID) func(compartmentId uuid.UUID
 		}
 	}
 }
+
+// queryScope restricts assets to those of a compartment whose items match the query
+func queryScope(tenantId uuid.UUID, compartmentId uuid.UUID, q Query) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Model(&Entity{}).
+			Joins("JOIN items ON items.id = cash_assets.item_id AND items.tenant_id = cash_assets.tenant_id").
+			Where("cash_assets.tenant_id = ? AND cash_assets.compartment_id = ?", tenantId, compartmentId)
+		if q.MinTemplateId() != 0 {
+			db = db.Where("items.template_id >= ?", q.MinTemplateId())
+		}
+		if q.MaxTemplateId() != 0 {
+			db = db.Where("items.template_id <= ?", q.MaxTemplateId())
+		}
+		if !q.ExpiringBefore().IsZero() {
+			db = db.Where("items.expiration < ?", q.ExpiringBefore())
+		}
+		if q.PurchasedBy() != nil {
+			db = db.Where("items.purchased_by = ?", *q.PurchasedBy())
+		}
+		if q.Flag() != 0 {
+			db = db.Where("items.flag & ? = ?", q.Flag(), q.Flag())
+		}
+		return db
+	}
+}
+
+// itemColumns selects the columns of the joined item, named for the embedded item of itemEntity
+var itemColumns = "items.id AS items_id, items.tenant_id AS items_tenant_id, items.cash_id AS items_cash_id, " +
+	"items.template_id AS items_template_id, items.quantity AS items_quantity, items.flag AS items_flag, " +
+	"items.purchased_by AS items_purchased_by, items.expiration AS items_expiration, items.purchased_at AS items_purchased_at, " +
+	"items.purchaser_deleted_at AS items_purchaser_deleted_at, items.discarded_at AS items_discarded_at"
+
+// getByQueryProvider retrieves the page of the assets of a compartment selected by the query, along with their items
+func getByQueryProvider(tenantId uuid.UUID) func(compartmentId uuid.UUID) func(q Query) database.EntityProvider[[]itemEntity] {
+	return func(compartmentId uuid.UUID) func(q Query) database.EntityProvider[[]itemEntity] {
+		return func(q Query) database.EntityProvider[[]itemEntity] {
+			return func(db *gorm.DB) model.Provider[[]itemEntity] {
+				return func() ([]itemEntity, error) {
+					column := "items.purchased_at"
+					if q.Sort() == SortExpiration {
+						column = "items.expiration"
+					}
+					direction := " ASC"
+					if q.Descending() {
+						direction = " DESC"
+					}
+
+					var entities []itemEntity
+					tx := db.Scopes(queryScope(tenantId, compartmentId, q)).
+						Select("cash_assets.*, " + itemColumns).
+						Order(column + direction).
+						Order("cash_assets.id" + direction).
+						Offset(q.Offset())
+					if q.Limit() > 0 {
+						tx = tx.Limit(q.Limit())
+					}
+					result := tx.Scan(&entities)
+					return entities, result.Error
+				}
+			}
+		}
+	}
+}
+
+// countByQuery counts the assets of a compartment matched by the query, regardless of its page
+func countByQuery(db *gorm.DB, tenantId uuid.UUID, compartmentId uuid.UUID, q Query) (int64, error) {
+	var count int64
+	result := db.Scopes(queryScope(tenantId, compartmentId, q)).Count(&count)
+	return count, result.Error
+}
@@ -0,0 +1,142 @@
+package asset
+
+import "time"
+
+// SortField names what a listing of assets is ordered by
+type SortField string
+
+const (
+	SortPurchasedAt = SortField("purchasedAt")
+	SortExpiration  = SortField("expiration")
+)
+
+// Query selects a page of the assets of a compartment, filtered by the items they hold
+// Assets with equal sort values are ordered by id, so pages do not overlap
+type Query struct {
+	minTemplateId  uint32
+	maxTemplateId  uint32
+	expiringBefore time.Time
+	purchasedBy    *uint32
+	flag           uint16
+	sort           SortField
+	descending     bool
+	offset         int
+	limit          int
+}
+
+// MinTemplateId returns the lowest template listed, or 0 when unbounded
+func (q Query) MinTemplateId() uint32 {
+	return q.minTemplateId
+}
+
+// MaxTemplateId returns the highest template listed, or 0 when unbounded
+func (q Query) MaxTemplateId() uint32 {
+	return q.maxTemplateId
+}
+
+// ExpiringBefore returns the time listed items expire before, or the zero time when unbounded
+func (q Query) ExpiringBefore() time.Time {
+	return q.expiringBefore
+}
+
+// PurchasedBy returns the character whose purchases are listed, or nil for all
+func (q Query) PurchasedBy() *uint32 {
+	return q.purchasedBy
+}
+
+// Flag returns the flag bits every listed item has set
+func (q Query) Flag() uint16 {
+	return q.flag
+}
+
+// Sort returns what the listing is ordered by
+func (q Query) Sort() SortField {
+	return q.sort
+}
+
+// Descending reports whether the listing is ordered from the highest value
+func (q Query) Descending() bool {
+	return q.descending
+}
+
+// Offset returns how many matching assets are skipped
+func (q Query) Offset() int {
+	return q.offset
+}
+
+// Limit returns how many matching assets are listed at most, or 0 for all
+func (q Query) Limit() int {
+	return q.limit
+}
+
+// QueryBuilder is a builder for the Query
+type QueryBuilder struct {
+	minTemplateId  uint32
+	maxTemplateId  uint32
+	expiringBefore time.Time
+	purchasedBy    *uint32
+	flag           uint16
+	sort           SortField
+	descending     bool
+	offset         int
+	limit          int
+}
+
+// NewQueryBuilder creates a builder for a query listing every asset in purchase order
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{sort: SortPurchasedAt}
+}
+
+// SetTemplateIdRange limits the listing to templates within the inclusive range. A bound of 0 leaves that side open
+func (b *QueryBuilder) SetTemplateIdRange(min uint32, max uint32) *QueryBuilder {
+	b.minTemplateId = min
+	b.maxTemplateId = max
+	return b
+}
+
+// SetExpiringBefore limits the listing to items expiring before the time
+func (b *QueryBuilder) SetExpiringBefore(expiringBefore time.Time) *QueryBuilder {
+	b.expiringBefore = expiringBefore
+	return b
+}
+
+// SetPurchasedBy limits the listing to items purchased by the character
+func (b *QueryBuilder) SetPurchasedBy(characterId uint32) *QueryBuilder {
+	b.purchasedBy = &characterId
+	return b
+}
+
+// SetFlag limits the listing to items with all of the flag bits set
+func (b *QueryBuilder) SetFlag(flag uint16) *QueryBuilder {
+	b.flag = flag
+	return b
+}
+
+// SetSort orders the listing
+func (b *QueryBuilder) SetSort(field SortField, descending bool) *QueryBuilder {
+	b.sort = field
+	b.descending = descending
+	return b
+}
+
+// SetPage limits the listing to limit assets after skipping offset of them
+func (b *QueryBuilder) SetPage(offset int, limit int) *QueryBuilder {
+	b.offset = offset
+	b.limit = limit
+	return b
+}
+
+// Build creates the Query
+func (b *QueryBuilder) Build() Query {
+	return Query{
+		minTemplateId:  b.minTemplateId,
+		maxTemplateId:  b.maxTemplateId,
+		expiringBefore: b.expiringBefore,
+		purchasedBy:    b.purchasedBy,
+		flag:           b.flag,
+		sort:           b.sort,
+		descending:     b.descending,
+		offset:         b.offset,
+		limit:          b.limit,
+	}
+}
@@ -0,0 +1,121 @@
+package asset_test
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/database/fixture"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestByQuery(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(fixture.AccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+
+	// Items are created in template order and expire in reverse template order
+	now := time.Now()
+	ap := asset.NewProcessor(l, ctx, db)
+	for i := uint32(0); i < 5; i++ {
+		im, err := item.NewProcessor(l, ctx, db).CreateAndEmit(5000000+i, 1, uint16(i%2), 2000+i%2, now.Add(time.Duration(10-i)*time.Hour))
+		if err != nil {
+			t.Fatalf("Unable to create item: %v", err)
+		}
+		if _, err = ap.CreateAndEmit(cm.Id(), im.Id()); err != nil {
+			t.Fatalf("Unable to create asset: %v", err)
+		}
+	}
+
+	templates := func(q asset.Query) []uint32 {
+		ms, err := ap.ByQueryProvider(cm.Id(), q)()
+		if err != nil {
+			t.Fatalf("Unable to query assets: %v", err)
+		}
+		var ids []uint32
+		for _, m := range ms {
+			ids = append(ids, m.TemplateId()-5000000)
+		}
+		return ids
+	}
+	expect := func(name string, q asset.Query, want ...uint32) {
+		got := templates(q)
+		if len(got) != len(want) {
+			t.Errorf("[%s] expected templates %v, got %v", name, want, got)
+			return
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("[%s] expected templates %v, got %v", name, want, got)
+				return
+			}
+		}
+	}
+
+	expect("all", asset.NewQueryBuilder().Build(), 0, 1, 2, 3, 4)
+	expect("newest first", asset.NewQueryBuilder().SetSort(asset.SortPurchasedAt, true).Build(), 4, 3, 2, 1, 0)
+	expect("by expiration", asset.NewQueryBuilder().SetSort(asset.SortExpiration, false).Build(), 4, 3, 2, 1, 0)
+	expect("template range", asset.NewQueryBuilder().SetTemplateIdRange(5000001, 5000003).Build(), 1, 2, 3)
+	expect("expiring before", asset.NewQueryBuilder().SetExpiringBefore(now.Add(8*time.Hour)).Build(), 3, 4)
+	expect("purchased by", asset.NewQueryBuilder().SetPurchasedBy(2000).Build(), 0, 2, 4)
+	expect("flag", asset.NewQueryBuilder().SetFlag(1).Build(), 1, 3)
+	expect("second page", asset.NewQueryBuilder().SetPage(2, 2).Build(), 2, 3)
+	expect("last page", asset.NewQueryBuilder().SetPage(4, 2).Build(), 4)
+
+	total, err := ap.CountByQuery(cm.Id(), asset.NewQueryBuilder().SetPurchasedBy(2000).SetPage(0, 1).Build())
+	if err != nil {
+		t.Fatalf("Unable to count assets: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected the count to ignore the page, got [%d]", total)
+	}
+}
+
+func TestByQueryIncludesItemsAndReservations(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(fixture.AccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+
+	ap := asset.NewProcessor(l, ctx, db)
+	var items []item.Model
+	for i := uint32(0); i < 2; i++ {
+		im, err := item.NewProcessor(l, ctx, db).CreateAndEmit(5000000+i, 1, 0, 2000, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Unable to create item: %v", err)
+		}
+		if _, err = ap.CreateAndEmit(cm.Id(), im.Id()); err != nil {
+			t.Fatalf("Unable to create asset: %v", err)
+		}
+		items = append(items, im)
+	}
+	if _, err = reservation.NewProcessor(l, ctx, db).Reserve(items[1].Id(), 2000, uuid.New()); err != nil {
+		t.Fatalf("Unable to reserve item: %v", err)
+	}
+
+	ms, err := ap.ByQueryProvider(cm.Id(), asset.NewQueryBuilder().Build())()
+	if err != nil {
+		t.Fatalf("Unable to query assets: %v", err)
+	}
+	if len(ms) != 2 {
+		t.Fatalf("Expected 2 assets, got [%d]", len(ms))
+	}
+	for i, m := range ms {
+		im := m.Item()
+		if im.Id() != items[i].Id() || im.CashId() != items[i].CashId() || im.TemplateId() != items[i].TemplateId() || im.PurchasedBy() != 2000 || !im.Expiration().Equal(items[i].Expiration()) {
+			t.Errorf("Expected asset [%d] to hold item %+v, got %+v", i, items[i], im)
+		}
+	}
+	if ms[0].Reserved() {
+		t.Errorf("Expected the first asset to be unreserved")
+	}
+	if !ms[1].Reserved() || ms[1].ReservedBy() != 2000 {
+		t.Errorf("Expected the second asset to be reserved by [2000], got [%d]", ms[1].ReservedBy())
+	}
+}
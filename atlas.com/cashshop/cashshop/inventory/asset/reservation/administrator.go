@@ -0,0 +1,47 @@
+package reservation
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// createEntity inserts a reservation unless one already exists for the item
+// The returned flag is false when another holder owns the item
+func createEntity(db *gorm.DB, tenantId uuid.UUID, itemId uint32, ownerId uint32, transactionId uuid.UUID, expiration time.Time) (Entity, bool, error) {
+	entity := Entity{
+		TenantId:      tenantId,
+		ItemId:        itemId,
+		OwnerId:       ownerId,
+		TransactionId: transactionId,
+		Expiration:    expiration,
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity)
+	if result.Error != nil {
+		return Entity{}, false, result.Error
+	}
+	return entity, result.RowsAffected == 1, nil
+}
+
+// deleteEntity deletes the reservation for an item
+func deleteEntity(db *gorm.DB, tenantId uuid.UUID, itemId uint32) error {
+	return db.Where("tenant_id = ? AND item_id = ?", tenantId, itemId).Delete(&Entity{}).Error
+}
+
+// deleteExpiredByItemId deletes the reservation for an item if it has lapsed
+func deleteExpiredByItemId(db *gorm.DB, tenantId uuid.UUID, itemId uint32, now time.Time) error {
+	return db.Where("tenant_id = ? AND item_id = ? AND expiration <= ?", tenantId, itemId, now).Delete(&Entity{}).Error
+}
+
+// deleteAllExpired deletes every lapsed reservation regardless of tenant
+func deleteAllExpired(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("expiration <= ?", now).Delete(&Entity{})
+	return result.RowsAffected, result.Error
+}
+
+// deleteAllByOwnerId removes every reservation held by a character
+func deleteAllByOwnerId(db *gorm.DB, tenantId uuid.UUID, ownerId uint32) error {
+	return db.Where("tenant_id = ? AND owner_id = ?", tenantId, ownerId).Delete(&Entity{}).Error
+}
@@ -0,0 +1,96 @@
+package reservation
+
+import (
+	"atlas-cashshop/database"
+	"errors"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// DatabaseStore is a Store backed by the cash_asset_reservations table
+type DatabaseStore struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewDatabaseStore creates a Store which persists reservations using the given database
+func NewDatabaseStore(db *gorm.DB, ttl time.Duration) *DatabaseStore {
+	return &DatabaseStore{
+		db:  db,
+		ttl: ttl,
+	}
+}
+
+func (s *DatabaseStore) Get(tenantId uuid.UUID, itemId uint32) (Model, error) {
+	e, err := getActiveByItemIdProvider(tenantId)(itemId)(time.Now())(s.db)()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Model{}, ErrNotFound
+	}
+	if err != nil {
+		return Model{}, err
+	}
+	return Make(e)
+}
+
+func (s *DatabaseStore) GetAll(tenantId uuid.UUID, itemIds []uint32) ([]Model, error) {
+	if len(itemIds) == 0 {
+		return []Model{}, nil
+	}
+	return model.SliceMap(Make)(getActiveByItemIdsProvider(tenantId)(itemIds)(time.Now())(s.db))(model.ParallelMap())()
+}
+
+func (s *DatabaseStore) Reserve(tenantId uuid.UUID, itemId uint32, ownerId uint32, transactionId uuid.UUID) (Model, error) {
+	var result Model
+	txErr := database.ExecuteTransaction(s.db, func(tx *gorm.DB) error {
+		now := time.Now()
+		// A lapsed hold must not block the new one
+		err := deleteExpiredByItemId(tx, tenantId, itemId, now)
+		if err != nil {
+			return err
+		}
+
+		e, created, err := createEntity(tx, tenantId, itemId, ownerId, transactionId, now.Add(s.ttl))
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrAlreadyReserved
+		}
+
+		result, err = Make(e)
+		return err
+	})
+	if txErr != nil {
+		return Model{}, txErr
+	}
+	return result, nil
+}
+
+func (s *DatabaseStore) Release(tenantId uuid.UUID, itemId uint32) error {
+	return deleteEntity(s.db, tenantId, itemId)
+}
+
+func (s *DatabaseStore) ReleaseAllByOwner(tenantId uuid.UUID, ownerId uint32) ([]Model, error) {
+	var released []Model
+	txErr := database.ExecuteTransaction(s.db, func(tx *gorm.DB) error {
+		ms, err := model.SliceMap(Make)(getActiveByOwnerIdProvider(tenantId)(ownerId)(time.Now())(tx))(model.ParallelMap())()
+		if err != nil {
+			return err
+		}
+		if err = deleteAllByOwnerId(tx, tenantId, ownerId); err != nil {
+			return err
+		}
+		released = ms
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return released, nil
+}
+
+func (s *DatabaseStore) DeleteExpired() (int64, error) {
+	return deleteAllExpired(s.db, time.Now())
+}
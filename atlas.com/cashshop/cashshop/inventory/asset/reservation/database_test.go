@@ -0,0 +1,151 @@
+package reservation
+
+import (
+	"atlas-cashshop/database"
+	"atlas-cashshop/database/migrations"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"testing"
+	"time"
+)
+
+func newDatabaseStore(t *testing.T, ttl time.Duration) *DatabaseStore {
+	db, err := database.Open(logrus.New(), database.SetDialector(database.SQLite("file:"+uuid.NewString()+"?mode=memory&cache=shared")), database.SetMigrations(migrations.All()...))
+	if err != nil {
+		t.Fatalf("Unable to open test database: %v", err)
+	}
+	return NewDatabaseStore(db, ttl)
+}
+
+func TestDatabaseStore_Get(t *testing.T) {
+	store := newDatabaseStore(t, DefaultTTL)
+
+	tenantId := uuid.New()
+	itemId := uint32(1)
+	characterId := uint32(123)
+	transactionId := uuid.New()
+
+	if _, err := store.Get(tenantId, itemId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item to not be reserved initially, got [%v]", err)
+	}
+
+	if _, err := store.Reserve(tenantId, itemId, characterId, transactionId); err != nil {
+		t.Fatalf("Failed to reserve item: %v", err)
+	}
+
+	m, err := store.Get(tenantId, itemId)
+	if err != nil {
+		t.Fatalf("Expected item to be reserved after reservation: %v", err)
+	}
+	if m.OwnerId() != characterId || m.TransactionId() != transactionId {
+		t.Errorf("Unexpected reservation owned by [%d] under transaction [%s]", m.OwnerId(), m.TransactionId())
+	}
+
+	if err = store.Release(tenantId, itemId); err != nil {
+		t.Fatalf("Failed to release item: %v", err)
+	}
+	if _, err = store.Get(tenantId, itemId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item to not be reserved after release, got [%v]", err)
+	}
+}
+
+func TestDatabaseStore_Reserve(t *testing.T) {
+	store := newDatabaseStore(t, DefaultTTL)
+
+	tenantId := uuid.New()
+	itemId := uint32(1)
+
+	if _, err := store.Reserve(tenantId, itemId, 123, uuid.New()); err != nil {
+		t.Fatalf("Failed to reserve item: %v", err)
+	}
+	if _, err := store.Reserve(tenantId, itemId, 456, uuid.New()); !errors.Is(err, ErrAlreadyReserved) {
+		t.Errorf("Expected second reservation to fail, got [%v]", err)
+	}
+	if _, err := store.Reserve(uuid.New(), itemId, 456, uuid.New()); err != nil {
+		t.Errorf("Expected reservation in another tenant to succeed: %v", err)
+	}
+}
+
+func TestDatabaseStore_Expiration(t *testing.T) {
+	store := newDatabaseStore(t, 10*time.Millisecond)
+
+	tenantId := uuid.New()
+	itemId := uint32(1)
+
+	if _, err := store.Reserve(tenantId, itemId, 123, uuid.New()); err != nil {
+		t.Fatalf("Failed to reserve item: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := store.Get(tenantId, itemId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item to not be reserved after expiration, got [%v]", err)
+	}
+	if _, err := store.Reserve(tenantId, itemId, 456, uuid.New()); err != nil {
+		t.Errorf("Expected reservation after expiration to succeed: %v", err)
+	}
+}
+
+func TestDatabaseStore_DeleteExpired(t *testing.T) {
+	store := newDatabaseStore(t, 10*time.Millisecond)
+
+	tenantId := uuid.New()
+	_, _ = store.Reserve(tenantId, 1, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 2, 123, uuid.New())
+	time.Sleep(20 * time.Millisecond)
+
+	n, err := store.DeleteExpired()
+	if err != nil {
+		t.Fatalf("Failed to delete expired reservations: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 expired reservations to be removed, got %d", n)
+	}
+}
+
+func TestDatabaseStore_ReleaseAllByOwner(t *testing.T) {
+	store := newDatabaseStore(t, DefaultTTL)
+
+	tenantId := uuid.New()
+	_, _ = store.Reserve(tenantId, 1, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 2, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 3, 456, uuid.New())
+
+	released, err := store.ReleaseAllByOwner(tenantId, 123)
+	if err != nil {
+		t.Fatalf("Failed to release reservations: %v", err)
+	}
+	if len(released) != 2 {
+		t.Errorf("Expected 2 reservations to be released, got %d", len(released))
+	}
+	if _, err = store.Get(tenantId, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item 1 to be released, got [%v]", err)
+	}
+	if _, err = store.Get(tenantId, 3); err != nil {
+		t.Errorf("Expected reservation of another owner to remain: %v", err)
+	}
+}
+
+func TestDatabaseStore_GetAll(t *testing.T) {
+	store := newDatabaseStore(t, DefaultTTL)
+
+	tenantId := uuid.New()
+	_, _ = store.Reserve(tenantId, 1, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 2, 456, uuid.New())
+	_, _ = store.Reserve(uuid.New(), 3, 123, uuid.New())
+
+	ms, err := store.GetAll(tenantId, []uint32{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("Failed to retrieve reservations: %v", err)
+	}
+	owners := make(map[uint32]uint32)
+	for _, m := range ms {
+		owners[m.ItemId()] = m.OwnerId()
+	}
+	if len(owners) != 2 || owners[1] != 123 || owners[2] != 456 {
+		t.Errorf("Expected the reservations of items 1 and 2 of the tenant, got %v", owners)
+	}
+	if ms, err = store.GetAll(tenantId, nil); err != nil || len(ms) != 0 {
+		t.Errorf("Expected no reservations for no items, got [%d] [%v]", len(ms), err)
+	}
+}
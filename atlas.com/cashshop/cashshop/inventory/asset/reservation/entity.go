@@ -0,0 +1,32 @@
+package reservation
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Entity represents a cash item reservation in the database
+// The composite primary key guarantees a single holder per item across all replicas
+type Entity struct {
+	TenantId      uuid.UUID `gorm:"primaryKey;type:uuid"`
+	ItemId        uint32    `gorm:"primaryKey;autoIncrement:false"`
+	OwnerId       uint32    `gorm:"not null"`
+	TransactionId uuid.UUID `gorm:"type:uuid;not null"`
+	Expiration    time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_asset_reservations"
+}
+
+// Make converts an Entity to a Model
+func Make(e Entity) (Model, error) {
+	return Model{
+		tenantId:      e.TenantId,
+		itemId:        e.ItemId,
+		ownerId:       e.OwnerId,
+		transactionId: e.TransactionId,
+		expiration:    e.Expiration,
+	}, nil
+}
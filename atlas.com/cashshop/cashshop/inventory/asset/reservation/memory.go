@@ -0,0 +1,119 @@
+package reservation
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+type memoryKey struct {
+	tenantId uuid.UUID
+	itemId   uint32
+}
+
+// MemoryStore is a process local Store intended for tests and single instance development
+type MemoryStore struct {
+	mu           sync.Mutex
+	ttl          time.Duration
+	reservations map[memoryKey]Model
+}
+
+// NewMemoryStore creates an empty in-memory Store
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:          ttl,
+		reservations: make(map[memoryKey]Model),
+	}
+}
+
+func (s *MemoryStore) Get(tenantId uuid.UUID, itemId uint32) (Model, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := memoryKey{tenantId: tenantId, itemId: itemId}
+	m, ok := s.reservations[k]
+	if !ok {
+		return Model{}, ErrNotFound
+	}
+	if m.Expired(time.Now()) {
+		delete(s.reservations, k)
+		return Model{}, ErrNotFound
+	}
+	return m, nil
+}
+
+func (s *MemoryStore) GetAll(tenantId uuid.UUID, itemIds []uint32) ([]Model, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	result := make([]Model, 0)
+	for _, itemId := range itemIds {
+		if m, ok := s.reservations[memoryKey{tenantId: tenantId, itemId: itemId}]; ok && !m.Expired(now) {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) Reserve(tenantId uuid.UUID, itemId uint32, ownerId uint32, transactionId uuid.UUID) (Model, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	k := memoryKey{tenantId: tenantId, itemId: itemId}
+	if m, ok := s.reservations[k]; ok && !m.Expired(now) {
+		return Model{}, ErrAlreadyReserved
+	}
+
+	m := Model{
+		tenantId:      tenantId,
+		itemId:        itemId,
+		ownerId:       ownerId,
+		transactionId: transactionId,
+		expiration:    now.Add(s.ttl),
+	}
+	s.reservations[k] = m
+	return m, nil
+}
+
+func (s *MemoryStore) Release(tenantId uuid.UUID, itemId uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reservations, memoryKey{tenantId: tenantId, itemId: itemId})
+	return nil
+}
+
+func (s *MemoryStore) ReleaseAllByOwner(tenantId uuid.UUID, ownerId uint32) ([]Model, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	released := make([]Model, 0)
+	for k, m := range s.reservations {
+		if k.tenantId != tenantId || m.OwnerId() != ownerId {
+			continue
+		}
+		delete(s.reservations, k)
+		if !m.Expired(now) {
+			released = append(released, m)
+		}
+	}
+	return released, nil
+}
+
+func (s *MemoryStore) DeleteExpired() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	count := int64(0)
+	for k, m := range s.reservations {
+		if m.Expired(now) {
+			delete(s.reservations, k)
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,160 @@
+package reservation
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Get(t *testing.T) {
+	store := NewMemoryStore(DefaultTTL)
+
+	tenantId := uuid.New()
+	itemId := uint32(1)
+	characterId := uint32(123)
+
+	// Initially, the item should not be reserved
+	if _, err := store.Get(tenantId, itemId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item to not be reserved initially")
+	}
+
+	// Reserve the item
+	if _, err := store.Reserve(tenantId, itemId, characterId, uuid.New()); err != nil {
+		t.Errorf("Failed to reserve item: %v", err)
+	}
+
+	// Now the item should be reserved by the character
+	m, err := store.Get(tenantId, itemId)
+	if err != nil {
+		t.Errorf("Expected item to be reserved after reservation")
+	}
+	if m.OwnerId() != characterId {
+		t.Errorf("Owner mismatch: expected %d, got %d", characterId, m.OwnerId())
+	}
+
+	// Release the reservation
+	_ = store.Release(tenantId, itemId)
+
+	// After release, the item should not be reserved
+	if _, err = store.Get(tenantId, itemId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item to not be reserved after release")
+	}
+}
+
+func TestMemoryStore_Reserve(t *testing.T) {
+	store := NewMemoryStore(DefaultTTL)
+
+	tenantId := uuid.New()
+	itemId := uint32(1)
+	characterId := uint32(123)
+	anotherCharacterId := uint32(456)
+
+	// First reservation should succeed
+	if _, err := store.Reserve(tenantId, itemId, characterId, uuid.New()); err != nil {
+		t.Errorf("Failed to reserve item: %v", err)
+	}
+
+	// Second reservation for the same item should fail
+	if _, err := store.Reserve(tenantId, itemId, anotherCharacterId, uuid.New()); !errors.Is(err, ErrAlreadyReserved) {
+		t.Errorf("Expected second reservation to fail")
+	}
+
+	// The same item id in another tenant is independent
+	if _, err := store.Reserve(uuid.New(), itemId, anotherCharacterId, uuid.New()); err != nil {
+		t.Errorf("Expected reservation in another tenant to succeed")
+	}
+
+	// Release the reservation
+	_ = store.Release(tenantId, itemId)
+
+	// After release, a new reservation should succeed
+	if _, err := store.Reserve(tenantId, itemId, anotherCharacterId, uuid.New()); err != nil {
+		t.Errorf("Failed to reserve item after release: %v", err)
+	}
+}
+
+func TestMemoryStore_Expiration(t *testing.T) {
+	store := NewMemoryStore(10 * time.Millisecond)
+
+	tenantId := uuid.New()
+	itemId := uint32(1)
+	characterId := uint32(123)
+	anotherCharacterId := uint32(456)
+
+	if _, err := store.Reserve(tenantId, itemId, characterId, uuid.New()); err != nil {
+		t.Errorf("Failed to reserve item: %v", err)
+	}
+
+	// Wait for the reservation to expire
+	time.Sleep(20 * time.Millisecond)
+
+	// After expiration, the item should not be reserved
+	if _, err := store.Get(tenantId, itemId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected item to not be reserved after expiration")
+	}
+
+	// And another character may take it
+	if _, err := store.Reserve(tenantId, itemId, anotherCharacterId, uuid.New()); err != nil {
+		t.Errorf("Expected reservation after expiration to succeed")
+	}
+}
+
+func TestMemoryStore_DeleteExpired(t *testing.T) {
+	store := NewMemoryStore(10 * time.Millisecond)
+
+	tenantId := uuid.New()
+	_, _ = store.Reserve(tenantId, 1, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 2, 123, uuid.New())
+
+	time.Sleep(20 * time.Millisecond)
+
+	count, err := store.DeleteExpired()
+	if err != nil {
+		t.Fatalf("Failed to delete expired reservations: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 expired reservations to be purged, got %d", count)
+	}
+}
+
+func TestMemoryStore_ReleaseAllByOwner(t *testing.T) {
+	store := NewMemoryStore(5 * time.Minute)
+
+	tenantId := uuid.New()
+	_, _ = store.Reserve(tenantId, 1, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 2, 123, uuid.New())
+	_, _ = store.Reserve(tenantId, 3, 456, uuid.New())
+	_, _ = store.Reserve(uuid.New(), 4, 123, uuid.New())
+
+	released, err := store.ReleaseAllByOwner(tenantId, 123)
+	if err != nil {
+		t.Fatalf("Failed to release reservations: %v", err)
+	}
+	if len(released) != 2 {
+		t.Errorf("Expected 2 released reservations, got %d", len(released))
+	}
+	if _, err = store.Get(tenantId, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected reservation of item 1 to be released")
+	}
+	if _, err = store.Get(tenantId, 3); err != nil {
+		t.Errorf("Expected reservation held by another character to remain: %v", err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	t.Setenv(EnvTTL, "")
+	if TTL() != DefaultTTL {
+		t.Errorf("Expected default TTL when unset")
+	}
+
+	t.Setenv(EnvTTL, "90s")
+	if TTL() != 90*time.Second {
+		t.Errorf("Expected configured TTL, got %s", TTL())
+	}
+
+	t.Setenv(EnvTTL, "garbage")
+	if TTL() != DefaultTTL {
+		t.Errorf("Expected default TTL for invalid value")
+	}
+}
@@ -0,0 +1,45 @@
+package reservation
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Model represents a time limited hold placed on a cash item by a character
+type Model struct {
+	tenantId      uuid.UUID
+	itemId        uint32
+	ownerId       uint32
+	transactionId uuid.UUID
+	expiration    time.Time
+}
+
+// TenantId returns the tenant the reservation belongs to
+func (m Model) TenantId() uuid.UUID {
+	return m.tenantId
+}
+
+// ItemId returns the cash item which is reserved
+func (m Model) ItemId() uint32 {
+	return m.itemId
+}
+
+// OwnerId returns the character holding the reservation
+func (m Model) OwnerId() uint32 {
+	return m.ownerId
+}
+
+// TransactionId returns the transaction the reservation was made for
+func (m Model) TransactionId() uuid.UUID {
+	return m.transactionId
+}
+
+// Expiration returns the time at which the reservation lapses
+func (m Model) Expiration() time.Time {
+	return m.expiration
+}
+
+// Expired reports whether the reservation has lapsed at the given time
+func (m Model) Expired(now time.Time) bool {
+	return !now.Before(m.expiration)
+}
@@ -0,0 +1,105 @@
+package reservation
+
+import (
+	"context"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Processor provides tenant scoped access to asset reservations
+type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
+	ByItemIdProvider(itemId uint32) model.Provider[Model]
+	GetByItemId(itemId uint32) (Model, error)
+	ByItemIdsProvider(itemIds []uint32) model.Provider[[]Model]
+	GetByItemIds(itemIds []uint32) ([]Model, error)
+	Reserve(itemId uint32, ownerId uint32, transactionId uuid.UUID) (Model, error)
+	Release(itemId uint32) error
+	ReleaseAllByOwner(ownerId uint32) ([]Model, error)
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	t   tenant.Model
+	s   Store
+}
+
+// NewProcessor creates a Processor backed by the database store
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return NewProcessorWithStore(l, ctx, NewDatabaseStore(db, TTL()))
+}
+
+// NewProcessorWithStore creates a Processor backed by the given store
+func NewProcessorWithStore(l logrus.FieldLogger, ctx context.Context, s Store) Processor {
+	return &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		t:   tenant.MustFromContext(ctx),
+		s:   s,
+	}
+}
+
+// WithTransaction returns a new Processor with the given transaction
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	s := p.s
+	if ds, ok := p.s.(*DatabaseStore); ok {
+		s = NewDatabaseStore(tx, ds.ttl)
+	}
+	return &ProcessorImpl{
+		l:   p.l,
+		ctx: p.ctx,
+		t:   p.t,
+		s:   s,
+	}
+}
+
+// ByItemIdProvider retrieves the active reservation for an item
+func (p *ProcessorImpl) ByItemIdProvider(itemId uint32) model.Provider[Model] {
+	return func() (Model, error) {
+		return p.s.Get(p.t.Id(), itemId)
+	}
+}
+
+// GetByItemId retrieves the active reservation for an item
+func (p *ProcessorImpl) GetByItemId(itemId uint32) (Model, error) {
+	return p.ByItemIdProvider(itemId)()
+}
+
+// ByItemIdsProvider retrieves the active reservations among the items in one lookup
+func (p *ProcessorImpl) ByItemIdsProvider(itemIds []uint32) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		return p.s.GetAll(p.t.Id(), itemIds)
+	}
+}
+
+// GetByItemIds retrieves the active reservations among the items in one lookup
+func (p *ProcessorImpl) GetByItemIds(itemIds []uint32) ([]Model, error) {
+	return p.ByItemIdsProvider(itemIds)()
+}
+
+// Reserve places a hold on an item for a character as part of a transaction
+func (p *ProcessorImpl) Reserve(itemId uint32, ownerId uint32, transactionId uuid.UUID) (Model, error) {
+	p.l.Debugf("Character [%d] attempting to reserve item [%d] for transaction [%s].", ownerId, itemId, transactionId)
+	m, err := p.s.Reserve(p.t.Id(), itemId, ownerId, transactionId)
+	if err != nil {
+		p.l.WithError(err).Debugf("Unable to reserve item [%d] for character [%d].", itemId, ownerId)
+		return Model{}, err
+	}
+	return m, nil
+}
+
+// Release removes any hold on an item
+func (p *ProcessorImpl) Release(itemId uint32) error {
+	p.l.Debugf("Releasing reservation on item [%d].", itemId)
+	return p.s.Release(p.t.Id(), itemId)
+}
+
+// ReleaseAllByOwner drops every hold placed by a character
+func (p *ProcessorImpl) ReleaseAllByOwner(ownerId uint32) ([]Model, error) {
+	return p.s.ReleaseAllByOwner(p.t.Id(), ownerId)
+}
@@ -0,0 +1,54 @@
+package reservation
+
+import (
+	"atlas-cashshop/database"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// getActiveByOwnerIdProvider retrieves the unexpired reservations held by a character
+func getActiveByOwnerIdProvider(tenantId uuid.UUID) func(ownerId uint32) func(now time.Time) database.EntityProvider[[]Entity] {
+	return func(ownerId uint32) func(now time.Time) database.EntityProvider[[]Entity] {
+		return func(now time.Time) database.EntityProvider[[]Entity] {
+			return func(db *gorm.DB) model.Provider[[]Entity] {
+				return func() ([]Entity, error) {
+					var entities []Entity
+					result := db.Where("tenant_id = ? AND owner_id = ? AND expiration > ?", tenantId, ownerId, now).Find(&entities)
+					return entities, result.Error
+				}
+			}
+		}
+	}
+}
+
+// getActiveByItemIdsProvider retrieves the unexpired reservations among the items
+func getActiveByItemIdsProvider(tenantId uuid.UUID) func(itemIds []uint32) func(now time.Time) database.EntityProvider[[]Entity] {
+	return func(itemIds []uint32) func(now time.Time) database.EntityProvider[[]Entity] {
+		return func(now time.Time) database.EntityProvider[[]Entity] {
+			return func(db *gorm.DB) model.Provider[[]Entity] {
+				return func() ([]Entity, error) {
+					var entities []Entity
+					result := db.Where("tenant_id = ? AND item_id IN ? AND expiration > ?", tenantId, itemIds, now).Find(&entities)
+					return entities, result.Error
+				}
+			}
+		}
+	}
+}
+
+// getActiveByItemIdProvider retrieves the unexpired reservation for an item
+func getActiveByItemIdProvider(tenantId uuid.UUID) func(itemId uint32) func(now time.Time) database.EntityProvider[Entity] {
+	return func(itemId uint32) func(now time.Time) database.EntityProvider[Entity] {
+		return func(now time.Time) database.EntityProvider[Entity] {
+			return func(db *gorm.DB) model.Provider[Entity] {
+				return func() (Entity, error) {
+					var entity Entity
+					result := db.Where("tenant_id = ? AND item_id = ? AND expiration > ?", tenantId, itemId, now).First(&entity)
+					return entity, result.Error
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package reservation
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"os"
+	"time"
+)
+
+const (
+	EnvTTL     = "ASSET_RESERVATION_TTL"
+	DefaultTTL = 5 * time.Minute
+)
+
+var ErrAlreadyReserved = errors.New("asset already reserved")
+var ErrNotFound = errors.New("reservation not found")
+
+// Store persists asset reservations so they can be shared between replicas
+type Store interface {
+	// Get returns the active reservation for an item, or ErrNotFound
+	Get(tenantId uuid.UUID, itemId uint32) (Model, error)
+	// GetAll returns the active reservations among the items, leaving out items without one
+	GetAll(tenantId uuid.UUID, itemIds []uint32) ([]Model, error)
+	// Reserve places a hold on an item for a character, or returns ErrAlreadyReserved
+	Reserve(tenantId uuid.UUID, itemId uint32, ownerId uint32, transactionId uuid.UUID) (Model, error)
+	// Release removes any hold on an item
+	Release(tenantId uuid.UUID, itemId uint32) error
+	// ReleaseAllByOwner removes every active hold placed by a character and returns the released reservations
+	ReleaseAllByOwner(tenantId uuid.UUID, ownerId uint32) ([]Model, error)
+	// DeleteExpired purges lapsed reservations and returns how many were removed
+	DeleteExpired() (int64, error)
+}
+
+// TTL returns the configured reservation lifetime, falling back to DefaultTTL
+func TTL() time.Duration {
+	if v, ok := os.LookupEnv(EnvTTL); ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultTTL
+}
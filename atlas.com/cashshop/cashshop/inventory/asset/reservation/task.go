@@ -0,0 +1,39 @@
+package reservation
+
+import (
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const ExpirationTaskInterval = time.Minute
+
+// ExpirationTask periodically purges lapsed reservations from a Store
+type ExpirationTask struct {
+	l        logrus.FieldLogger
+	s        Store
+	interval time.Duration
+}
+
+// NewExpirationTask creates a task which purges lapsed reservations every interval
+func NewExpirationTask(l logrus.FieldLogger, s Store, interval time.Duration) *ExpirationTask {
+	return &ExpirationTask{
+		l:        l,
+		s:        s,
+		interval: interval,
+	}
+}
+
+func (t *ExpirationTask) Run() {
+	count, err := t.s.DeleteExpired()
+	if err != nil {
+		t.l.WithError(err).Errorf("Unable to purge expired asset reservations.")
+		return
+	}
+	if count > 0 {
+		t.l.Debugf("Purged [%d] expired asset reservations.", count)
+	}
+}
+
+func (t *ExpirationTask) SleepTime() time.Duration {
+	return t.interval
+}
@@ -10,12 +10,17 @@ import (
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // InitResource initializes the asset resource
 func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
 	return func(db *gorm.DB) server.RouteInitializer {
 		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(ErrAssetExists, http.StatusConflict, "ASSET_EXISTS", "Asset already exists")
+
 			registerGet := rest.RegisterHandler(l)(si)
 
 			r := router.PathPrefix("/accounts/{accountId}/cash-shop/inventory/compartments/{compartmentId}/assets").Subrouter()
@@ -41,14 +46,14 @@ func handleGetAssetById(db *gorm.DB) rest.GetHandler {
 						rm, err := model.Map(Transform)(assetProvider)()
 						if err != nil {
 							d.Logger().WithError(err).Errorf("Error retrieving asset with ID [%s]", assetId)
-							w.WriteHeader(http.StatusNotFound)
+							rest.WriteError(d.Logger())(w)(err)
 							return
 						}
 
 						// Verify that the asset belongs to the specified compartment
 						if rm.CompartmentId != compartmentId {
 							d.Logger().Errorf("Asset with ID [%s] does not belong to compartment with ID [%s]", assetId, compartmentId)
-							w.WriteHeader(http.StatusNotFound)
+							rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("asset does not belong to compartment"))
 							return
 						}
 
@@ -62,3 +67,75 @@ func handleGetAssetById(db *gorm.DB) rest.GetHandler {
 		})
 	}
 }
+
+const (
+	ParamMinTemplateId  = "filter[templateIdMin]"
+	ParamMaxTemplateId  = "filter[templateIdMax]"
+	ParamExpiringBefore = "filter[expiringBefore]"
+	ParamPurchasedBy    = "filter[purchasedBy]"
+	ParamFlag           = "filter[flag]"
+	ParamSort           = "sort"
+)
+
+// ParseQuery reads the filter and sort parameters of a request listing assets, selecting the given page
+// Sorting is by purchasedAt or expiration, descending when prefixed with '-'
+func ParseQuery(r *http.Request, page rest.Page) (Query, error) {
+	query := r.URL.Query()
+	b := NewQueryBuilder().SetPage(page.Offset(), page.Size())
+
+	parseUint := func(name string, bits int) (uint64, bool, error) {
+		v := query.Get(name)
+		if v == "" {
+			return 0, false, nil
+		}
+		n, err := strconv.ParseUint(v, 10, bits)
+		if err != nil {
+			return 0, false, rest.InvalidParameter(name).WithDetail(err.Error())
+		}
+		return n, true, nil
+	}
+
+	minTemplateId, _, err := parseUint(ParamMinTemplateId, 32)
+	if err != nil {
+		return Query{}, err
+	}
+	maxTemplateId, _, err := parseUint(ParamMaxTemplateId, 32)
+	if err != nil {
+		return Query{}, err
+	}
+	if maxTemplateId != 0 && minTemplateId > maxTemplateId {
+		return Query{}, rest.InvalidParameter(ParamMinTemplateId).WithDetail("lowest template is above the highest")
+	}
+	b.SetTemplateIdRange(uint32(minTemplateId), uint32(maxTemplateId))
+
+	if v := query.Get(ParamExpiringBefore); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return Query{}, rest.InvalidParameter(ParamExpiringBefore).WithDetail("expected an RFC 3339 time")
+		}
+		b.SetExpiringBefore(t)
+	}
+
+	purchasedBy, ok, err := parseUint(ParamPurchasedBy, 32)
+	if err != nil {
+		return Query{}, err
+	}
+	if ok {
+		b.SetPurchasedBy(uint32(purchasedBy))
+	}
+
+	flag, _, err := parseUint(ParamFlag, 16)
+	if err != nil {
+		return Query{}, err
+	}
+	b.SetFlag(uint16(flag))
+
+	if v := query.Get(ParamSort); v != "" {
+		field := SortField(strings.TrimPrefix(v, "-"))
+		if field != SortPurchasedAt && field != SortExpiration {
+			return Query{}, rest.InvalidParameter(ParamSort).WithDetail("sort by purchasedAt or expiration")
+		}
+		b.SetSort(field, strings.HasPrefix(v, "-"))
+	}
+	return b.Build(), nil
+}
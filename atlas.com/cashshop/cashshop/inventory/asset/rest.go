@@ -4,13 +4,17 @@ import (
 	"atlas-cashshop/cashshop/item"
 	"github.com/google/uuid"
 	"github.com/jtumidanski/api2go/jsonapi"
+	"time"
 )
 
 // RestModel represents a cash shop inventory asset for REST API
 type RestModel struct {
-	Id            uuid.UUID      `json:"-"`
-	CompartmentId uuid.UUID      `json:"compartmentId"`
-	Item          item.RestModel `json:"-"`
+	Id                    uuid.UUID      `json:"-"`
+	CompartmentId         uuid.UUID      `json:"compartmentId"`
+	Reserved              bool           `json:"reserved"`
+	ReservedBy            uint32         `json:"reservedBy,omitempty"`
+	ReservationExpiration *time.Time     `json:"reservationExpiration,omitempty"`
+	Item                  item.RestModel `json:"-"`
 }
 
 // GetName returns the resource name
@@ -98,11 +102,18 @@ func Transform(a Model) (RestModel, error) {
 		return RestModel{}, err
 	}
 
-	return RestModel{
+	rm := RestModel{
 		Id:            a.Id(),
 		CompartmentId: a.CompartmentId(),
 		Item:          item,
-	}, nil
+	}
+	if a.Reserved() {
+		expiration := a.ReservationExpiration()
+		rm.Reserved = true
+		rm.ReservedBy = a.ReservedBy()
+		rm.ReservationExpiration = &expiration
+	}
+	return rm, nil
 }
 
 func Extract(rm RestModel) (Model, error) {
@@ -110,9 +121,9 @@ func Extract(rm RestModel) (Model, error) {
 	if err != nil {
 		return Model{}, err
 	}
-	return Model{
-		id:            rm.Id,
-		compartmentId: rm.CompartmentId,
-		item:          item,
-	}, nil
+	b := NewBuilder(rm.Id, rm.CompartmentId, item)
+	if rm.Reserved && rm.ReservationExpiration != nil {
+		b.SetReservation(rm.ReservedBy, *rm.ReservationExpiration)
+	}
+	return b.Build(), nil
 }
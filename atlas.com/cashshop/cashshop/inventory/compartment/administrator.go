@@ -1,6 +1,7 @@
 package compartment
 
 import (
+	"errors"
 	"github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -16,7 +17,11 @@ func createEntity(db *gorm.DB, t tenant.Model, accountId uint32, type_ Compartme
 		Capacity:  capacity,
 	}
 
-	if err := db.Create(&entity).Error; err != nil {
+	err := db.Create(&entity).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return Model{}, ErrCompartmentExists
+	}
+	if err != nil {
 		return Model{}, err
 	}
 
@@ -0,0 +1,201 @@
+package compartment_test
+
+import (
+	"atlas-cashshop/account"
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/database/fixture"
+	consumer "atlas-cashshop/kafka/consumer"
+	compartmentConsumer "atlas-cashshop/kafka/consumer/cashshop/compartment"
+	compartmentMessage "atlas-cashshop/kafka/message/cashshop/compartment"
+	"atlas-cashshop/kafka/producer"
+	"atlas-cashshop/outbox"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	producer2 "github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// commandSuite runs the cash compartment command consumer against a real database, with Kafka stood in for
+type commandSuite struct {
+	t          *testing.T
+	l          logrus.FieldLogger
+	ctx        context.Context
+	db         *gorm.DB
+	dispatcher *consumer.Dispatcher
+	memory     *producer.Memory
+	relay      *outbox.RelayTask
+	cm         compartment.Model
+}
+
+func newCommandSuite(t *testing.T) *commandSuite {
+	t.Setenv(compartmentMessage.EnvCommandTopic, "cash-compartment-command")
+	t.Setenv(compartmentMessage.EnvEventTopicStatus, "cash-compartment-status")
+	t.Setenv(consumer.EnvRetryAttempts, "1")
+
+	l, ctx, db := fixture.Provision(t)
+	s := &commandSuite{
+		t:          t,
+		l:          l,
+		ctx:        ctx,
+		db:         db,
+		dispatcher: consumer.NewDispatcher(l),
+		memory:     producer.NewMemory(),
+	}
+	s.relay = outbox.NewRelayTaskWithProvider(l, db, time.Second, s.memory.HeaderProvider)
+	compartmentConsumer.InitHandlers(l)(db)(s.dispatcher.Register)
+
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(testAccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	s.cm = cm
+	return s
+}
+
+// compartment retrieves the compartment of the type of an account, provisioning the account first when it is not the fixture's
+func (s *commandSuite) compartment(accountId uint32, type_ compartment.CompartmentType) compartment.Model {
+	if accountId != testAccountId {
+		if _, err := account.NewProcessor(s.l, s.ctx, s.db).ProvisionAndEmit(accountId); err != nil {
+			s.t.Fatalf("Unable to provision account [%d]: %v", accountId, err)
+		}
+	}
+	cm, err := compartment.NewProcessor(s.l, s.ctx, s.db).GetByAccountIdAndType(accountId, type_)
+	if err != nil {
+		s.t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	return cm
+}
+
+// item creates a cash item which is not yet in any compartment
+func (s *commandSuite) item() item.Model {
+	im, err := item.NewProcessor(s.l, s.ctx, s.db).CreateAndEmit(5000000, 1, 0, 2000, time.Now().Add(time.Hour))
+	if err != nil {
+		s.t.Fatalf("Unable to create item: %v", err)
+	}
+	return im
+}
+
+// assetCount counts the assets held by the compartment
+func (s *commandSuite) assetCount(compartmentId uuid.UUID) int {
+	ms, err := asset.NewProcessor(s.l, s.ctx, s.db).GetByCompartmentId(compartmentId)
+	if err != nil {
+		s.t.Fatalf("Unable to retrieve assets: %v", err)
+	}
+	return len(ms)
+}
+
+// send dispatches a command to the consumer and returns the cash compartment status events it caused
+func send[E any](s *commandSuite, c compartmentMessage.Command[E]) []kafka.Message {
+	s.relay.Run()
+	s.memory.Drain(compartmentMessage.EnvEventTopicStatus)
+
+	if err := s.dispatcher.Provider(s.ctx)(compartmentMessage.EnvCommandTopic)(producer2.SingleMessageProvider(producer2.CreateKey(int(c.AccountId)), c)); err != nil {
+		s.t.Fatalf("Unable to dispatch [%s] command: %v", c.Type, err)
+	}
+	s.relay.Run()
+	return s.memory.Drain(compartmentMessage.EnvEventTopicStatus)
+}
+
+func decodeStatusEvent[E any](t *testing.T, msg kafka.Message) compartmentMessage.StatusEvent[E] {
+	var e compartmentMessage.StatusEvent[E]
+	if err := json.Unmarshal(msg.Value, &e); err != nil {
+		t.Fatalf("Unable to decode status event: %v", err)
+	}
+	return e
+}
+
+// expectError checks the command caused a single ERROR event carrying the code, the request id and the transaction id
+func expectError(t *testing.T, ms []kafka.Message, code string, requestId uuid.UUID, transactionId uuid.UUID) {
+	t.Helper()
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 status event, got [%d]", len(ms))
+	}
+	e := decodeStatusEvent[compartmentMessage.StatusEventErrorBody](t, ms[0])
+	if e.Type != compartmentMessage.StatusEventTypeError || e.Body.ErrorCode != code {
+		t.Errorf("Expected a [%s] error event, got %+v", code, e)
+	}
+	if e.RequestId != requestId || e.Body.TransactionId != transactionId {
+		t.Errorf("Expected request [%s] and transaction [%s] to be echoed, got %+v", requestId, transactionId, e)
+	}
+}
+
+func TestAcceptRejected(t *testing.T) {
+	cases := []struct {
+		name      string
+		accountId uint32
+		type_     compartment.CompartmentType
+		capacity  uint32
+		code      string
+	}{
+		{"another account", testAccountId + 1, compartment.TypeExplorer, 0, compartmentMessage.ErrorCodeCompartmentMismatch},
+		{"another type", testAccountId, compartment.TypeCygnus, 0, compartmentMessage.ErrorCodeTypeMismatch},
+		{"full", testAccountId, compartment.TypeExplorer, 1, compartmentMessage.ErrorCodeCompartmentFull},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newCommandSuite(t)
+			if c.capacity != 0 {
+				grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+				if _, err := compartment.NewProcessor(s.l, s.ctx, s.db).UpdateCapacityAndEmit(s.cm.Id(), c.capacity); err != nil {
+					t.Fatalf("Unable to update capacity: %v", err)
+				}
+			}
+			before := s.assetCount(s.cm.Id())
+
+			requestId := uuid.New()
+			transactionId := uuid.New()
+			ms := send(s, compartmentMessage.Command[compartmentMessage.AcceptCommandBody]{
+				AccountId:       c.accountId,
+				CompartmentType: byte(c.type_),
+				RequestId:       requestId,
+				Type:            compartmentMessage.CommandAccept,
+				Body:            compartmentMessage.AcceptCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), ReferenceId: s.item().Id()},
+			})
+			expectError(t, ms, c.code, requestId, transactionId)
+			if n := s.assetCount(s.cm.Id()); n != before {
+				t.Errorf("Expected a rejected accept to leave [%d] assets, got [%d]", before, n)
+			}
+		})
+	}
+}
+
+func TestReleaseRejected(t *testing.T) {
+	cases := []struct {
+		name      string
+		accountId uint32
+		type_     compartment.CompartmentType
+		code      string
+	}{
+		{"another account", testAccountId + 1, compartment.TypeExplorer, compartmentMessage.ErrorCodeCompartmentMismatch},
+		{"another type", testAccountId, compartment.TypeCygnus, compartmentMessage.ErrorCodeTypeMismatch},
+		{"not reserved", testAccountId, compartment.TypeExplorer, compartmentMessage.ErrorCodeAssetNotReserved},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newCommandSuite(t)
+			am := grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+
+			requestId := uuid.New()
+			transactionId := uuid.New()
+			ms := send(s, compartmentMessage.Command[compartmentMessage.ReleaseCommandBody]{
+				AccountId:       c.accountId,
+				CompartmentType: byte(c.type_),
+				RequestId:       requestId,
+				Type:            compartmentMessage.CommandRelease,
+				Body:            compartmentMessage.ReleaseCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), AssetId: am.Item().Id()},
+			})
+			expectError(t, ms, c.code, requestId, transactionId)
+			if _, err := asset.NewProcessor(s.l, s.ctx, s.db).GetById(am.Id()); err != nil {
+				t.Errorf("Expected a rejected release to leave the asset: %v", err)
+			}
+		})
+	}
+}
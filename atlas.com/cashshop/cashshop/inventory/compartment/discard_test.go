@@ -0,0 +1,109 @@
+package compartment_test
+
+import (
+	"atlas-cashshop/cashshop/grant"
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/database/fixture"
+	"atlas-cashshop/kafka/message"
+	compartmentMessage "atlas-cashshop/kafka/message/cashshop/compartment"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const testAccountId = fixture.AccountId
+
+func grantAsset(t *testing.T, l logrus.FieldLogger, ctx context.Context, db *gorm.DB, cm compartment.Model, flag uint16) asset.Model {
+	gm := grant.NewBuilder(5000000).SetFlag(flag).SetExpiration(time.Now().Add(time.Hour)).SetReason("test").Build()
+	am, err := grant.NewProcessor(l, ctx, db).GrantAndEmit(testAccountId, cm.Id(), gm)
+	if err != nil {
+		t.Fatalf("Unable to grant item: %v", err)
+	}
+	return am
+}
+
+func TestDiscard(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cp := compartment.NewProcessor(l, ctx, db)
+	cm, err := cp.GetByAccountIdAndType(testAccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	am := grantAsset(t, l, ctx, db, cm, 0)
+
+	buf := message.NewBuffer()
+	transactionId := uuid.New()
+	if err = cp.Discard(buf)(testAccountId, cm.Id(), cm.Type(), am.Item().Id(), transactionId); err != nil {
+		t.Fatalf("Unable to discard asset: %v", err)
+	}
+
+	if _, err = asset.NewProcessor(l, ctx, db).GetById(am.Id()); err == nil {
+		t.Errorf("Expected discarded asset [%s] to be removed", am.Id())
+	}
+	im, err := item.NewProcessor(l, ctx, db).GetById(am.Item().Id())
+	if err != nil {
+		t.Fatalf("Unable to retrieve discarded item: %v", err)
+	}
+	if !im.Discarded() || im.DiscardedAt() == nil {
+		t.Errorf("Expected item [%d] to be marked discarded", im.Id())
+	}
+
+	ms := buf.GetAll()[compartmentMessage.EnvEventTopicStatus]
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 status event, got [%d]", len(ms))
+	}
+	var e compartmentMessage.StatusEvent[compartmentMessage.StatusEventDiscardedBody]
+	if err = json.Unmarshal(ms[0].Value, &e); err != nil {
+		t.Fatalf("Unable to decode status event: %v", err)
+	}
+	if e.Type != compartmentMessage.StatusEventTypeDiscarded || e.Body.AssetId != am.Item().Id() || e.Body.TemplateId != 5000000 || e.Body.TransactionId != transactionId {
+		t.Errorf("Unexpected status event %+v", e)
+	}
+}
+
+func TestDiscardRejected(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cp := compartment.NewProcessor(l, ctx, db)
+	cm, err := cp.GetByAccountIdAndType(testAccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	am := grantAsset(t, l, ctx, db, cm, item.FlagCannotDiscard)
+
+	buf := message.NewBuffer()
+	rejection := cp.Discard(buf)(testAccountId, cm.Id(), cm.Type(), am.Item().Id(), uuid.New())
+	if !errors.Is(rejection, compartment.ErrCannotDiscard) {
+		t.Fatalf("Expected discarding a flagged item to be rejected, got [%v]", rejection)
+	}
+
+	if _, err = asset.NewProcessor(l, ctx, db).GetById(am.Id()); err != nil {
+		t.Errorf("Expected rejected asset [%s] to remain: %v", am.Id(), err)
+	}
+	if ms := buf.GetAll()[compartmentMessage.EnvEventTopicStatus]; len(ms) != 0 {
+		t.Errorf("Expected a rejected discard to buffer no events, got [%d]", len(ms))
+	}
+
+	buf = message.NewBuffer()
+	if err = cp.Fail(buf)(cm.Id(), cm.Type(), uuid.New(), rejection); err != nil {
+		t.Fatalf("Unable to report rejected discard: %v", err)
+	}
+	ms := buf.GetAll()[compartmentMessage.EnvEventTopicStatus]
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 status event, got [%d]", len(ms))
+	}
+	var e compartmentMessage.StatusEvent[compartmentMessage.StatusEventErrorBody]
+	if err = json.Unmarshal(ms[0].Value, &e); err != nil {
+		t.Fatalf("Unable to decode status event: %v", err)
+	}
+	if e.Body.ErrorCode != compartmentMessage.ErrorCodeCannotDiscard {
+		t.Errorf("Expected error code [%s], got [%s]", compartmentMessage.ErrorCodeCannotDiscard, e.Body.ErrorCode)
+	}
+}
@@ -1,21 +1,17 @@
 package compartment
 
 import (
+	"atlas-cashshop/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// Migration sets up the database table for compartments
-func Migration(db *gorm.DB) error {
-	return db.AutoMigrate(&Entity{})
-}
-
 // Entity represents a cash shop inventory compartment in the database
 type Entity struct {
-	Id        uuid.UUID `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	TenantId  uuid.UUID `gorm:"not null"`
-	AccountId uint32    `gorm:"not null"`
-	Type      byte      `gorm:"not null"`
+	Id        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId  uuid.UUID `gorm:"not null;uniqueIndex:idx_cash_compartments_tenant_account_type"`
+	AccountId uint32    `gorm:"not null;uniqueIndex:idx_cash_compartments_tenant_account_type"`
+	Type      byte      `gorm:"not null;uniqueIndex:idx_cash_compartments_tenant_account_type"`
 	Capacity  uint32    `gorm:"not null;default:55"`
 }
 
@@ -24,6 +20,11 @@ func (e Entity) TableName() string {
 	return "cash_compartments"
 }
 
+// BeforeCreate assigns the ID, so it does not depend on a database generated default
+func (e *Entity) BeforeCreate(_ *gorm.DB) error {
+	return database.AssignId(&e.Id)
+}
+
 // Make converts an Entity to a Model
 func Make(e Entity) (Model, error) {
 	return NewBuilder(
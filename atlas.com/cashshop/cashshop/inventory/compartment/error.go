@@ -0,0 +1,59 @@
+package compartment
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
+	"atlas-cashshop/kafka/message/cashshop/compartment"
+	"errors"
+)
+
+// Error rejects a compartment command for a reason the client is told about through its error code
+type Error struct {
+	code    string
+	message string
+}
+
+func newError(code string, message string) *Error {
+	return &Error{code: code, message: message}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Code returns the error code reported in the error status event
+func (e *Error) Code() string {
+	return e.code
+}
+
+var ErrAssetNotFound = newError(compartment.ErrorCodeItemNotFound, "asset not found")
+var ErrAssetNotReserved = newError(compartment.ErrorCodeAssetNotReserved, "asset not reserved for transaction")
+var ErrCompartmentMismatch = newError(compartment.ErrorCodeCompartmentMismatch, "compartment does not belong to account")
+var ErrTypeMismatch = newError(compartment.ErrorCodeTypeMismatch, "compartment type mismatch")
+var ErrCompartmentFull = newError(compartment.ErrorCodeCompartmentFull, "compartment full")
+var ErrInvalidDestination = newError(compartment.ErrorCodeInvalidDestination, "invalid destination compartment")
+var ErrCannotDiscard = newError(compartment.ErrorCodeCannotDiscard, "item cannot be discarded")
+
+// ErrAssetCreationFailed wraps a failure to create the asset of an accepted item. It is retried, so it is not a rejection
+var ErrAssetCreationFailed = errors.New("asset creation failed")
+
+// IsRejection reports whether err rejects the command on its merits, so trying again cannot change the outcome
+func IsRejection(err error) bool {
+	var e *Error
+	return errors.As(err, &e) || errors.Is(err, reservation.ErrAlreadyReserved) || errors.Is(err, reservation.ErrNotFound)
+}
+
+// ErrorCode translates the error a compartment command failed with into the code reported to the client
+func ErrorCode(err error) string {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e.Code()
+	case errors.Is(err, ErrAssetCreationFailed):
+		return compartment.ErrorCodeAssetCreationFailed
+	case errors.Is(err, reservation.ErrAlreadyReserved):
+		return compartment.ErrorCodeAssetReserved
+	case errors.Is(err, reservation.ErrNotFound):
+		return compartment.ErrorCodeAssetNotReserved
+	}
+	return compartment.ErrorCodeUnknown
+}
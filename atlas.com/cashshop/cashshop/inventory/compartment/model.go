@@ -2,6 +2,7 @@ package compartment
 
 import (
 	"atlas-cashshop/cashshop/inventory/asset"
+	"github.com/Chronicle20/atlas-constants/job"
 	"github.com/google/uuid"
 )
 
@@ -14,6 +15,18 @@ const (
 	TypeLegend   = CompartmentType(3) // "legend"
 )
 
+// TypeFromJobId returns the compartment type which holds cash items for a job
+func TypeFromJobId(jobId uint16) CompartmentType {
+	switch job.GetType(job.Id(jobId)) {
+	case job.TypeExplorer:
+		return TypeExplorer
+	case job.TypeCygnus:
+		return TypeCygnus
+	default:
+		return TypeLegend
+	}
+}
+
 // Model represents a cash shop inventory compartment
 type Model struct {
 	id        uuid.UUID
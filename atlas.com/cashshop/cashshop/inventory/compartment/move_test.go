@@ -0,0 +1,162 @@
+package compartment_test
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	compartmentMessage "atlas-cashshop/kafka/message/cashshop/compartment"
+	"atlas-cashshop/rest"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// moveCommand builds a command moving the asset out of the suite's compartment
+func moveCommand(s *commandSuite, requestId uuid.UUID, transactionId uuid.UUID, assetId uint32, destinationId uuid.UUID) compartmentMessage.Command[compartmentMessage.MoveCommandBody] {
+	return compartmentMessage.Command[compartmentMessage.MoveCommandBody]{
+		AccountId:       testAccountId,
+		CompartmentType: byte(s.cm.Type()),
+		RequestId:       requestId,
+		Type:            compartmentMessage.CommandMove,
+		Body: compartmentMessage.MoveCommandBody{
+			TransactionId:            transactionId,
+			CompartmentId:            s.cm.Id(),
+			AssetId:                  assetId,
+			DestinationCompartmentId: destinationId,
+		},
+	}
+}
+
+func TestMove(t *testing.T) {
+	s := newCommandSuite(t)
+	am := grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+	dm := s.compartment(testAccountId, compartment.TypeCygnus)
+
+	transactionId := uuid.New()
+	ms := send(s, moveCommand(s, uuid.New(), transactionId, am.Item().Id(), dm.Id()))
+	if len(ms) != 2 {
+		t.Fatalf("Expected 2 status events, got [%d]", len(ms))
+	}
+	re := decodeStatusEvent[compartmentMessage.StatusEventReleasedBody](t, ms[0])
+	if re.Type != compartmentMessage.StatusEventTypeReleased || re.CompartmentId != s.cm.Id() {
+		t.Errorf("Expected the source to report RELEASED, got %+v", re)
+	}
+	ae := decodeStatusEvent[compartmentMessage.StatusEventAcceptedBody](t, ms[1])
+	if ae.Type != compartmentMessage.StatusEventTypeAccepted || ae.CompartmentId != dm.Id() {
+		t.Errorf("Expected the destination to report ACCEPTED, got %+v", ae)
+	}
+	if re.Body.TransactionId != transactionId || ae.Body.TransactionId != transactionId {
+		t.Errorf("Expected both events to carry transaction [%s], got [%s] and [%s]", transactionId, re.Body.TransactionId, ae.Body.TransactionId)
+	}
+
+	mm, err := asset.NewProcessor(s.l, s.ctx, s.db).GetById(am.Id())
+	if err != nil {
+		t.Fatalf("Unable to retrieve moved asset: %v", err)
+	}
+	if mm.CompartmentId() != dm.Id() {
+		t.Errorf("Expected asset to be in compartment [%s], got [%s]", dm.Id(), mm.CompartmentId())
+	}
+}
+
+func TestMoveRejected(t *testing.T) {
+	cases := []struct {
+		name        string
+		destination func(s *commandSuite) compartment.Model
+		code        string
+	}{
+		{"full destination", func(s *commandSuite) compartment.Model {
+			dm := s.compartment(testAccountId, compartment.TypeCygnus)
+			grantAsset(s.t, s.l, s.ctx, s.db, dm, 0)
+			if _, err := compartment.NewProcessor(s.l, s.ctx, s.db).UpdateCapacityAndEmit(dm.Id(), 1); err != nil {
+				s.t.Fatalf("Unable to update capacity: %v", err)
+			}
+			return dm
+		}, compartmentMessage.ErrorCodeCompartmentFull},
+		{"destination of another account", func(s *commandSuite) compartment.Model {
+			return s.compartment(testAccountId+1, compartment.TypeExplorer)
+		}, compartmentMessage.ErrorCodeCompartmentMismatch},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newCommandSuite(t)
+			am := grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+			dm := c.destination(s)
+			before := s.assetCount(dm.Id())
+
+			requestId := uuid.New()
+			transactionId := uuid.New()
+			ms := send(s, moveCommand(s, requestId, transactionId, am.Item().Id(), dm.Id()))
+			expectError(t, ms, c.code, requestId, transactionId)
+
+			mm, err := asset.NewProcessor(s.l, s.ctx, s.db).GetById(am.Id())
+			if err != nil {
+				t.Fatalf("Unable to retrieve asset: %v", err)
+			}
+			if mm.CompartmentId() != s.cm.Id() {
+				t.Errorf("Expected a rejected move to leave the asset in compartment [%s], got [%s]", s.cm.Id(), mm.CompartmentId())
+			}
+			if n := s.assetCount(dm.Id()); n != before {
+				t.Errorf("Expected a rejected move to leave [%d] assets in the destination, got [%d]", before, n)
+			}
+		})
+	}
+}
+
+// testServer implements jsonapi.ServerInformation
+type testServer struct{}
+
+func (s testServer) GetBaseURL() string {
+	return ""
+}
+
+func (s testServer) GetPrefix() string {
+	return "/api/"
+}
+
+func TestMoveAssetToMissingDestination(t *testing.T) {
+	s := newCommandSuite(t)
+	am := grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+
+	router := mux.NewRouter()
+	compartment.InitResource(testServer{})(s.db)(router, s.l)
+
+	body := fmt.Sprintf(`{"data":{"type":"assets","id":"%s","attributes":{"compartmentId":"%s"}}}`, am.Id(), uuid.New())
+	url := fmt.Sprintf("/accounts/%d/cash-shop/inventory/compartments/%s/assets/%s", testAccountId, s.cm.Id(), am.Id())
+	req := httptest.NewRequest(http.MethodPatch, url, strings.NewReader(body))
+	te := tenant.MustFromContext(s.ctx)
+	req.Header.Set("TENANT_ID", te.Id().String())
+	req.Header.Set("REGION", te.Region())
+	req.Header.Set("MAJOR_VERSION", strconv.Itoa(int(te.MajorVersion())))
+	req.Header.Set("MINOR_VERSION", strconv.Itoa(int(te.MinorVersion())))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status [%d], got [%d]: %s", http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+	}
+	var doc rest.ErrorDocument
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Unable to decode error document: %v", err)
+	}
+	if len(doc.Errors) != 1 || doc.Errors[0].Code != "INVALID_DESTINATION" {
+		t.Fatalf("Expected an INVALID_DESTINATION error, got %+v", doc)
+	}
+	if doc.Errors[0].Source == nil || doc.Errors[0].Source.Pointer != "/data/attributes/compartmentId" {
+		t.Errorf("Expected the error to point at the destination, got %+v", doc.Errors[0].Source)
+	}
+
+	mm, err := asset.NewProcessor(s.l, s.ctx, s.db).GetById(am.Id())
+	if err != nil {
+		t.Fatalf("Unable to retrieve asset: %v", err)
+	}
+	if mm.CompartmentId() != s.cm.Id() {
+		t.Errorf("Expected the asset to stay in compartment [%s], got [%s]", s.cm.Id(), mm.CompartmentId())
+	}
+}
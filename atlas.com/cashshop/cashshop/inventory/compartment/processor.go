@@ -2,12 +2,18 @@ package compartment
 
 import (
 	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
+	"atlas-cashshop/cashshop/inventory/compartment/transaction"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/correlation"
+	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
 	"atlas-cashshop/kafka/message/cashshop/compartment"
-	"atlas-cashshop/kafka/producer"
 	compartmentProducer "atlas-cashshop/kafka/producer/cashshop/inventory/compartment"
+	"atlas-cashshop/outbox"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
@@ -17,6 +23,8 @@ import (
 
 const DefaultCapacity = uint32(55)
 
+var ErrCompartmentExists = errors.New("account already has a compartment of the type")
+
 // Processor interface defines the operations for cash shop inventory compartments
 type Processor interface {
 	WithTransaction(tx *gorm.DB) Processor
@@ -38,40 +46,60 @@ type Processor interface {
 	Accept(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
 	ReleaseAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
 	Release(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
+	ReserveAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, characterId uint32, transactionId uuid.UUID) error
+	Reserve(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, characterId uint32, transactionId uuid.UUID) error
+	CancelReservationAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
+	CancelReservation(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
+	MoveAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, destinationId uuid.UUID, transactionId uuid.UUID) error
+	Move(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, destinationId uuid.UUID, transactionId uuid.UUID) error
+	DiscardAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
+	Discard(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error
+	Fail(mb *message.Buffer) func(id uuid.UUID, type_ CompartmentType, transactionId uuid.UUID, err error) error
+	FailAndEmit(id uuid.UUID, type_ CompartmentType, transactionId uuid.UUID, err error) error
 }
 
 // ProcessorImpl implements the Processor interface
 type ProcessorImpl struct {
-	l   logrus.FieldLogger
-	ctx context.Context
-	db  *gorm.DB
-	t   tenant.Model
-	p   producer.Provider
-	cap asset.Processor
+	l    logrus.FieldLogger
+	ctx  context.Context
+	db   *gorm.DB
+	t    tenant.Model
+	cap  asset.Processor
+	resP reservation.Processor
+	txnP transaction.Processor
+	itmP item.Processor
 }
 
 // NewProcessor creates a new Processor instance
 func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
 	p := &ProcessorImpl{
-		l:   l,
-		ctx: ctx,
-		db:  db,
-		t:   tenant.MustFromContext(ctx),
-		p:   producer.ProviderImpl(l)(ctx),
-		cap: asset.NewProcessor(l, ctx, db),
+		l:    l,
+		ctx:  ctx,
+		db:   db,
+		t:    tenant.MustFromContext(ctx),
+		cap:  asset.NewProcessor(l, ctx, db),
+		resP: reservation.NewProcessor(l, ctx, db),
+		txnP: transaction.NewProcessor(l, ctx, db),
+		itmP: item.NewProcessor(l, ctx, db),
 	}
 	return p
 }
 
 // WithTransaction returns a new Processor with the given transaction
 func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return p.withTransaction(tx)
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
 	return &ProcessorImpl{
-		l:   p.l,
-		ctx: p.ctx,
-		db:  tx,
-		t:   p.t,
-		p:   p.p,
-		cap: p.cap,
+		l:    p.l,
+		ctx:  p.ctx,
+		db:   tx,
+		t:    p.t,
+		cap:  p.cap.WithTransaction(tx),
+		resP: p.resP.WithTransaction(tx),
+		txnP: p.txnP.WithTransaction(tx),
+		itmP: p.itmP.WithTransaction(tx),
 	}
 }
 
@@ -84,19 +112,25 @@ func (p *ProcessorImpl) DecorateAssets(m Model) Model {
 	return Clone(m).SetAssets(assets).Build()
 }
 
+// byIdForUpdateProvider retrieves a compartment by ID, holding a row lock until the surrounding transaction ends
+func (p *ProcessorImpl) byIdForUpdateProvider(id uuid.UUID) model.Provider[Model] {
+	cp := model.Map[Entity, Model](Make)(getByIdForUpdateProvider(p.t.Id())(id)(p.db))
+	return model.Map(model.Decorate(model.Decorators(p.DecorateAssets)))(cp)
+}
+
 func (p *ProcessorImpl) GetById(id uuid.UUID) (Model, error) {
 	return p.ByIdProvider(id)()
 }
 
 // ByIdProvider returns a provider for retrieving a compartment by ID
 func (p *ProcessorImpl) ByIdProvider(id uuid.UUID) model.Provider[Model] {
-	cp := model.Map[Entity, Model](Make)(getByIdProvider(p.t.Id())(id)(p.db))
+	cp := model.Map[Entity, Model](Make)(getByIdProvider(p.t.Id())(id)(database.Read(p.ctx, p.db)))
 	return model.Map(model.Decorate(model.Decorators(p.DecorateAssets)))(cp)
 }
 
 // ByAccountIdAndTypeProvider returns a provider for retrieving a compartment by account ID and type
 func (p *ProcessorImpl) ByAccountIdAndTypeProvider(accountId uint32, type_ CompartmentType) model.Provider[Model] {
-	cp := model.Map[Entity, Model](Make)(getByAccountIdAndTypeProvider(p.t.Id())(accountId)(type_)(p.db))
+	cp := model.Map[Entity, Model](Make)(getByAccountIdAndTypeProvider(p.t.Id())(accountId)(type_)(database.Read(p.ctx, p.db)))
 	return model.Map(model.Decorate(model.Decorators(p.DecorateAssets)))(cp)
 }
 
@@ -106,7 +140,7 @@ func (p *ProcessorImpl) GetByAccountIdAndType(accountId uint32, type_ Compartmen
 
 // AllByAccountIdProvider returns a provider for retrieving all compartments for an account
 func (p *ProcessorImpl) AllByAccountIdProvider(accountId uint32) model.Provider[[]Model] {
-	cp := model.SliceMap[Entity, Model](Make)(getAllByAccountIdProvider(p.t.Id())(accountId)(p.db))(model.ParallelMap())
+	cp := model.SliceMap[Entity, Model](Make)(getAllByAccountIdProvider(p.t.Id())(accountId)(database.Read(p.ctx, p.db)))(model.ParallelMap())
 	return model.SliceMap(model.Decorate(model.Decorators(p.DecorateAssets)))(cp)(model.ParallelMap())
 }
 
@@ -129,7 +163,7 @@ func (p *ProcessorImpl) Create(mb *message.Buffer) func(accountId uint32) func(t
 				}
 
 				// Add message to buffer
-				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.CreateStatusEventProvider(model.Id(), byte(type_), capacity))
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.CreateStatusEventProvider(correlation.RequestId(p.ctx), model.Id(), byte(type_), capacity))
 
 				return model, nil
 			}
@@ -139,19 +173,9 @@ func (p *ProcessorImpl) Create(mb *message.Buffer) func(accountId uint32) func(t
 
 // CreateAndEmit creates a new compartment and emits an event
 func (p *ProcessorImpl) CreateAndEmit(accountId uint32, type_ CompartmentType, capacity uint32) (Model, error) {
-	mb := message.NewBuffer()
-	m, err := p.Create(mb)(accountId)(type_)(capacity)
-	if err != nil {
-		return Model{}, err
-	}
-
-	for t, ms := range mb.GetAll() {
-		if err = p.p(t)(model.FixedProvider(ms)); err != nil {
-			return Model{}, err
-		}
-	}
-
-	return m, nil
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.withTransaction(tx).Create(buf)(accountId)(type_)(capacity)
+	})
 }
 
 // UpdateCapacity updates the capacity of a compartment
@@ -168,7 +192,7 @@ func (p *ProcessorImpl) UpdateCapacity(mb *message.Buffer) func(id uuid.UUID) fu
 			}
 
 			// Add message to buffer
-			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.UpdateStatusEventProvider(id, byte(model.Type()), capacity))
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.UpdateStatusEventProvider(correlation.RequestId(p.ctx), id, byte(model.Type()), capacity))
 
 			return model, nil
 		}
@@ -177,80 +201,82 @@ func (p *ProcessorImpl) UpdateCapacity(mb *message.Buffer) func(id uuid.UUID) fu
 
 // UpdateCapacityAndEmit updates the capacity of a compartment and emits an event
 func (p *ProcessorImpl) UpdateCapacityAndEmit(id uuid.UUID, capacity uint32) (Model, error) {
-	mb := message.NewBuffer()
-	m, err := p.UpdateCapacity(mb)(id)(capacity)
-	if err != nil {
-		return Model{}, err
-	}
-
-	for t, ms := range mb.GetAll() {
-		if err = p.p(t)(model.FixedProvider(ms)); err != nil {
-			return Model{}, err
-		}
-	}
-
-	return m, nil
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.withTransaction(tx).UpdateCapacity(buf)(id)(capacity)
+	})
 }
 
 // Delete deletes a compartment
 func (p *ProcessorImpl) Delete(mb *message.Buffer) func(id uuid.UUID) error {
 	return func(id uuid.UUID) error {
 		p.l.Debugf("Deleting compartment [%s].", id)
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			tp := p.withTransaction(tx)
+
+			// Get the compartment to get the account ID
+			m, err := tp.ByIdProvider(id)()
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not find compartment [%s] to delete.", id)
+				return err
+			}
+			return tp.delete(mb)(m)
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Could not delete compartment [%s].", id)
+			return txErr
+		}
+		return nil
+	}
+}
 
-		// Get the compartment to get the account ID
-		m, err := p.ByIdProvider(id)()
+// delete removes a compartment along with its assets, their items, and its transaction history
+func (p *ProcessorImpl) delete(mb *message.Buffer) func(m Model) error {
+	return func(m Model) error {
+		err := p.cap.DeleteAllByCompartmentId(mb)(m.Id())
 		if err != nil {
-			p.l.WithError(err).Errorf("Could not find compartment [%s] to delete.", id)
+			p.l.WithError(err).Errorf("Could not delete assets of compartment [%s].", m.Id())
 			return err
 		}
 
-		// Delete the compartment
-		err = deleteEntity(p.db, p.t.Id(), id)
+		err = deleteEntity(p.db, p.t.Id(), m.Id())
 		if err != nil {
-			p.l.WithError(err).Errorf("Could not delete compartment [%s].", id)
+			p.l.WithError(err).Errorf("Could not delete compartment [%s].", m.Id())
 			return err
 		}
 
-		_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.DeleteStatusEventProvider(id, byte(m.Type())))
+		err = p.txnP.DeleteAllByCompartmentId(m.Id())
+		if err != nil {
+			p.l.WithError(err).Errorf("Could not delete transaction history of compartment [%s].", m.Id())
+			return err
+		}
+
+		_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.DeleteStatusEventProvider(correlation.RequestId(p.ctx), m.Id(), byte(m.Type())))
 		return nil
 	}
 }
 
 // DeleteAndEmit deletes a compartment and emits an event
 func (p *ProcessorImpl) DeleteAndEmit(id uuid.UUID) error {
-	mb := message.NewBuffer()
-	err := p.Delete(mb)(id)
-	if err != nil {
-		return err
-	}
-
-	for t, ms := range mb.GetAll() {
-		if err = p.p(t)(model.FixedProvider(ms)); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Delete(buf)(id)
+	})
 }
 
 // DeleteAllByAccountId deletes all compartments for an account
 func (p *ProcessorImpl) DeleteAllByAccountId(mb *message.Buffer) func(accountId uint32) error {
 	return func(accountId uint32) error {
 		p.l.Debugf("Deleting all compartments for account [%d].", accountId)
-		txErr := p.db.Transaction(func(tx *gorm.DB) error {
-			cscm, err := p.GetByAccountId(accountId)
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			tp := p.withTransaction(tx)
+			cscm, err := tp.GetByAccountId(accountId)
 			if err != nil {
 				p.l.WithError(err).Errorf("Could not get compartments for account [%d].", accountId)
 				return err
 			}
 			for _, ccm := range cscm {
-				err = deleteEntity(p.db, p.t.Id(), ccm.Id())
-				if err != nil {
-					p.l.WithError(err).Errorf("Could not delete compartment [%s].", ccm.Id())
+				if err = tp.delete(mb)(ccm); err != nil {
 					return err
 				}
-
-				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.DeleteStatusEventProvider(ccm.Id(), byte(ccm.Type())))
 			}
 			return nil
 		})
@@ -264,97 +290,497 @@ func (p *ProcessorImpl) DeleteAllByAccountId(mb *message.Buffer) func(accountId
 
 // DeleteAllByAccountIdAndEmit deletes all compartments for an account and emits an event
 func (p *ProcessorImpl) DeleteAllByAccountIdAndEmit(accountId uint32) error {
-	mb := message.NewBuffer()
-	err := p.DeleteAllByAccountId(mb)(accountId)
-	if err != nil {
-		return err
-	}
-
-	for t, ms := range mb.GetAll() {
-		if err = p.p(t)(model.FixedProvider(ms)); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).DeleteAllByAccountId(buf)(accountId)
+	})
 }
 
 func (p *ProcessorImpl) AcceptAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
-	return message.Emit(p.p)(func(buf *message.Buffer) error {
-		return p.Accept(buf)(accountId, id, type_, assetId, transactionId)
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Accept(buf)(accountId, id, type_, assetId, transactionId)
 	})
 }
 
+// Accept moves an item into a compartment
+// A transaction which was already accepted into the compartment replays the original ACCEPTED event
+// A refused accept rolls back along with its buffered events, so the refusal is reported on its own by Fail
 func (p *ProcessorImpl) Accept(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
 	return func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
 		p.l.Debugf("Handling accepting asset for account [%d], compartment [%s], type [%d].", accountId, id, type_)
 
-		// Get the compartment
-		ccm, err := p.GetById(id)
-		if err != nil {
-			p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
-			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ErrorStatusEventProvider(id, byte(type_), "UNKNOWN_ERROR", transactionId))
-			return err
-		}
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			// Get the compartment, holding it until the asset is inserted so concurrent accepts respect capacity
+			ccm, err := p.withTransaction(tx).byIdForUpdateProvider(id)()
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
+				return err
+			}
+			if err := validateOwnership(ccm, accountId, type_); err != nil {
+				p.l.WithError(err).Errorf("Rejecting accept of asset [%d] into compartment [%s] for account [%d] and type [%d].", assetId, id, accountId, type_)
+				return err
+			}
 
-		// Create the asset entity in the database
-		_, err = p.cap.Create(mb)(id)(assetId)
-		if err != nil {
-			p.l.WithError(err).Errorf("Unable to create asset for compartment [%s] with item ID [%d].", id, assetId)
-			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ErrorStatusEventProvider(id, byte(type_), "ASSET_CREATION_FAILED", transactionId))
-			return err
-		}
+			tm, created, err := p.txnP.WithTransaction(tx).Record(id, transactionId, transaction.OperationAccept, byte(ccm.Type()), assetId)
+			if err != nil {
+				return err
+			}
+			if !created {
+				p.l.Debugf("Transaction [%s] was already accepted into compartment [%s]. Replaying result.", transactionId, id)
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.AcceptedStatusEventProvider(correlation.RequestId(p.ctx), id, tm.CompartmentType(), transactionId))
+				return nil
+			}
 
-		// Add an AcceptedStatusEventProvider result to the buffer
-		_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.AcceptedStatusEventProvider(id, byte(ccm.Type()), transactionId))
+			if uint32(len(ccm.Assets())) >= ccm.Capacity() {
+				p.l.Errorf("Compartment [%s] is full. Capacity [%d].", id, ccm.Capacity())
+				return ErrCompartmentFull
+			}
+
+			// Create the asset entity in the database
+			_, err = p.cap.WithTransaction(tx).Create(mb)(id)(assetId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to create asset for compartment [%s] with item ID [%d].", id, assetId)
+				return fmt.Errorf("%w: %w", ErrAssetCreationFailed, err)
+			}
+
+			// Add an AcceptedStatusEventProvider result to the buffer
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.AcceptedStatusEventProvider(correlation.RequestId(p.ctx), id, byte(ccm.Type()), transactionId))
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to accept asset [%d] into compartment [%s].", assetId, id)
+			return txErr
+		}
 		return nil
 	}
 }
 
 func (p *ProcessorImpl) ReleaseAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
-	return message.Emit(p.p)(func(buf *message.Buffer) error {
-		return p.Release(buf)(accountId, id, type_, assetId, transactionId)
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Release(buf)(accountId, id, type_, assetId, transactionId)
 	})
 }
 
+// Release moves an item out of a compartment
+// A transaction which was already released from the compartment replays the original RELEASED event
+// A refused release rolls back along with its buffered events, so the refusal is reported on its own by Fail
 func (p *ProcessorImpl) Release(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
 	return func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
-		p.l.Debugf("Handling releasing asset for account [%d], compartment [%d], type [%d].", accountId, id, type_)
+		p.l.Debugf("Handling releasing asset for account [%d], compartment [%s], type [%d].", accountId, id, type_)
 
-		// Get the compartment
-		ccm, err := p.GetById(id)
-		if err != nil {
-			p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
-			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ErrorStatusEventProvider(id, byte(type_), "UNKNOWN_ERROR", transactionId))
-			return err
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			// Get the compartment
+			ccm, err := p.WithTransaction(tx).GetById(id)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
+				return err
+			}
+
+			if err := validateOwnership(ccm, accountId, type_); err != nil {
+				p.l.WithError(err).Errorf("Rejecting release of asset [%d] from compartment [%s] for account [%d] and type [%d].", assetId, id, accountId, type_)
+				return err
+			}
+
+			tm, created, err := p.txnP.WithTransaction(tx).Record(id, transactionId, transaction.OperationRelease, byte(ccm.Type()), assetId)
+			if err != nil {
+				return err
+			}
+			if !created {
+				p.l.Debugf("Transaction [%s] was already released from compartment [%s]. Replaying result.", transactionId, id)
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReleasedStatusEventProvider(correlation.RequestId(p.ctx), id, tm.CompartmentType(), transactionId))
+				return nil
+			}
+
+			// Find the asset in the compartment
+			if _, ok := findAsset(ccm, assetId); !ok {
+				p.l.Errorf("Asset with ID [%d] not found in compartment [%s].", assetId, ccm.Id())
+				return ErrAssetNotFound
+			}
+
+			// The asset must be held by a reservation made for this transaction
+			resP := p.resP.WithTransaction(tx)
+			rm, err := resP.GetByItemId(assetId)
+			if errors.Is(err, reservation.ErrNotFound) {
+				p.l.Errorf("Asset [%d] in compartment [%s] is not reserved for transaction [%s].", assetId, ccm.Id(), transactionId)
+				return ErrAssetNotReserved
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to retrieve reservation for asset [%d].", assetId)
+				return err
+			}
+			if rm.TransactionId() != transactionId {
+				p.l.Errorf("Asset [%d] in compartment [%s] is reserved by transaction [%s], not [%s].", assetId, ccm.Id(), rm.TransactionId(), transactionId)
+				return reservation.ErrAlreadyReserved
+			}
+
+			// Delete the asset entity from the database
+			err = p.cap.WithTransaction(tx).Release(mb)(assetId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to remove cash compartment - cash item association for account [%d], cash item [%d].", accountId, assetId)
+				return err
+			}
+
+			// The reservation has been fulfilled
+			err = resP.Release(assetId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to clear reservation for asset [%d].", assetId)
+				return err
+			}
+
+			// Emit a cash shop status event for "cash shop item moved to inventory"
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReleasedStatusEventProvider(correlation.RequestId(p.ctx), id, byte(type_), transactionId))
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to release asset [%d] from compartment [%s].", assetId, id)
+			return txErr
 		}
+		return nil
+	}
+}
+
+func (p *ProcessorImpl) ReserveAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, characterId uint32, transactionId uuid.UUID) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Reserve(buf)(accountId, id, type_, assetId, characterId, transactionId)
+	})
+}
 
-		// Find the asset in the compartment
-		//var targetAsset asset.Model
-		found := false
-		for _, a := range ccm.Assets() {
-			if a.Item().Id() == assetId {
-				//targetAsset = a
-				found = true
-				break
+// Reserve places a hold on an asset for a character so that only the reserving transaction may release it
+// The ownership check and the hold are made in one transaction. A refused reservation is reported on its own by Fail
+func (p *ProcessorImpl) Reserve(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, characterId uint32, transactionId uuid.UUID) error {
+	return func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, characterId uint32, transactionId uuid.UUID) error {
+		p.l.Debugf("Handling reserving asset [%d] for character [%d], account [%d], compartment [%s], type [%d].", assetId, characterId, accountId, id, type_)
+
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			tp := p.withTransaction(tx)
+
+			// Get the compartment
+			ccm, err := tp.GetById(id)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
+				return err
 			}
+
+			if err := validateOwnership(ccm, accountId, type_); err != nil {
+				p.l.WithError(err).Errorf("Rejecting reservation of asset [%d] in compartment [%s] for account [%d] and type [%d].", assetId, id, accountId, type_)
+				return err
+			}
+
+			if _, ok := findAsset(ccm, assetId); !ok {
+				p.l.Errorf("Asset with ID [%d] not found in compartment [%s].", assetId, ccm.Id())
+				return ErrAssetNotFound
+			}
+
+			rm, err := tp.resP.Reserve(assetId, characterId, transactionId)
+			if errors.Is(err, reservation.ErrAlreadyReserved) {
+				// A redelivered command for the same transaction observes its own reservation.
+				if em, gerr := tp.resP.GetByItemId(assetId); gerr == nil && em.TransactionId() == transactionId {
+					rm, err = em, nil
+				}
+			}
+			if errors.Is(err, reservation.ErrAlreadyReserved) {
+				p.l.Debugf("Asset [%d] in compartment [%s] is already reserved.", assetId, ccm.Id())
+				return err
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to reserve asset [%d] for character [%d].", assetId, characterId)
+				return err
+			}
+
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReservedStatusEventProvider(correlation.RequestId(p.ctx), id, byte(ccm.Type()), assetId, rm.OwnerId(), rm.Expiration(), transactionId))
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to reserve asset [%d] in compartment [%s].", assetId, id)
+			return txErr
 		}
+		return nil
+	}
+}
+
+func (p *ProcessorImpl) CancelReservationAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).CancelReservation(buf)(accountId, id, type_, assetId, transactionId)
+	})
+}
+
+// CancelReservation removes a hold on an asset placed by the given transaction
+// The ownership check and the release of the hold are made in one transaction. A refused cancellation is reported on its own by Fail
+func (p *ProcessorImpl) CancelReservation(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
+	return func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
+		p.l.Debugf("Handling cancelling reservation of asset [%d] for account [%d], compartment [%s], type [%d].", assetId, accountId, id, type_)
 
-		if !found {
-			p.l.Errorf("Asset with ID [%d] not found in compartment [%s].", assetId, ccm.Id())
-			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ErrorStatusEventProvider(id, byte(type_), "ITEM_NOT_FOUND", transactionId))
-			return errors.New("asset not found")
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			tp := p.withTransaction(tx)
+
+			ccm, err := tp.GetById(id)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
+				return err
+			}
+
+			if err := validateOwnership(ccm, accountId, type_); err != nil {
+				p.l.WithError(err).Errorf("Rejecting cancellation of reservation of asset [%d] in compartment [%s] for account [%d] and type [%d].", assetId, id, accountId, type_)
+				return err
+			}
+
+			rm, err := tp.resP.GetByItemId(assetId)
+			if errors.Is(err, reservation.ErrNotFound) {
+				// Nothing is held, which is the requested outcome.
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReservationCancelledStatusEventProvider(correlation.RequestId(p.ctx), id, byte(type_), assetId, transactionId))
+				return nil
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to retrieve reservation for asset [%d].", assetId)
+				return err
+			}
+
+			// The reservation must be of an asset in the addressed compartment, so an account cannot cancel holds on assets of another
+			if _, ok := findAsset(ccm, assetId); !ok {
+				p.l.Errorf("Asset with ID [%d] not found in compartment [%s].", assetId, ccm.Id())
+				return ErrAssetNotFound
+			}
+
+			if rm.TransactionId() != transactionId {
+				p.l.Errorf("Asset [%d] is reserved by transaction [%s], refusing cancellation from [%s].", assetId, rm.TransactionId(), transactionId)
+				return reservation.ErrAlreadyReserved
+			}
+
+			err = tp.resP.Release(assetId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to cancel reservation for asset [%d].", assetId)
+				return err
+			}
+
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReservationCancelledStatusEventProvider(correlation.RequestId(p.ctx), id, byte(type_), assetId, transactionId))
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to cancel reservation of asset [%d] in compartment [%s].", assetId, id)
+			return txErr
 		}
+		return nil
+	}
+}
 
-		// Delete the asset entity from the database
-		err = p.cap.Release(mb)(assetId)
-		if err != nil {
-			p.l.WithError(err).Errorf("Unable to remove cash compartment - cash item association for account [%d], cash item [%d].", accountId, assetId)
-			return err
+func (p *ProcessorImpl) MoveAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, destinationId uuid.UUID, transactionId uuid.UUID) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Move(buf)(accountId, id, type_, assetId, destinationId, transactionId)
+	})
+}
+
+// Move transfers an asset between two compartments of the same account
+// The source reports RELEASED and the destination ACCEPTED, both carrying the same transaction id
+// A refused move rolls back along with its buffered events, so the refusal is reported on its own by Fail
+func (p *ProcessorImpl) Move(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, destinationId uuid.UUID, transactionId uuid.UUID) error {
+	return func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, destinationId uuid.UUID, transactionId uuid.UUID) error {
+		p.l.Debugf("Handling moving asset [%d] for account [%d] from compartment [%s] to [%s].", assetId, accountId, id, destinationId)
+
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			tp := p.withTransaction(tx)
+
+			// Get the source compartment
+			sm, err := tp.GetById(id)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
+				return err
+			}
+			if err := validateOwnership(sm, accountId, type_); err != nil {
+				p.l.WithError(err).Errorf("Rejecting move of asset [%d] from compartment [%s] for account [%d] and type [%d].", assetId, id, accountId, type_)
+				return err
+			}
+			if destinationId == id {
+				p.l.Errorf("Asset [%d] cannot be moved to the compartment [%s] it is already in.", assetId, id)
+				return ErrInvalidDestination
+			}
+
+			// Get the destination compartment, holding it until the asset is moved so concurrent accepts respect capacity
+			dm, err := tp.byIdForUpdateProvider(destinationId)()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				p.l.Errorf("Destination compartment [%s] does not exist.", destinationId)
+				return ErrInvalidDestination
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get destination compartment for ID [%s].", destinationId)
+				return err
+			}
+			if dm.AccountId() != accountId {
+				p.l.Errorf("Destination compartment [%s] does not belong to account [%d].", destinationId, accountId)
+				return ErrCompartmentMismatch
+			}
+
+			_, created, err := tp.txnP.Record(id, transactionId, transaction.OperationRelease, byte(sm.Type()), assetId)
+			if err != nil {
+				return err
+			}
+			if created {
+				_, created, err = tp.txnP.Record(destinationId, transactionId, transaction.OperationAccept, byte(dm.Type()), assetId)
+				if err != nil {
+					return err
+				}
+			}
+			if !created {
+				p.l.Debugf("Transaction [%s] already moved asset [%d] from compartment [%s] to [%s]. Replaying result.", transactionId, assetId, id, destinationId)
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReleasedStatusEventProvider(correlation.RequestId(p.ctx), id, byte(sm.Type()), transactionId))
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.AcceptedStatusEventProvider(correlation.RequestId(p.ctx), destinationId, byte(dm.Type()), transactionId))
+				return nil
+			}
+
+			if _, ok := findAsset(sm, assetId); !ok {
+				p.l.Errorf("Asset with ID [%d] not found in compartment [%s].", assetId, id)
+				return ErrAssetNotFound
+			}
+
+			// An asset held for another transaction must stay where it is
+			rm, err := tp.resP.GetByItemId(assetId)
+			if err == nil && rm.TransactionId() != transactionId {
+				p.l.Errorf("Asset [%d] in compartment [%s] is reserved by transaction [%s].", assetId, id, rm.TransactionId())
+				return reservation.ErrAlreadyReserved
+			}
+			if err != nil && !errors.Is(err, reservation.ErrNotFound) {
+				p.l.WithError(err).Errorf("Unable to retrieve reservation for asset [%d].", assetId)
+				return err
+			}
+
+			if uint32(len(dm.Assets())) >= dm.Capacity() {
+				p.l.Errorf("Destination compartment [%s] is full. Capacity [%d].", destinationId, dm.Capacity())
+				return ErrCompartmentFull
+			}
+
+			_, err = tp.cap.Move(mb)(assetId)(destinationId)
+			if err != nil {
+				return err
+			}
+
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReleasedStatusEventProvider(correlation.RequestId(p.ctx), id, byte(sm.Type()), transactionId))
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.AcceptedStatusEventProvider(correlation.RequestId(p.ctx), destinationId, byte(dm.Type()), transactionId))
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to move asset [%d] from compartment [%s] to [%s].", assetId, id, destinationId)
+			return txErr
 		}
+		return nil
+	}
+}
+
+// DiscardAndEmit discards an asset and emits the resulting event
+func (p *ProcessorImpl) DiscardAndEmit(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Discard(buf)(accountId, id, type_, assetId, transactionId)
+	})
+}
+
+// Discard removes an asset from a compartment at the request of the player, keeping its item marked as discarded
+// Reserved assets and items flagged as not discardable are refused. A transaction which was already discarded replays the original DISCARDED event
+// A refused discard rolls back along with its buffered events, so the refusal is reported on its own by Fail
+func (p *ProcessorImpl) Discard(mb *message.Buffer) func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
+	return func(accountId uint32, id uuid.UUID, type_ CompartmentType, assetId uint32, transactionId uuid.UUID) error {
+		p.l.Debugf("Handling discarding asset [%d] for account [%d], compartment [%s], type [%d].", assetId, accountId, id, type_)
+
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			ccm, err := p.WithTransaction(tx).GetById(id)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to get compartment for ID [%s].", id)
+				return err
+			}
+
+			if err := validateOwnership(ccm, accountId, type_); err != nil {
+				p.l.WithError(err).Errorf("Rejecting discard of asset [%d] from compartment [%s] for account [%d] and type [%d].", assetId, id, accountId, type_)
+				return err
+			}
+
+			tm, created, err := p.txnP.WithTransaction(tx).Record(id, transactionId, transaction.OperationDiscard, byte(ccm.Type()), assetId)
+			if err != nil {
+				return err
+			}
+			if !created {
+				p.l.Debugf("Transaction [%s] was already discarded from compartment [%s]. Replaying result.", transactionId, id)
+				im, err := p.itmP.WithTransaction(tx).GetById(assetId)
+				if err != nil {
+					return err
+				}
+				_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.DiscardedStatusEventProvider(correlation.RequestId(p.ctx), id, tm.CompartmentType(), assetId, im.TemplateId(), transactionId))
+				return nil
+			}
 
-		// Emit a cash shop status event for "cash shop item moved to inventory"
-		_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ReleasedStatusEventProvider(id, byte(type_), transactionId))
+			am, ok := findAsset(ccm, assetId)
+			if !ok {
+				p.l.Errorf("Asset with ID [%d] not found in compartment [%s].", assetId, ccm.Id())
+				return ErrAssetNotFound
+			}
+
+			// A reserved asset is being handed to a character and may not be thrown away underneath it
+			resP := p.resP.WithTransaction(tx)
+			_, err = resP.GetByItemId(assetId)
+			if err == nil {
+				p.l.Errorf("Asset [%d] in compartment [%s] is reserved and cannot be discarded.", assetId, ccm.Id())
+				return reservation.ErrAlreadyReserved
+			}
+			if !errors.Is(err, reservation.ErrNotFound) {
+				p.l.WithError(err).Errorf("Unable to retrieve reservation for asset [%d].", assetId)
+				return err
+			}
+
+			if !am.Item().Discardable() {
+				p.l.Errorf("Asset [%d] in compartment [%s] holds an item which cannot be discarded.", assetId, ccm.Id())
+				return ErrCannotDiscard
+			}
+
+			err = p.cap.WithTransaction(tx).Release(mb)(assetId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to remove asset [%d] from compartment [%s].", assetId, ccm.Id())
+				return err
+			}
+			err = p.itmP.WithTransaction(tx).Discard(assetId)
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to mark item [%d] discarded.", assetId)
+				return err
+			}
+
+			_ = mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.DiscardedStatusEventProvider(correlation.RequestId(p.ctx), id, byte(ccm.Type()), assetId, am.TemplateId(), transactionId))
+			return nil
+		})
+		if txErr != nil {
+			p.l.WithError(txErr).Errorf("Unable to discard asset [%d] from compartment [%s].", assetId, id)
+			return txErr
+		}
 		return nil
 	}
 }
+
+// Fail reports a command on the compartment which failed, translating the error into the code shown to the client
+func (p *ProcessorImpl) Fail(mb *message.Buffer) func(id uuid.UUID, type_ CompartmentType, transactionId uuid.UUID, err error) error {
+	return func(id uuid.UUID, type_ CompartmentType, transactionId uuid.UUID, err error) error {
+		code := ErrorCode(err)
+		p.l.WithError(err).Debugf("Reporting failed command on compartment [%s] as [%s].", id, code)
+		return mb.Put(compartment.EnvEventTopicStatus, compartmentProducer.ErrorStatusEventProvider(correlation.RequestId(p.ctx), id, byte(type_), code, transactionId))
+	}
+}
+
+// FailAndEmit reports a command on the compartment which failed and emits the error event
+func (p *ProcessorImpl) FailAndEmit(id uuid.UUID, type_ CompartmentType, transactionId uuid.UUID, err error) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(_ *gorm.DB, buf *message.Buffer) error {
+		return p.Fail(buf)(id, type_, transactionId, err)
+	})
+}
+
+// findAsset locates an asset in the compartment by its cash item id
+func findAsset(m Model, assetId uint32) (asset.Model, bool) {
+	for _, a := range m.Assets() {
+		if a.Item().Id() == assetId {
+			return a, true
+		}
+	}
+	return asset.Model{}, false
+}
+
+// validateOwnership ensures a command addresses a compartment of the given account and type
+func validateOwnership(m Model, accountId uint32, type_ CompartmentType) error {
+	if m.AccountId() != accountId {
+		return ErrCompartmentMismatch
+	}
+	if m.Type() != type_ {
+		return ErrTypeMismatch
+	}
+	return nil
+}
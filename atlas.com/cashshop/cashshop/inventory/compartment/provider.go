@@ -5,6 +5,7 @@ import (
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // getByIdProvider retrieves a compartment by ID
@@ -20,6 +21,19 @@ func getByIdProvider(tenantId uuid.UUID) func(id uuid.UUID) database.EntityProvi
 	}
 }
 
+// getByIdForUpdateProvider retrieves a compartment by ID and locks it for the remainder of the transaction
+func getByIdForUpdateProvider(tenantId uuid.UUID) func(id uuid.UUID) database.EntityProvider[Entity] {
+	return func(id uuid.UUID) database.EntityProvider[Entity] {
+		return func(db *gorm.DB) model.Provider[Entity] {
+			return func() (Entity, error) {
+				var entity Entity
+				result := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND tenant_id = ?", id, tenantId).First(&entity)
+				return entity, result.Error
+			}
+		}
+	}
+}
+
 // getByAccountIdAndTypeProvider retrieves a compartment by account ID and type
 func getByAccountIdAndTypeProvider(tenantId uuid.UUID) func(accountId uint32) func(type_ CompartmentType) database.EntityProvider[Entity] {
 	return func(accountId uint32) func(type_ CompartmentType) database.EntityProvider[Entity] {
@@ -0,0 +1,104 @@
+package compartment_test
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	compartmentMessage "atlas-cashshop/kafka/message/cashshop/compartment"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+func acceptCommand(s *commandSuite, transactionId uuid.UUID, itemId uint32) compartmentMessage.Command[compartmentMessage.AcceptCommandBody] {
+	return compartmentMessage.Command[compartmentMessage.AcceptCommandBody]{
+		AccountId:       testAccountId,
+		CompartmentType: byte(s.cm.Type()),
+		RequestId:       uuid.New(),
+		Type:            compartmentMessage.CommandAccept,
+		Body:            compartmentMessage.AcceptCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), ReferenceId: itemId},
+	}
+}
+
+func reserveCommand(s *commandSuite, transactionId uuid.UUID, itemId uint32) compartmentMessage.Command[compartmentMessage.ReserveCommandBody] {
+	return compartmentMessage.Command[compartmentMessage.ReserveCommandBody]{
+		AccountId:       testAccountId,
+		CompartmentType: byte(s.cm.Type()),
+		RequestId:       uuid.New(),
+		Type:            compartmentMessage.CommandReserve,
+		Body:            compartmentMessage.ReserveCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), AssetId: itemId, CharacterId: 1},
+	}
+}
+
+func releaseCommand(s *commandSuite, transactionId uuid.UUID, itemId uint32) compartmentMessage.Command[compartmentMessage.ReleaseCommandBody] {
+	return compartmentMessage.Command[compartmentMessage.ReleaseCommandBody]{
+		AccountId:       testAccountId,
+		CompartmentType: byte(s.cm.Type()),
+		RequestId:       uuid.New(),
+		Type:            compartmentMessage.CommandRelease,
+		Body:            compartmentMessage.ReleaseCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), AssetId: itemId},
+	}
+}
+
+// expectStatus checks the command caused a single status event of the type carrying the transaction id
+func expectStatus(t *testing.T, ms []kafka.Message, type_ string, transactionId uuid.UUID) {
+	t.Helper()
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 status event, got [%d]", len(ms))
+	}
+	e := decodeStatusEvent[compartmentMessage.StatusEventAcceptedBody](t, ms[0])
+	if e.Type != type_ || e.Body.TransactionId != transactionId {
+		t.Errorf("Expected a [%s] event for transaction [%s], got %+v", type_, transactionId, e)
+	}
+}
+
+func TestAcceptRedelivered(t *testing.T) {
+	s := newCommandSuite(t)
+	im := s.item()
+	before := s.assetCount(s.cm.Id())
+
+	transactionId := uuid.New()
+	expectStatus(t, send(s, acceptCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeAccepted, transactionId)
+	expectStatus(t, send(s, acceptCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeAccepted, transactionId)
+
+	if n := s.assetCount(s.cm.Id()); n != before+1 {
+		t.Errorf("Expected a redelivered accept to leave [%d] assets, got [%d]", before+1, n)
+	}
+}
+
+func TestReleaseRedelivered(t *testing.T) {
+	s := newCommandSuite(t)
+	im := s.item()
+	acceptId := uuid.New()
+	expectStatus(t, send(s, acceptCommand(s, acceptId, im.Id())), compartmentMessage.StatusEventTypeAccepted, acceptId)
+	transactionId := uuid.New()
+	expectStatus(t, send(s, reserveCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeReserved, transactionId)
+	expectStatus(t, send(s, releaseCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeReleased, transactionId)
+
+	// The item comes back before the release is redelivered, which must leave it where it is
+	returnId := uuid.New()
+	expectStatus(t, send(s, acceptCommand(s, returnId, im.Id())), compartmentMessage.StatusEventTypeAccepted, returnId)
+	before := s.assetCount(s.cm.Id())
+	expectStatus(t, send(s, releaseCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeReleased, transactionId)
+
+	if n := s.assetCount(s.cm.Id()); n != before {
+		t.Errorf("Expected a redelivered release to leave [%d] assets, got [%d]", before, n)
+	}
+	if _, err := asset.NewProcessor(s.l, s.ctx, s.db).GetByItemId(im.Id()); err != nil {
+		t.Errorf("Expected a redelivered release to leave the asset of item [%d]: %v", im.Id(), err)
+	}
+}
+
+func TestTransactionReusedForAnotherOperation(t *testing.T) {
+	s := newCommandSuite(t)
+	im := s.item()
+	before := s.assetCount(s.cm.Id())
+
+	transactionId := uuid.New()
+	expectStatus(t, send(s, acceptCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeAccepted, transactionId)
+	expectStatus(t, send(s, reserveCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeReserved, transactionId)
+	expectStatus(t, send(s, releaseCommand(s, transactionId, im.Id())), compartmentMessage.StatusEventTypeReleased, transactionId)
+
+	if n := s.assetCount(s.cm.Id()); n != before {
+		t.Errorf("Expected the release to be applied rather than replayed, leaving [%d] assets, got [%d]", before, n)
+	}
+}
@@ -0,0 +1,89 @@
+package compartment_test
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/database/fixture"
+	compartmentMessage "atlas-cashshop/kafka/message/cashshop/compartment"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCancelReservationRejected(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	cp := compartment.NewProcessor(l, ctx, db)
+	cm, err := cp.GetByAccountIdAndType(testAccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	am := grantAsset(t, l, ctx, db, cm, 0)
+
+	transactionId := uuid.New()
+	if err = cp.ReserveAndEmit(testAccountId, cm.Id(), cm.Type(), am.Item().Id(), 1, transactionId); err != nil {
+		t.Fatalf("Unable to reserve asset: %v", err)
+	}
+
+	if err = cp.CancelReservationAndEmit(testAccountId+1, cm.Id(), cm.Type(), am.Item().Id(), transactionId); !errors.Is(err, compartment.ErrCompartmentMismatch) {
+		t.Errorf("Expected cancelling a reservation in the compartment of another account to be rejected, got [%v]", err)
+	}
+	if err = cp.CancelReservationAndEmit(testAccountId, cm.Id(), compartment.TypeCygnus, am.Item().Id(), transactionId); !errors.Is(err, compartment.ErrTypeMismatch) {
+		t.Errorf("Expected cancelling a reservation with another compartment type to be rejected, got [%v]", err)
+	}
+	if _, err = reservation.NewProcessor(l, ctx, db).GetByItemId(am.Item().Id()); err != nil {
+		t.Errorf("Expected the reservation to remain: %v", err)
+	}
+
+	if err = cp.CancelReservationAndEmit(testAccountId, cm.Id(), cm.Type(), am.Item().Id(), transactionId); err != nil {
+		t.Errorf("Unable to cancel own reservation: %v", err)
+	}
+}
+
+func TestReserveRejected(t *testing.T) {
+	s := newCommandSuite(t)
+	am := grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+	held := uuid.New()
+	if err := compartment.NewProcessor(s.l, s.ctx, s.db).ReserveAndEmit(testAccountId, s.cm.Id(), s.cm.Type(), am.Item().Id(), 2000, held); err != nil {
+		t.Fatalf("Unable to reserve asset: %v", err)
+	}
+
+	requestId := uuid.New()
+	transactionId := uuid.New()
+	ms := send(s, compartmentMessage.Command[compartmentMessage.ReserveCommandBody]{
+		AccountId:       testAccountId,
+		CompartmentType: byte(s.cm.Type()),
+		RequestId:       requestId,
+		Type:            compartmentMessage.CommandReserve,
+		Body:            compartmentMessage.ReserveCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), AssetId: am.Item().Id(), CharacterId: 2001},
+	})
+	expectError(t, ms, compartmentMessage.ErrorCodeAssetReserved, requestId, transactionId)
+
+	rm, err := reservation.NewProcessor(s.l, s.ctx, s.db).GetByItemId(am.Item().Id())
+	if err != nil || rm.TransactionId() != held {
+		t.Errorf("Expected the reservation of transaction [%s] to remain, got [%s] [%v]", held, rm.TransactionId(), err)
+	}
+}
+
+func TestCancelReservationOfAnotherTransactionRejected(t *testing.T) {
+	s := newCommandSuite(t)
+	am := grantAsset(t, s.l, s.ctx, s.db, s.cm, 0)
+	if err := compartment.NewProcessor(s.l, s.ctx, s.db).ReserveAndEmit(testAccountId, s.cm.Id(), s.cm.Type(), am.Item().Id(), 2000, uuid.New()); err != nil {
+		t.Fatalf("Unable to reserve asset: %v", err)
+	}
+
+	requestId := uuid.New()
+	transactionId := uuid.New()
+	ms := send(s, compartmentMessage.Command[compartmentMessage.CancelReservationCommandBody]{
+		AccountId:       testAccountId,
+		CompartmentType: byte(s.cm.Type()),
+		RequestId:       requestId,
+		Type:            compartmentMessage.CommandCancelReservation,
+		Body:            compartmentMessage.CancelReservationCommandBody{TransactionId: transactionId, CompartmentId: s.cm.Id(), AssetId: am.Item().Id()},
+	})
+	expectError(t, ms, compartmentMessage.ErrorCodeAssetReserved, requestId, transactionId)
+
+	if _, err := reservation.NewProcessor(s.l, s.ctx, s.db).GetByItemId(am.Item().Id()); err != nil {
+		t.Errorf("Expected the reservation to remain: %v", err)
+	}
+}
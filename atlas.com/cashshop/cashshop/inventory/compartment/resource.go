@@ -1,9 +1,13 @@
 package compartment
 
 import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
 	"atlas-cashshop/rest"
+	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/server"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jtumidanski/api2go/jsonapi"
 	"github.com/sirupsen/logrus"
@@ -16,9 +20,22 @@ import (
 func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
 	return func(db *gorm.DB) server.RouteInitializer {
 		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(ErrAssetNotFound, http.StatusNotFound, "ASSET_NOT_FOUND", "Asset not found")
+			rest.RegisterError(ErrAssetNotReserved, http.StatusConflict, "ASSET_NOT_RESERVED", "Asset not reserved")
+			rest.RegisterError(reservation.ErrAlreadyReserved, http.StatusConflict, "ASSET_RESERVED", "Asset reserved by another transaction")
+			rest.RegisterError(ErrCompartmentFull, http.StatusConflict, "COMPARTMENT_FULL", "Compartment full")
+			rest.RegisterError(ErrCompartmentExists, http.StatusConflict, "COMPARTMENT_EXISTS", "Compartment already exists")
+			rest.RegisterError(ErrCompartmentMismatch, http.StatusUnprocessableEntity, "COMPARTMENT_MISMATCH", "Compartment belongs to another account")
+			rest.RegisterError(ErrTypeMismatch, http.StatusUnprocessableEntity, "COMPARTMENT_TYPE_MISMATCH", "Compartment of another type")
+			rest.RegisterError(ErrInvalidDestination, http.StatusUnprocessableEntity, "INVALID_DESTINATION", "Invalid destination compartment")
+			rest.RegisterError(ErrCannotDiscard, http.StatusConflict, "CANNOT_DISCARD", "Item cannot be discarded")
+
 			registerGet := rest.RegisterHandler(l)(si)
 			r := router.PathPrefix("/accounts/{accountId}/cash-shop/inventory/compartments").Subrouter()
 			r.HandleFunc("", registerGet("get_cash_compartments", handleGetCompartments(db))).Methods(http.MethodGet).Queries("type", "{type}")
+			r.HandleFunc("/{compartmentId}/assets", registerGet("get_cash_assets", handleGetAssets(db))).Methods(http.MethodGet)
+			r.HandleFunc("/{compartmentId}/assets/{assetId}", rest.RegisterInputHandler[asset.RestModel](l)(si)("move_cash_asset", handleMoveAsset(db))).Methods(http.MethodPatch)
+			r.HandleFunc("/{compartmentId}/assets/{assetId}", registerGet("discard_cash_asset", handleDiscardAsset(db))).Methods(http.MethodDelete)
 		}
 	}
 }
@@ -40,7 +57,7 @@ func handleGetCompartments(db *gorm.DB) rest.GetHandler {
 					typeInt, err := strconv.Atoi(typeStr)
 					if err != nil {
 						d.Logger().WithError(err).Errorf("Invalid type parameter: %s", typeStr)
-						w.WriteHeader(http.StatusBadRequest)
+						rest.WriteError(d.Logger())(w)(rest.InvalidParameter("type").WithDetail(err.Error()))
 						return
 					}
 
@@ -53,7 +70,7 @@ func handleGetCompartments(db *gorm.DB) rest.GetHandler {
 					res, err := model.Map(Transform)(compartmentProvider)()
 					if err != nil {
 						d.Logger().WithError(err).Errorf("Creating REST model.")
-						w.WriteHeader(http.StatusInternalServerError)
+						rest.WriteError(d.Logger())(w)(err)
 						return
 					}
 
@@ -67,7 +84,7 @@ func handleGetCompartments(db *gorm.DB) rest.GetHandler {
 				res, err := model.SliceMap(Transform)(processor.AllByAccountIdProvider(accountId))(model.ParallelMap())()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -78,3 +95,152 @@ func handleGetCompartments(db *gorm.DB) rest.GetHandler {
 		})
 	}
 }
+
+// handleGetAssets handles the GET request for a page of the assets of a compartment, filtered and sorted by their items
+func handleGetAssets(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return rest.ParseCompartmentId(d.Logger(), func(compartmentId uuid.UUID) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					page, err := rest.ParsePage(r)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					q, err := asset.ParseQuery(r, page)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+
+					cm, err := NewProcessor(d.Logger(), d.Context(), db).GetById(compartmentId)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					if cm.AccountId() != accountId {
+						d.Logger().Errorf("Compartment [%s] not found for account [%d].", compartmentId, accountId)
+						rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("compartment does not belong to account"))
+						return
+					}
+
+					ap := asset.NewProcessor(d.Logger(), d.Context(), db)
+					total, err := ap.CountByQuery(compartmentId, q)
+					if err != nil {
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+					res, err := model.SliceMap(asset.Transform)(ap.ByQueryProvider(compartmentId, q))()()
+					if err != nil {
+						d.Logger().WithError(err).Errorf("Creating REST model.")
+						rest.WriteError(d.Logger())(w)(err)
+						return
+					}
+
+					rest.MarshalPage[[]asset.RestModel](d.Logger())(w)(c.ServerInformation())(r)(page, total)(res)
+				}
+			})
+		})
+	}
+}
+
+// handleMoveAsset handles the PATCH request which moves an asset to the compartment named in the body
+func handleMoveAsset(db *gorm.DB) rest.InputHandler[asset.RestModel] {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext, input asset.RestModel) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return rest.ParseCompartmentId(d.Logger(), func(compartmentId uuid.UUID) http.HandlerFunc {
+				return rest.ParseAssetId(d.Logger(), func(assetId uuid.UUID) http.HandlerFunc {
+					return func(w http.ResponseWriter, r *http.Request) {
+						ap := asset.NewProcessor(d.Logger(), d.Context(), db)
+						am, err := ap.GetById(assetId)
+						if err != nil {
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+						if am.CompartmentId() != compartmentId {
+							d.Logger().Errorf("Asset with ID [%s] not found in compartment [%s].", assetId, compartmentId)
+							rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("asset does not belong to compartment"))
+							return
+						}
+
+						cp := NewProcessor(d.Logger(), d.Context(), db)
+						cm, err := cp.GetById(compartmentId)
+						if err != nil {
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+						if cm.AccountId() != accountId {
+							d.Logger().Errorf("Compartment [%s] not found for account [%d].", compartmentId, accountId)
+							rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("compartment does not belong to account"))
+							return
+						}
+
+						err = cp.MoveAndEmit(accountId, compartmentId, cm.Type(), am.Item().Id(), input.CompartmentId, uuid.New())
+						if errors.Is(err, ErrInvalidDestination) || errors.Is(err, ErrCompartmentMismatch) {
+							rest.WriteError(d.Logger())(w)(rest.ErrorFor(err).WithPointer("/data/attributes/compartmentId"))
+							return
+						}
+						if err != nil {
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+
+						res, err := model.Map(asset.Transform)(ap.ByIdProvider(assetId))()
+						if err != nil {
+							d.Logger().WithError(err).Errorf("Creating REST model.")
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+
+						query := r.URL.Query()
+						queryParams := jsonapi.ParseQueryFields(&query)
+						server.MarshalResponse[asset.RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(res)
+					}
+				})
+			})
+		})
+	}
+}
+
+// handleDiscardAsset handles the DELETE request which discards an asset, keeping its item marked as discarded
+func handleDiscardAsset(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
+			return rest.ParseCompartmentId(d.Logger(), func(compartmentId uuid.UUID) http.HandlerFunc {
+				return rest.ParseAssetId(d.Logger(), func(assetId uuid.UUID) http.HandlerFunc {
+					return func(w http.ResponseWriter, r *http.Request) {
+						am, err := asset.NewProcessor(d.Logger(), d.Context(), db).GetById(assetId)
+						if err != nil {
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+						if am.CompartmentId() != compartmentId {
+							d.Logger().Errorf("Asset with ID [%s] not found in compartment [%s].", assetId, compartmentId)
+							rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("asset does not belong to compartment"))
+							return
+						}
+
+						cp := NewProcessor(d.Logger(), d.Context(), db)
+						cm, err := cp.GetById(compartmentId)
+						if err != nil {
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+						if cm.AccountId() != accountId {
+							d.Logger().Errorf("Compartment [%s] not found for account [%d].", compartmentId, accountId)
+							rest.WriteError(d.Logger())(w)(rest.ErrNotFound.WithDetail("compartment does not belong to account"))
+							return
+						}
+
+						err = cp.DiscardAndEmit(accountId, compartmentId, cm.Type(), am.Item().Id(), uuid.New())
+						if err != nil {
+							rest.WriteError(d.Logger())(w)(err)
+							return
+						}
+						w.WriteHeader(http.StatusNoContent)
+					}
+				})
+			})
+		})
+	}
+}
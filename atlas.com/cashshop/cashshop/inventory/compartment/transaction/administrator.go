@@ -0,0 +1,33 @@
+package transaction
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// createEntity records a transaction unless it has already been recorded
+// The returned flag is false when the transaction was previously applied
+func createEntity(db *gorm.DB, tenantId uuid.UUID, compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation, compartmentType byte, assetId uint32) (Entity, bool, error) {
+	entity := Entity{
+		TenantId:        tenantId,
+		CompartmentId:   compartmentId,
+		TransactionId:   transactionId,
+		Operation:       string(operation),
+		CompartmentType: compartmentType,
+		AssetId:         assetId,
+		CreatedAt:       time.Now(),
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity)
+	if result.Error != nil {
+		return Entity{}, false, result.Error
+	}
+	return entity, result.RowsAffected == 1, nil
+}
+
+// deleteAllByCompartmentId deletes the transaction history of a compartment
+func deleteAllByCompartmentId(db *gorm.DB, tenantId uuid.UUID, compartmentId uuid.UUID) error {
+	return db.Where("tenant_id = ? AND compartment_id = ?", tenantId, compartmentId).Delete(&Entity{}).Error
+}
@@ -0,0 +1,35 @@
+package transaction
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Entity represents a processed compartment transaction in the database
+type Entity struct {
+	TenantId        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	CompartmentId   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TransactionId   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Operation       string    `gorm:"primaryKey"`
+	CompartmentType byte      `gorm:"not null"`
+	AssetId         uint32    `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"not null"`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_compartment_transactions"
+}
+
+// Make converts an Entity to a Model
+func Make(e Entity) (Model, error) {
+	return Model{
+		tenantId:        e.TenantId,
+		compartmentId:   e.CompartmentId,
+		transactionId:   e.TransactionId,
+		operation:       Operation(e.Operation),
+		compartmentType: e.CompartmentType,
+		assetId:         e.AssetId,
+		createdAt:       e.CreatedAt,
+	}, nil
+}
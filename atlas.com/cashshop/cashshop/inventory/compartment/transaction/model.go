@@ -0,0 +1,61 @@
+package transaction
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Operation identifies the compartment command a transaction was applied by
+type Operation string
+
+const (
+	OperationAccept  = Operation("ACCEPT")
+	OperationRelease = Operation("RELEASE")
+	OperationDiscard = Operation("DISCARD")
+)
+
+// Model records a transaction which has already been applied to a compartment
+type Model struct {
+	tenantId        uuid.UUID
+	compartmentId   uuid.UUID
+	transactionId   uuid.UUID
+	operation       Operation
+	compartmentType byte
+	assetId         uint32
+	createdAt       time.Time
+}
+
+// TenantId returns the tenant the transaction belongs to
+func (m Model) TenantId() uuid.UUID {
+	return m.tenantId
+}
+
+// CompartmentId returns the compartment the transaction was applied to
+func (m Model) CompartmentId() uuid.UUID {
+	return m.compartmentId
+}
+
+// TransactionId returns the caller supplied transaction identifier
+func (m Model) TransactionId() uuid.UUID {
+	return m.transactionId
+}
+
+// Operation returns the command the transaction was applied by
+func (m Model) Operation() Operation {
+	return m.operation
+}
+
+// CompartmentType returns the compartment type reported in the original event
+func (m Model) CompartmentType() byte {
+	return m.compartmentType
+}
+
+// AssetId returns the cash item the transaction moved
+func (m Model) AssetId() uint32 {
+	return m.assetId
+}
+
+// CreatedAt returns when the transaction was first applied
+func (m Model) CreatedAt() time.Time {
+	return m.createdAt
+}
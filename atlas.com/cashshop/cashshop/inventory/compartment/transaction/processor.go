@@ -0,0 +1,78 @@
+package transaction
+
+import (
+	"context"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Processor tracks which transactions have been applied to compartments
+type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
+	ByTransactionIdProvider(compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation) model.Provider[Model]
+	GetByTransactionId(compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation) (Model, error)
+	Record(compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation, compartmentType byte, assetId uint32) (Model, bool, error)
+	DeleteAllByCompartmentId(compartmentId uuid.UUID) error
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	db  *gorm.DB
+	t   tenant.Model
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		db:  db,
+		t:   tenant.MustFromContext(ctx),
+	}
+}
+
+// WithTransaction returns a new Processor with the given transaction
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   p.l,
+		ctx: p.ctx,
+		db:  tx,
+		t:   p.t,
+	}
+}
+
+// ByTransactionIdProvider retrieves a processed transaction
+func (p *ProcessorImpl) ByTransactionIdProvider(compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation) model.Provider[Model] {
+	return model.Map(Make)(getByTransactionIdProvider(p.t.Id())(compartmentId)(transactionId)(operation)(p.db))
+}
+
+// GetByTransactionId retrieves a processed transaction
+func (p *ProcessorImpl) GetByTransactionId(compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation) (Model, error) {
+	return p.ByTransactionIdProvider(compartmentId, transactionId, operation)()
+}
+
+// Record marks a transaction as applied to a compartment
+// When the transaction was already recorded, the original record is returned along with false
+func (p *ProcessorImpl) Record(compartmentId uuid.UUID, transactionId uuid.UUID, operation Operation, compartmentType byte, assetId uint32) (Model, bool, error) {
+	e, created, err := createEntity(p.db, p.t.Id(), compartmentId, transactionId, operation, compartmentType, assetId)
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to record transaction [%s] for compartment [%s].", transactionId, compartmentId)
+		return Model{}, false, err
+	}
+	if !created {
+		m, err := p.GetByTransactionId(compartmentId, transactionId, operation)
+		return m, false, err
+	}
+	m, err := Make(e)
+	return m, true, err
+}
+
+// DeleteAllByCompartmentId removes the transaction history of a compartment
+func (p *ProcessorImpl) DeleteAllByCompartmentId(compartmentId uuid.UUID) error {
+	return deleteAllByCompartmentId(p.db, p.t.Id(), compartmentId)
+}
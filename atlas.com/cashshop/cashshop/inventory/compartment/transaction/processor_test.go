@@ -0,0 +1,58 @@
+package transaction_test
+
+import (
+	"atlas-cashshop/cashshop/inventory/compartment/transaction"
+	"atlas-cashshop/database/fixture"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecord(t *testing.T) {
+	db := fixture.Open(t)
+	p := transaction.NewProcessor(logrus.New(), fixture.Tenant(t), db)
+	compartmentId := uuid.New()
+	transactionId := uuid.New()
+
+	m, created, err := p.Record(compartmentId, transactionId, transaction.OperationAccept, 1, 10)
+	if err != nil {
+		t.Fatalf("Unable to record transaction: %v", err)
+	}
+	if !created || m.CompartmentId() != compartmentId || m.TransactionId() != transactionId || m.Operation() != transaction.OperationAccept || m.CompartmentType() != 1 || m.AssetId() != 10 {
+		t.Fatalf("Unexpected first record created [%t] %+v", created, m)
+	}
+
+	// A redelivery returns the original record, whatever it carries
+	m, created, err = p.Record(compartmentId, transactionId, transaction.OperationAccept, 2, 20)
+	if err != nil {
+		t.Fatalf("Unable to record transaction again: %v", err)
+	}
+	if created {
+		t.Errorf("Expected a recorded transaction to be reported as already applied")
+	}
+	if m.CompartmentType() != 1 || m.AssetId() != 10 {
+		t.Errorf("Expected the original record to be returned, got type [%d] asset [%d]", m.CompartmentType(), m.AssetId())
+	}
+
+	// The same transaction id applied by another operation, or to another compartment, is a new transaction
+	if _, created, err = p.Record(compartmentId, transactionId, transaction.OperationRelease, 1, 10); err != nil || !created {
+		t.Errorf("Expected a transaction reused for another operation to be recorded, created [%t]: %v", created, err)
+	}
+	if _, created, err = p.Record(uuid.New(), transactionId, transaction.OperationAccept, 1, 10); err != nil || !created {
+		t.Errorf("Expected a transaction reused for another compartment to be recorded, created [%t]: %v", created, err)
+	}
+}
+
+func TestRecordIsTenantScoped(t *testing.T) {
+	db := fixture.Open(t)
+	compartmentId := uuid.New()
+	transactionId := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		p := transaction.NewProcessor(logrus.New(), fixture.Tenant(t), db)
+		if _, created, err := p.Record(compartmentId, transactionId, transaction.OperationAccept, 1, 10); err != nil || !created {
+			t.Fatalf("Expected tenant [%d] to record its own transaction, created [%t]: %v", i, created, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package transaction
+
+import (
+	"atlas-cashshop/database"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// getByTransactionIdProvider retrieves a processed transaction for a compartment and operation
+func getByTransactionIdProvider(tenantId uuid.UUID) func(compartmentId uuid.UUID) func(transactionId uuid.UUID) func(operation Operation) database.EntityProvider[Entity] {
+	return func(compartmentId uuid.UUID) func(transactionId uuid.UUID) func(operation Operation) database.EntityProvider[Entity] {
+		return func(transactionId uuid.UUID) func(operation Operation) database.EntityProvider[Entity] {
+			return func(operation Operation) database.EntityProvider[Entity] {
+				return func(db *gorm.DB) model.Provider[Entity] {
+					return func() (Entity, error) {
+						var entity Entity
+						result := db.Where("tenant_id = ? AND compartment_id = ? AND transaction_id = ? AND operation = ?", tenantId, compartmentId, transactionId, string(operation)).First(&entity)
+						return entity, result.Error
+					}
+				}
+			}
+		}
+	}
+}
@@ -2,11 +2,13 @@ package inventory
 
 import (
 	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
 	inventoryMsg "atlas-cashshop/kafka/message/cashshop/inventory"
-	"atlas-cashshop/kafka/producer"
 	inventory2 "atlas-cashshop/kafka/producer/cashshop/inventory"
+	"atlas-cashshop/outbox"
 	"context"
+	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
@@ -18,7 +20,7 @@ type Processor interface {
 	WithTransaction(tx *gorm.DB) Processor
 	ByAccountIdProvider(accountId uint32) model.Provider[Model]
 	GetByAccountId(accountId uint32) (Model, error)
-	Create(mb *message.Buffer) func(accountId uint32) (Model, error)
+	Create(mb *message.Buffer) func(accountId uint32) (Model, bool, error)
 	CreateAndEmit(accountId uint32) (Model, error)
 	Delete(mb *message.Buffer) func(accountId uint32) error
 	DeleteAndEmit(accountId uint32) error
@@ -30,7 +32,6 @@ type ProcessorImpl struct {
 	ctx context.Context
 	db  *gorm.DB
 	t   tenant.Model
-	p   producer.Provider
 	ccp compartment.Processor
 }
 
@@ -41,7 +42,6 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 		ctx: ctx,
 		db:  db,
 		t:   tenant.MustFromContext(ctx),
-		p:   producer.ProviderImpl(l)(ctx),
 		ccp: compartment.NewProcessor(l, ctx, db),
 	}
 	return p
@@ -49,13 +49,16 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 
 // WithTransaction returns a new Processor with the given transaction
 func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return p.withTransaction(tx)
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
 	return &ProcessorImpl{
 		l:   p.l,
 		ctx: p.ctx,
 		db:  tx,
 		t:   p.t,
-		p:   p.p,
-		ccp: p.ccp,
+		ccp: p.ccp.WithTransaction(tx),
 	}
 }
 
@@ -86,76 +89,64 @@ func (p *ProcessorImpl) GetByAccountId(accountId uint32) (Model, error) {
 	return p.ByAccountIdProvider(accountId)()
 }
 
-// createDefaultCompartments creates the default compartments for an account
-func (p *ProcessorImpl) createDefaultCompartments(accountId uint32) (Model, error) {
-	p.l.Debugf("Creating default compartments for account [%d] with capacity [%d].", accountId, compartment.DefaultCapacity)
-
-	// Create a compartment processor
-	compartmentProcessor := compartment.NewProcessor(p.l, p.ctx, p.db)
-
-	// Create Explorer compartment
-	explorerCompartment, err := compartmentProcessor.CreateAndEmit(accountId, compartment.TypeExplorer, compartment.DefaultCapacity)
-	if err != nil {
-		p.l.WithError(err).Errorf("Could not create Explorer compartment for account [%d].", accountId)
-		return Model{}, err
-	}
-
-	// Create Cygnus compartment
-	cygnusCompartment, err := compartmentProcessor.CreateAndEmit(accountId, compartment.TypeCygnus, compartment.DefaultCapacity)
-	if err != nil {
-		p.l.WithError(err).Errorf("Could not create Cygnus compartment for account [%d].", accountId)
-		return Model{}, err
-	}
+// DefaultCompartmentTypes are the compartments every account is provisioned with
+var DefaultCompartmentTypes = []compartment.CompartmentType{compartment.TypeExplorer, compartment.TypeCygnus, compartment.TypeLegend}
 
-	// Create Legend compartment
-	legendCompartment, err := compartmentProcessor.CreateAndEmit(accountId, compartment.TypeLegend, compartment.DefaultCapacity)
-	if err != nil {
-		p.l.WithError(err).Errorf("Could not create Legend compartment for account [%d].", accountId)
-		return Model{}, err
+// createMissingCompartments creates any default compartment the account does not have yet
+// The returned flag reports whether a compartment was created
+func (p *ProcessorImpl) createMissingCompartments(mb *message.Buffer) func(accountId uint32) (Model, bool, error) {
+	return func(accountId uint32) (Model, bool, error) {
+		builder := NewBuilder(accountId)
+		created := false
+		for _, ct := range DefaultCompartmentTypes {
+			c, err := p.ccp.GetByAccountIdAndType(accountId, ct)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				p.l.Debugf("Creating [%d] compartment for account [%d] with capacity [%d].", ct, accountId, compartment.DefaultCapacity)
+				c, err = p.ccp.Create(mb)(accountId)(ct)(compartment.DefaultCapacity)
+				created = true
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Could not provision [%d] compartment for account [%d].", ct, accountId)
+				return Model{}, false, err
+			}
+			builder.SetCompartment(c)
+		}
+		return builder.Build(), created, nil
 	}
-
-	// Build the inventory model
-	builder := NewBuilder(accountId)
-	builder.SetCompartment(explorerCompartment)
-	builder.SetCompartment(cygnusCompartment)
-	builder.SetCompartment(legendCompartment)
-
-	return builder.Build(), nil
 }
 
-// Create creates a new inventory with default compartments
-func (p *ProcessorImpl) Create(mb *message.Buffer) func(accountId uint32) (Model, error) {
-	return func(accountId uint32) (Model, error) {
-		p.l.Debugf("Initializing cash shop inventory for account [%d] with capacity [%d].", accountId)
-
-		// Create default compartments
-		inventory, err := p.createDefaultCompartments(accountId)
-		if err != nil {
-			return Model{}, err
+// Create provisions the default compartments of an account
+// Compartments which already exist are left untouched, so repeating the call is safe. The flag reports whether a compartment was created
+func (p *ProcessorImpl) Create(mb *message.Buffer) func(accountId uint32) (Model, bool, error) {
+	return func(accountId uint32) (Model, bool, error) {
+		p.l.Debugf("Initializing cash shop inventory for account [%d].", accountId)
+
+		var result Model
+		var created bool
+		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			inventory, c, err := p.withTransaction(tx).createMissingCompartments(mb)(accountId)
+			if err != nil {
+				return err
+			}
+			if c {
+				_ = mb.Put(inventoryMsg.EnvEventTopicStatus, inventory2.CreateStatusEventProvider(accountId))
+			}
+			result, created = inventory, c
+			return nil
+		})
+		if txErr != nil {
+			return Model{}, false, txErr
 		}
-
-		// Add message to buffer
-		_ = mb.Put(inventoryMsg.EnvEventTopicStatus, inventory2.CreateStatusEventProvider(accountId))
-
-		return inventory, nil
+		return result, created, nil
 	}
 }
 
 // CreateAndEmit creates a new inventory and emits an event
 func (p *ProcessorImpl) CreateAndEmit(accountId uint32) (Model, error) {
-	mb := message.NewBuffer()
-	m, err := p.Create(mb)(accountId)
-	if err != nil {
-		return Model{}, err
-	}
-
-	for t, ms := range mb.GetAll() {
-		if err = p.p(t)(model.FixedProvider(ms)); err != nil {
-			return Model{}, err
-		}
-	}
-
-	return m, nil
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		m, _, err := p.withTransaction(tx).Create(buf)(accountId)
+		return m, err
+	})
 }
 
 // Delete deletes an inventory and all its compartments and assets
@@ -163,11 +154,8 @@ func (p *ProcessorImpl) Delete(mb *message.Buffer) func(accountId uint32) error
 	return func(accountId uint32) error {
 		p.l.Debugf("Account [%d] was deleted. Cleaning up cash shop inventory...", accountId)
 
-		// Create a compartment processor
-		compartmentProcessor := compartment.NewProcessor(p.l, p.ctx, p.db)
-
-		// Delete all compartments for the account
-		err := compartmentProcessor.DeleteAllByAccountIdAndEmit(accountId)
+		// Delete all compartments, assets, and items for the account
+		err := p.ccp.DeleteAllByAccountId(mb)(accountId)
 		if err != nil {
 			return err
 		}
@@ -180,17 +168,7 @@ func (p *ProcessorImpl) Delete(mb *message.Buffer) func(accountId uint32) error
 
 // DeleteAndEmit deletes an inventory and emits an event
 func (p *ProcessorImpl) DeleteAndEmit(accountId uint32) error {
-	mb := message.NewBuffer()
-	err := p.Delete(mb)(accountId)
-	if err != nil {
-		return err
-	}
-
-	for t, ms := range mb.GetAll() {
-		if err = p.p(t)(model.FixedProvider(ms)); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Delete(buf)(accountId)
+	})
 }
@@ -1,8 +1,8 @@
 package inventory
 
 import (
+	"atlas-cashshop/cashshop/inventory/compartment"
 	"atlas-cashshop/rest"
-	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/server"
 	"github.com/gorilla/mux"
@@ -16,6 +16,8 @@ import (
 func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
 	return func(db *gorm.DB) server.RouteInitializer {
 		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(compartment.ErrCompartmentExists, http.StatusConflict, "COMPARTMENT_EXISTS", "Compartment already exists")
+
 			registerGet := rest.RegisterHandler(l)(si)
 			registerInput := rest.RegisterInputHandler[RestModel](l)(si)
 			r := router.PathPrefix("/accounts/{accountId}/cash-shop/inventory").Subrouter()
@@ -32,13 +34,9 @@ func handleGetCashInventory(db *gorm.DB) rest.GetHandler {
 			return func(w http.ResponseWriter, r *http.Request) {
 				// Get the inventory by account ID
 				m, err := NewProcessor(d.Logger(), d.Context(), db).GetByAccountId(accountId)
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					w.WriteHeader(http.StatusNotFound)
-					return
-				}
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Error retrieving cash inventory for account [%d]", accountId)
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -46,7 +44,7 @@ func handleGetCashInventory(db *gorm.DB) rest.GetHandler {
 				res, err := model.Map(Transform)(model.FixedProvider(m))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -68,7 +66,7 @@ func handleCreateCashInventory(db *gorm.DB) rest.InputHandler[RestModel] {
 				m, err := NewProcessor(d.Logger(), d.Context(), db).CreateAndEmit(accountId)
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Error creating cash inventory for account [%d]", accountId)
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -76,7 +74,7 @@ func handleCreateCashInventory(db *gorm.DB) rest.InputHandler[RestModel] {
 				res, err := model.Map(Transform)(model.FixedProvider(m))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
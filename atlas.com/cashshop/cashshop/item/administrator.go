@@ -1,47 +1,37 @@
 package item
 
 import (
+	"atlas-cashshop/cashshop/item/cashid"
 	"atlas-cashshop/database"
+	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
-	"math/rand"
 	"time"
 )
 
-func generateUniqueCashId(tenantId uuid.UUID, db *gorm.DB) (int64, error) {
-	for {
-		cashId := rand.Int63()
-		entities, err := byCashIdEntityProvider(tenantId, cashId)(db)()
-		if err != nil {
-			return 0, err
-		}
-		if len(entities) == 0 {
-			return cashId, nil
-		}
-	}
-}
-
-func createEntityProvider(tenantId uuid.UUID, templateId uint32, quantity uint32, purchasedBy uint32) database.EntityProvider[Entity] {
+func createEntityProvider(tenantId uuid.UUID, templateId uint32, quantity uint32, flag uint16, purchasedBy uint32, expiration time.Time) database.EntityProvider[Entity] {
 	return func(db *gorm.DB) model.Provider[Entity] {
-		cashId, err := generateUniqueCashId(tenantId, db)
+		cashId, err := cashid.Next(db)
 		if err != nil {
 			return model.ErrorProvider[Entity](err)
 		}
 
-		expiration := time.Now().AddDate(0, 0, 30) // 30 days from now
-
 		entity := Entity{
 			TenantId:    tenantId,
 			CashId:      cashId,
 			TemplateId:  templateId,
 			Quantity:    quantity,
-			Flag:        0, // Default flag value
+			Flag:        flag,
 			PurchasedBy: purchasedBy,
 			Expiration:  expiration,
+			PurchasedAt: time.Now(),
 		}
 
 		err = db.Create(&entity).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return model.ErrorProvider[Entity](ErrCashIdTaken)
+		}
 		if err != nil {
 			return model.ErrorProvider[Entity](err)
 		}
@@ -49,3 +39,22 @@ func createEntityProvider(tenantId uuid.UUID, templateId uint32, quantity uint32
 		return model.FixedProvider[Entity](entity)
 	}
 }
+
+// flagPurchaserDeleted marks the items bought by a deleted character and returns how many were flagged
+func flagPurchaserDeleted(db *gorm.DB, tenantId uuid.UUID, purchasedBy uint32, deletedAt time.Time) (int64, error) {
+	result := db.Model(&Entity{}).
+		Where("tenant_id = ? AND purchased_by = ? AND purchaser_deleted_at IS NULL", tenantId, purchasedBy).
+		Update("purchaser_deleted_at", deletedAt)
+	return result.RowsAffected, result.Error
+}
+
+// markDiscarded records that a player discarded the item
+func markDiscarded(db *gorm.DB, tenantId uuid.UUID, id uint32, discardedAt time.Time) error {
+	return db.Model(&Entity{}).
+		Where("tenant_id = ? AND id = ?", tenantId, id).
+		Update("discarded_at", discardedAt).Error
+}
+
+func deleteEntity(db *gorm.DB, tenantId uuid.UUID, id uint32) error {
+	return db.Where(&Entity{TenantId: tenantId, Id: id}).Delete(&Entity{}).Error
+}
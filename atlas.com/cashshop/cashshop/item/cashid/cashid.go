@@ -0,0 +1,31 @@
+package cashid
+
+import (
+	"atlas-cashshop/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Sequence is the Postgres sequence cash ids are drawn from
+	Sequence = "cash_item_ids"
+	// CounterTable holds the last issued cash id on SQLite, which has no sequences
+	CounterTable = "cash_item_id_counter"
+)
+
+// Next issues a cash id which no other item has been given, by any replica or tenant
+// On Postgres the id is drawn from a sequence, which never blocks concurrent purchases and is not returned if the transaction rolls back
+// On SQLite a single counter row is incremented. SQLite serializes writers, so the counter cannot hand out an id twice
+func Next(db *gorm.DB) (int64, error) {
+	var result int64
+	var err error
+	if db.Dialector.Name() == database.DialectSQLite {
+		err = db.Raw("UPDATE " + CounterTable + " SET value = value + 1 WHERE id = 1 RETURNING value").Scan(&result).Error
+	} else {
+		err = db.Raw("SELECT nextval('" + Sequence + "')").Scan(&result).Error
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package cashid_test
+
+import (
+	"atlas-cashshop/cashshop/item/cashid"
+	"atlas-cashshop/database"
+	"atlas-cashshop/database/migrations"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+func TestNext(t *testing.T) {
+	db, err := database.Open(logrus.New(), database.SetDialector(database.SQLite("file:"+uuid.NewString()+"?mode=memory&cache=shared")), database.SetMigrations(migrations.All()...))
+	if err != nil {
+		t.Fatalf("Unable to open database: %v", err)
+	}
+
+	issued := make(map[int64]struct{})
+	last := int64(0)
+	for i := 0; i < 100; i++ {
+		id, err := cashid.Next(db)
+		if err != nil {
+			t.Fatalf("Unable to issue cash id: %v", err)
+		}
+		if _, ok := issued[id]; ok || id <= last {
+			t.Fatalf("Expected a new increasing cash id after [%d], got [%d]", last, id)
+		}
+		issued[id] = struct{}{}
+		last = id
+	}
+}
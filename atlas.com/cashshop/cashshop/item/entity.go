@@ -2,23 +2,23 @@ package item
 
 import (
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 	"time"
 )
 
-func Migration(db *gorm.DB) error {
-	return db.AutoMigrate(&Entity{})
-}
-
 type Entity struct {
 	Id          uint32    `gorm:"primaryKey;autoIncrement:true"`
-	TenantId    uuid.UUID `gorm:"not null"`
-	CashId      int64     `gorm:"not null"`
+	TenantId    uuid.UUID `gorm:"not null;uniqueIndex:idx_items_tenant_cash;index:idx_items_tenant_purchased_by"`
+	CashId      int64     `gorm:"not null;uniqueIndex:idx_items_tenant_cash"`
 	TemplateId  uint32    `gorm:"not null"`
 	Quantity    uint32    `gorm:"not null"`
 	Flag        uint16    `gorm:"not null"`
-	PurchasedBy uint32    `gorm:"not null"`
+	PurchasedBy uint32    `gorm:"not null;index:idx_items_tenant_purchased_by"`
 	Expiration  time.Time `gorm:"not null"`
+	PurchasedAt time.Time
+	// PurchaserDeletedAt is set when the purchasing character was deleted while the item remained with the account
+	PurchaserDeletedAt *time.Time
+	// DiscardedAt is set when a player discarded the item. The item is kept so it remains traceable
+	DiscardedAt *time.Time
 }
 
 func (e Entity) TableName() string {
@@ -27,12 +27,15 @@ func (e Entity) TableName() string {
 
 func Make(e Entity) (Model, error) {
 	return Model{
-		id:          e.Id,
-		cashId:      e.CashId,
-		templateId:  e.TemplateId,
-		quantity:    e.Quantity,
-		flag:        e.Flag,
-		purchasedBy: e.PurchasedBy,
-		expiration:  e.Expiration,
+		id:                 e.Id,
+		cashId:             e.CashId,
+		templateId:         e.TemplateId,
+		quantity:           e.Quantity,
+		flag:               e.Flag,
+		purchasedBy:        e.PurchasedBy,
+		expiration:         e.Expiration,
+		purchasedAt:        e.PurchasedAt,
+		purchaserDeletedAt: e.PurchaserDeletedAt,
+		discardedAt:        e.DiscardedAt,
 	}, nil
 }
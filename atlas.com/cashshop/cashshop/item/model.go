@@ -2,14 +2,20 @@ package item
 
 import "time"
 
+// FlagCannotDiscard marks an item the player may not discard. It is the lock bit of inventory item flags
+const FlagCannotDiscard = uint16(0x01)
+
 type Model struct {
-	id          uint32
-	cashId      int64
-	templateId  uint32
-	quantity    uint32
-	flag        uint16
-	purchasedBy uint32
-	expiration  time.Time
+	id                 uint32
+	cashId             int64
+	templateId         uint32
+	quantity           uint32
+	flag               uint16
+	purchasedBy        uint32
+	expiration         time.Time
+	purchasedAt        time.Time
+	purchaserDeletedAt *time.Time
+	discardedAt        *time.Time
 }
 
 func (m Model) Id() uint32 {
@@ -40,6 +46,36 @@ func (m Model) Expiration() time.Time {
 	return m.expiration
 }
 
+// PurchasedAt returns when the item was purchased or granted
+func (m Model) PurchasedAt() time.Time {
+	return m.purchasedAt
+}
+
+// PurchaserDeleted reports whether the character which bought the item has been deleted
+func (m Model) PurchaserDeleted() bool {
+	return m.purchaserDeletedAt != nil
+}
+
+// PurchaserDeletedAt returns when the purchasing character was deleted, or nil
+func (m Model) PurchaserDeletedAt() *time.Time {
+	return m.purchaserDeletedAt
+}
+
+// Discardable reports whether the player may discard the item
+func (m Model) Discardable() bool {
+	return m.flag&FlagCannotDiscard == 0
+}
+
+// Discarded reports whether a player discarded the item
+func (m Model) Discarded() bool {
+	return m.discardedAt != nil
+}
+
+// DiscardedAt returns when the item was discarded, or nil
+func (m Model) DiscardedAt() *time.Time {
+	return m.discardedAt
+}
+
 type Builder struct {
 	id          uint32
 	cashId      int64
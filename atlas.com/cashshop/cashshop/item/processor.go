@@ -1,23 +1,34 @@
 package item
 
 import (
+	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
 	"atlas-cashshop/kafka/message/item"
-	"atlas-cashshop/kafka/producer"
 	itemProducer "atlas-cashshop/kafka/producer/item"
+	"atlas-cashshop/outbox"
 	"context"
+	"errors"
 	"github.com/sirupsen/logrus"
+	"time"
 
 	"github.com/Chronicle20/atlas-model/model"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"gorm.io/gorm"
 )
 
+// ErrCashIdTaken is returned when the cash id issued for a new item is already held by another item of the tenant
+// Issued ids are unique, so this only happens when the sequence reaches an id drawn at random before it was introduced
+var ErrCashIdTaken = errors.New("cash id already taken")
+
 type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
 	ByIdProvider(itemId uint32) model.Provider[Model]
 	GetById(itemId uint32) (Model, error)
-	Create(mb *message.Buffer) func(templateId uint32) func(quantity uint32) func(purchasedBy uint32) (Model, error)
-	CreateAndEmit(templateId uint32, quantity uint32, purchasedBy uint32) (Model, error)
+	Create(mb *message.Buffer) func(templateId uint32) func(quantity uint32) func(flag uint16) func(purchasedBy uint32) func(expiration time.Time) (Model, error)
+	CreateAndEmit(templateId uint32, quantity uint32, flag uint16, purchasedBy uint32, expiration time.Time) (Model, error)
+	Delete(mb *message.Buffer) func(itemId uint32) error
+	FlagPurchaserDeleted(purchasedBy uint32) (int64, error)
+	Discard(itemId uint32) error
 }
 
 type ProcessorImpl struct {
@@ -25,7 +36,6 @@ type ProcessorImpl struct {
 	ctx context.Context
 	db  *gorm.DB
 	t   tenant.Model
-	p   producer.Provider
 }
 
 func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
@@ -34,51 +44,103 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 		ctx: ctx,
 		db:  db,
 		t:   tenant.MustFromContext(ctx),
-		p:   producer.ProviderImpl(l)(ctx),
 	}
 	return p
 }
 
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   p.l,
+		ctx: p.ctx,
+		db:  tx,
+		t:   p.t,
+	}
+}
+
 func (p *ProcessorImpl) ByIdProvider(id uint32) model.Provider[Model] {
-	return model.Map(Make)(byIdEntityProvider(p.t.Id(), id)(p.db))
+	return model.Map(Make)(byIdEntityProvider(p.t.Id(), id)(database.Read(p.ctx, p.db)))
 }
 
 func (p *ProcessorImpl) GetById(id uint32) (Model, error) {
 	return p.ByIdProvider(id)()
 }
 
-func (p *ProcessorImpl) Create(mb *message.Buffer) func(templateId uint32) func(quantity uint32) func(purchasedBy uint32) (Model, error) {
-	return func(templateId uint32) func(quantity uint32) func(purchasedBy uint32) (Model, error) {
-		return func(quantity uint32) func(purchasedBy uint32) (Model, error) {
-			return func(purchasedBy uint32) (Model, error) {
-				entity, err := createEntityProvider(p.t.Id(), templateId, quantity, purchasedBy)(p.db)()
-				if err != nil {
-					return Model{}, err
-				}
+// DefaultExpirationDays is how many days purchased cash items last
+const DefaultExpirationDays = 30
 
-				m, err := Make(entity)
-				if err != nil {
-					return Model{}, err
-				}
+// DefaultExpiration is the expiration given to purchased cash items, DefaultExpirationDays from now
+func DefaultExpiration() time.Time {
+	return time.Now().AddDate(0, 0, DefaultExpirationDays)
+}
 
-				err = mb.Put(item.EnvStatusTopic, itemProducer.CreateStatusEventProvider(
-					m.Id(),
-					m.CashId(),
-					m.TemplateId(),
-					m.Quantity(),
-					m.PurchasedBy(),
-					m.Flag(),
-				))
-				if err != nil {
-					return Model{}, err
-				}
+func (p *ProcessorImpl) Create(mb *message.Buffer) func(templateId uint32) func(quantity uint32) func(flag uint16) func(purchasedBy uint32) func(expiration time.Time) (Model, error) {
+	return func(templateId uint32) func(quantity uint32) func(flag uint16) func(purchasedBy uint32) func(expiration time.Time) (Model, error) {
+		return func(quantity uint32) func(flag uint16) func(purchasedBy uint32) func(expiration time.Time) (Model, error) {
+			return func(flag uint16) func(purchasedBy uint32) func(expiration time.Time) (Model, error) {
+				return func(purchasedBy uint32) func(expiration time.Time) (Model, error) {
+					return func(expiration time.Time) (Model, error) {
+						entity, err := createEntityProvider(p.t.Id(), templateId, quantity, flag, purchasedBy, expiration)(p.db)()
+						if err != nil {
+							return Model{}, err
+						}
+
+						m, err := Make(entity)
+						if err != nil {
+							return Model{}, err
+						}
 
-				return m, nil
+						err = mb.Put(item.EnvStatusTopic, itemProducer.CreateStatusEventProvider(
+							m.Id(),
+							m.CashId(),
+							m.TemplateId(),
+							m.Quantity(),
+							m.PurchasedBy(),
+							m.Flag(),
+						))
+						if err != nil {
+							return Model{}, err
+						}
+
+						return m, nil
+					}
+				}
 			}
 		}
 	}
 }
 
-func (p *ProcessorImpl) CreateAndEmit(templateId uint32, quantity uint32, purchasedBy uint32) (Model, error) {
-	return message.EmitWithResult[Model, uint32](p.p)(model.Flip(model.Flip(p.Create)(templateId))(quantity))(purchasedBy)
+func (p *ProcessorImpl) CreateAndEmit(templateId uint32, quantity uint32, flag uint16, purchasedBy uint32, expiration time.Time) (Model, error) {
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.WithTransaction(tx).Create(buf)(templateId)(quantity)(flag)(purchasedBy)(expiration)
+	})
+}
+
+func (p *ProcessorImpl) Delete(mb *message.Buffer) func(itemId uint32) error {
+	return func(itemId uint32) error {
+		m, err := p.GetById(itemId)
+		if err != nil {
+			return err
+		}
+
+		p.l.Debugf("Deleting cash item [%d].", itemId)
+		err = deleteEntity(p.db, p.t.Id(), itemId)
+		if err != nil {
+			return err
+		}
+
+		return mb.Put(item.EnvStatusTopic, itemProducer.DeleteStatusEventProvider(m.Id(), m.CashId(), m.TemplateId(), m.PurchasedBy()))
+	}
+}
+
+// FlagPurchaserDeleted marks the items bought by a deleted character so they remain traceable
+// The items stay usable by the account which holds them
+func (p *ProcessorImpl) FlagPurchaserDeleted(purchasedBy uint32) (int64, error) {
+	p.l.Debugf("Flagging cash items purchased by deleted character [%d].", purchasedBy)
+	return flagPurchaserDeleted(p.db, p.t.Id(), purchasedBy, time.Now())
+}
+
+// Discard marks the item as discarded by a player. The item is kept rather than deleted so it remains traceable
+func (p *ProcessorImpl) Discard(itemId uint32) error {
+	p.l.Debugf("Marking cash item [%d] discarded.", itemId)
+	return markDiscarded(p.db, p.t.Id(), itemId, time.Now())
 }
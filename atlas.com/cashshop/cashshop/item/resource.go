@@ -2,7 +2,6 @@ package item
 
 import (
 	"atlas-cashshop/rest"
-	"errors"
 	"net/http"
 	"strconv"
 
@@ -17,6 +16,8 @@ import (
 func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
 	return func(db *gorm.DB) server.RouteInitializer {
 		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(ErrCashIdTaken, http.StatusConflict, "CASH_ID_TAKEN", "Cash id already taken")
+
 			registerGet := rest.RegisterHandler(l)(si)
 			registerInput := rest.RegisterInputHandler[RestModel](l)(si)
 			r := router.PathPrefix("/cash-shop/items").Subrouter()
@@ -31,19 +32,15 @@ func handleGetItem(db *gorm.DB) rest.GetHandler {
 		return ParseItemId(d.Logger(), func(itemId uint32) http.HandlerFunc {
 			return func(w http.ResponseWriter, r *http.Request) {
 				ms, err := NewProcessor(d.Logger(), d.Context(), db).GetById(itemId)
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					w.WriteHeader(http.StatusNotFound)
-					return
-				}
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
 				res, err := model.Map(Transform)(model.FixedProvider(ms))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -61,21 +58,30 @@ func handleCreateItem(db *gorm.DB) rest.InputHandler[RestModel] {
 			im, err := Extract(i)
 			if err != nil {
 				d.Logger().WithError(err).Errorf("Extracting model.")
-				w.WriteHeader(http.StatusBadRequest)
+				rest.WriteError(d.Logger())(w)(rest.InvalidBody(err))
+				return
+			}
+
+			if im.TemplateId() == 0 {
+				rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("templateId", "an item needs a template"))
+				return
+			}
+			if im.Quantity() == 0 {
+				rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("quantity", "an item needs a quantity of at least 1"))
 				return
 			}
 
-			m, err := NewProcessor(d.Logger(), d.Context(), db).CreateAndEmit(im.TemplateId(), im.Quantity(), im.PurchasedBy())
+			m, err := NewProcessor(d.Logger(), d.Context(), db).CreateAndEmit(im.TemplateId(), im.Quantity(), 0, im.PurchasedBy(), DefaultExpiration())
 			if err != nil {
 				d.Logger().WithError(err).Errorf("Creating item.")
-				w.WriteHeader(http.StatusInternalServerError)
+				rest.WriteError(d.Logger())(w)(err)
 				return
 			}
 
 			restModel, err := Transform(m)
 			if err != nil {
 				d.Logger().WithError(err).Errorf("Creating REST model.")
-				w.WriteHeader(http.StatusInternalServerError)
+				rest.WriteError(d.Logger())(w)(err)
 				return
 			}
 
@@ -94,7 +100,7 @@ func ParseItemId(l logrus.FieldLogger, next ItemIdHandler) http.HandlerFunc {
 		itemId, err := strconv.Atoi(mux.Vars(r)["itemId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse itemId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			rest.WriteError(l)(w)(rest.InvalidParameter("itemId").WithDetail(err.Error()))
 			return
 		}
 		next(uint32(itemId))(w, r)
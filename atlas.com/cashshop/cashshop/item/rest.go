@@ -1,14 +1,23 @@
 package item
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+)
 
 type RestModel struct {
-	Id          uint32 `json:"-"`
-	CashId      int64  `json:"cashId,string"`
-	TemplateId  uint32 `json:"templateId"`
-	Quantity    uint32 `json:"quantity"`
-	Flag        uint16 `json:"flag"`
-	PurchasedBy uint32 `json:"purchasedBy"`
+	Id                 uint32     `json:"-"`
+	CashId             int64      `json:"cashId,string"`
+	TemplateId         uint32     `json:"templateId"`
+	Quantity           uint32     `json:"quantity"`
+	Flag               uint16     `json:"flag"`
+	PurchasedBy        uint32     `json:"purchasedBy"`
+	PurchasedAt        time.Time  `json:"purchasedAt"`
+	Expiration         time.Time  `json:"expiration"`
+	PurchaserDeleted   bool       `json:"purchaserDeleted"`
+	PurchaserDeletedAt *time.Time `json:"purchaserDeletedAt,omitempty"`
+	Discarded          bool       `json:"discarded"`
+	DiscardedAt        *time.Time `json:"discardedAt,omitempty"`
 }
 
 func (r RestModel) GetName() string {
@@ -30,12 +39,18 @@ func (r *RestModel) SetID(strId string) error {
 
 func Transform(m Model) (RestModel, error) {
 	return RestModel{
-		Id:          m.id,
-		CashId:      m.cashId,
-		TemplateId:  m.templateId,
-		Quantity:    m.quantity,
-		Flag:        m.flag,
-		PurchasedBy: m.purchasedBy,
+		Id:                 m.id,
+		CashId:             m.cashId,
+		TemplateId:         m.templateId,
+		Quantity:           m.quantity,
+		Flag:               m.flag,
+		PurchasedBy:        m.purchasedBy,
+		PurchasedAt:        m.purchasedAt,
+		Expiration:         m.expiration,
+		PurchaserDeleted:   m.PurchaserDeleted(),
+		PurchaserDeletedAt: m.purchaserDeletedAt,
+		Discarded:          m.Discarded(),
+		DiscardedAt:        m.discardedAt,
 	}, nil
 }
 
@@ -0,0 +1,53 @@
+package cashshop
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/wallet"
+	"github.com/google/uuid"
+)
+
+// Receipt represents a completed purchase, holding the asset created for it and the wallet it was paid from
+type Receipt struct {
+	requestId    uuid.UUID
+	characterId  uint32
+	currency     uint32
+	serialNumber uint32
+	price        uint32
+	asset        asset.Model
+	wallet       wallet.Model
+}
+
+// RequestId returns the request the purchase was made for
+func (m Receipt) RequestId() uuid.UUID {
+	return m.requestId
+}
+
+// CharacterId returns the character who made the purchase
+func (m Receipt) CharacterId() uint32 {
+	return m.characterId
+}
+
+// Currency returns the currency the purchase was paid with
+func (m Receipt) Currency() uint32 {
+	return m.currency
+}
+
+// SerialNumber returns the commodity which was purchased
+func (m Receipt) SerialNumber() uint32 {
+	return m.serialNumber
+}
+
+// Price returns the amount of currency the purchase cost
+func (m Receipt) Price() uint32 {
+	return m.price
+}
+
+// Asset returns the asset created for the purchase, along with its item
+func (m Receipt) Asset() asset.Model {
+	return m.asset
+}
+
+// Wallet returns the wallet after the purchase was paid
+func (m Receipt) Wallet() wallet.Model {
+	return m.wallet
+}
@@ -3,36 +3,36 @@ package cashshop
 import (
 	"atlas-cashshop/cashshop/commodity"
 	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
 	"atlas-cashshop/cashshop/inventory/compartment"
 	"atlas-cashshop/cashshop/item"
 	"atlas-cashshop/character"
 	compartment2 "atlas-cashshop/character/compartment"
 	inventory2 "atlas-cashshop/character/inventory"
+	"atlas-cashshop/correlation"
 	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
 	"atlas-cashshop/kafka/message/cashshop"
-	"atlas-cashshop/kafka/producer"
 	cashshop2 "atlas-cashshop/kafka/producer/cashshop"
+	"atlas-cashshop/outbox"
 	"atlas-cashshop/wallet"
 	"context"
-	"errors"
 	"github.com/Chronicle20/atlas-constants/inventory"
-	"github.com/Chronicle20/atlas-constants/job"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-var ErrInsufficientFunds = errors.New("insufficient funds")
-var ErrMaxSlots = errors.New("max slots")
-var ErrAssetAlreadyReserved = errors.New("asset already reserved")
+var ErrAssetAlreadyReserved = reservation.ErrAlreadyReserved
 
 type Processor interface {
-	PurchaseAndEmit(characterId uint32, currency uint32, serialNumber uint32) error
-	Purchase(mb *message.Buffer) func(characterId uint32, currency uint32, serialNumber uint32) error
+	PurchaseAndEmit(characterId uint32, currency uint32, serialNumber uint32) (Receipt, error)
+	Purchase(mb *message.Buffer) func(characterId uint32, currency uint32, serialNumber uint32) (Receipt, error)
 	PurchaseInventoryIncreaseByItemAndEmit(characterId uint32, currency uint32, serialNumber uint32) error
 	PurchaseInventoryIncreaseByTypeAndEmit(characterId uint32, currency uint32, inventoryType inventory.Type) error
 	PurchaseInventoryIncrease(mb *message.Buffer) func(characterId uint32, currency uint32, inventoryType inventory.Type, cost uint32, amount uint32) error
+	Fail(mb *message.Buffer) func(characterId uint32, err error) error
+	FailAndEmit(characterId uint32, err error) error
 }
 
 type ProcessorImpl struct {
@@ -40,7 +40,6 @@ type ProcessorImpl struct {
 	ctx     context.Context
 	db      *gorm.DB
 	t       tenant.Model
-	p       producer.Provider
 	chaP    character.Processor
 	comP    commodity.Processor
 	cicP    compartment.Processor
@@ -57,7 +56,6 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 		ctx:     ctx,
 		db:      db,
 		t:       tenant.MustFromContext(ctx),
-		p:       producer.ProviderImpl(l)(ctx),
 		chaP:    character.NewProcessor(l, ctx),
 		comP:    commodity.NewProcessor(l, ctx),
 		cicP:    compartment.NewProcessor(l, ctx, db),
@@ -70,56 +68,63 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 	return p
 }
 
-func (p *ProcessorImpl) PurchaseAndEmit(characterId uint32, currency uint32, serialNumber uint32) error {
-	return message.Emit(p.p)(func(buf *message.Buffer) error {
-		return p.Purchase(buf)(characterId, currency, serialNumber)
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
+	return &ProcessorImpl{
+		l:       p.l,
+		ctx:     p.ctx,
+		db:      tx,
+		t:       p.t,
+		chaP:    p.chaP,
+		comP:    p.comP,
+		cicP:    p.cicP.WithTransaction(tx),
+		chaInvP: p.chaInvP,
+		chaComP: p.chaComP,
+		walP:    p.walP.WithTransaction(tx),
+		itmP:    p.itmP.WithTransaction(tx),
+		astP:    p.astP.WithTransaction(tx),
+	}
+}
+
+func (p *ProcessorImpl) PurchaseAndEmit(characterId uint32, currency uint32, serialNumber uint32) (Receipt, error) {
+	return outbox.EmitWithResult[Receipt](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Receipt, error) {
+		return p.withTransaction(tx).Purchase(buf)(characterId, currency, serialNumber)
 	})
 }
 
-func (p *ProcessorImpl) Purchase(mb *message.Buffer) func(characterId uint32, currency uint32, serialNumber uint32) error {
-	return func(characterId uint32, currency uint32, serialNumber uint32) error {
+// Purchase pays for the commodity from the wallet of the character's account and places it in their cash inventory
+func (p *ProcessorImpl) Purchase(mb *message.Buffer) func(characterId uint32, currency uint32, serialNumber uint32) (Receipt, error) {
+	return func(characterId uint32, currency uint32, serialNumber uint32) (Receipt, error) {
+		var result Receipt
 		txErr := database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
 
 			ci, err := p.comP.GetById(serialNumber)
 			if err != nil {
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 				return err
 			}
 			p.l.Debugf("Character [%d] attempting to purchase [%d] using currency [%d]. Cost is [%d].", characterId, serialNumber, currency, ci.Price())
 			c, err := p.chaP.GetById(p.chaP.InventoryDecorator)(characterId)
 			if err != nil {
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 				return err
 			}
 			w, err := p.walP.GetByAccountId(c.AccountId())
 			if err != nil {
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 				return err
 			}
 			balance := w.Balance(currency)
 			if balance < ci.Price() {
 				p.l.Debugf("Character [%d] has insufficient balance for purchase. Cost [%d]. Balance [%d].", characterId, ci.Price(), balance)
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "NOT_ENOUGH_CASH"))
 				return ErrInsufficientFunds
 			}
 
-			var compartmentType compartment.CompartmentType
-			if job.GetType(job.Id(c.JobId())) == job.TypeExplorer {
-				compartmentType = compartment.TypeExplorer
-			} else if job.GetType(job.Id(c.JobId())) == job.TypeCygnus {
-				compartmentType = compartment.TypeCygnus
-			} else {
-				compartmentType = compartment.TypeLegend
-			}
+			compartmentType := compartment.TypeFromJobId(c.JobId())
 
 			ccm, err := p.cicP.GetByAccountIdAndType(c.AccountId(), compartmentType)
 			if err != nil {
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 				return err
 			}
 			if ccm.Capacity() <= uint32(len(ccm.Assets())) {
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "INVENTORY_FULL"))
-				return nil
+				p.l.Debugf("Cash inventory [%s] of character [%d] is full. Capacity [%d].", ccm.Id(), characterId, ccm.Capacity())
+				return ErrInventoryFull
 			}
 
 			w = w.Purchase(currency, ci.Price())
@@ -129,10 +134,9 @@ func (p *ProcessorImpl) Purchase(mb *message.Buffer) func(characterId uint32, cu
 			}
 
 			// Create the cash item
-			im, err := p.itmP.Create(mb)(ci.ItemId())(ci.Count())(characterId)
+			im, err := p.itmP.Create(mb)(ci.ItemId())(ci.Count())(0)(characterId)(item.DefaultExpiration())
 			if err != nil {
 				p.l.WithError(err).Errorf("Unable to create cash item for character [%d].", characterId)
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 				return err
 			}
 
@@ -140,20 +144,28 @@ func (p *ProcessorImpl) Purchase(mb *message.Buffer) func(characterId uint32, cu
 			am, err := p.astP.Create(mb)(ccm.Id())(im.Id())
 			if err != nil {
 				p.l.WithError(err).Errorf("Unable to create asset for character [%d].", characterId)
-				_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 				return err
 			}
 
 			p.l.Debugf("Character [%d] successfully purchased item [%d] for [%d] currency.", characterId, ci.ItemId(), ci.Price())
-			_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.PurchaseStatusEventProvider(characterId, ci.ItemId(), ci.Price(), ccm.Id(), am.Id(), im.Id()))
+			_ = mb.Put(cashshop.EnvEventTopicStatus, cashshop2.PurchaseStatusEventProvider(correlation.RequestId(p.ctx), characterId, ci.ItemId(), ci.Price(), currency, ccm.Id(), am.Id(), im.Id()))
 
+			result = Receipt{
+				requestId:    correlation.RequestId(p.ctx),
+				characterId:  characterId,
+				currency:     currency,
+				serialNumber: serialNumber,
+				price:        ci.Price(),
+				asset:        am,
+				wallet:       w,
+			}
 			return nil
 		})
 		if txErr != nil {
 			p.l.WithError(txErr).Errorf("Unable to complete purchase for character [%d].", characterId)
-			return txErr
+			return Receipt{}, txErr
 		}
-		return nil
+		return result, nil
 	}
 }
 
@@ -163,14 +175,16 @@ func (p *ProcessorImpl) PurchaseInventoryIncreaseByItemAndEmit(characterId uint3
 		return err
 	}
 	inventoryType := inventory.Type(ci.ItemId() - 9110000/1000)
-	return message.Emit(producer.ProviderImpl(p.l)(p.ctx))(func(buf *message.Buffer) error {
-		return p.PurchaseInventoryIncrease(buf)(characterId, currency, inventoryType, ci.Price(), 4)
-	})
+	return p.purchaseInventoryIncreaseAndEmit(characterId, currency, inventoryType, ci.Price(), 4)
 }
 
 func (p *ProcessorImpl) PurchaseInventoryIncreaseByTypeAndEmit(characterId uint32, currency uint32, inventoryType inventory.Type) error {
-	return message.Emit(producer.ProviderImpl(p.l)(p.ctx))(func(buf *message.Buffer) error {
-		return p.PurchaseInventoryIncrease(buf)(characterId, currency, inventoryType, 4000, 8)
+	return p.purchaseInventoryIncreaseAndEmit(characterId, currency, inventoryType, 4000, 8)
+}
+
+func (p *ProcessorImpl) purchaseInventoryIncreaseAndEmit(characterId uint32, currency uint32, inventoryType inventory.Type, cost uint32, amount uint32) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).PurchaseInventoryIncrease(buf)(characterId, currency, inventoryType, cost, amount)
 	})
 }
 
@@ -214,12 +228,27 @@ func (p *ProcessorImpl) PurchaseInventoryIncrease(mb *message.Buffer) func(chara
 			return nil
 		})
 		if txErr != nil {
-			_ = producer.ProviderImpl(p.l)(p.ctx)(cashshop.EnvEventTopicStatus)(cashshop2.ErrorStatusEventProvider(characterId, "UNKNOWN_ERROR"))
 			return txErr
 		}
 
 		p.l.Debugf("Character [%d] purchased inventory [%d] increase. New capacity will be [%d].", characterId, inventoryType, newCapacity)
-		_ = producer.ProviderImpl(p.l)(p.ctx)(cashshop.EnvEventTopicStatus)(cashshop2.InventoryCapacityIncreasedStatusEventProvider(characterId, byte(inventoryType), newCapacity, amount))
-		return nil
+		return mb.Put(cashshop.EnvEventTopicStatus, cashshop2.InventoryCapacityIncreasedStatusEventProvider(correlation.RequestId(p.ctx), characterId, byte(inventoryType), newCapacity, amount))
+	}
+}
+
+// Fail reports a request of the character which failed, translating the error into the code shown to the client
+// A failed request rolls back along with its buffered events, so the failure is reported on its own once the request is given up on
+func (p *ProcessorImpl) Fail(mb *message.Buffer) func(characterId uint32, err error) error {
+	return func(characterId uint32, err error) error {
+		code := ErrorCode(err)
+		p.l.WithError(err).Debugf("Reporting failed cash shop request of character [%d] as [%s].", characterId, code)
+		return mb.Put(cashshop.EnvEventTopicStatus, cashshop2.ErrorStatusEventProvider(correlation.RequestId(p.ctx), characterId, code))
 	}
 }
+
+// FailAndEmit reports a request of the character which failed and emits the error event
+func (p *ProcessorImpl) FailAndEmit(characterId uint32, err error) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(_ *gorm.DB, buf *message.Buffer) error {
+		return p.Fail(buf)(characterId, err)
+	})
+}
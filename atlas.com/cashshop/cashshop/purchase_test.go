@@ -0,0 +1,289 @@
+package cashshop_test
+
+import (
+	"atlas-cashshop/account"
+	cashshop3 "atlas-cashshop/cashshop"
+	"atlas-cashshop/cashshop/commodity"
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/character"
+	"atlas-cashshop/correlation"
+	"atlas-cashshop/database/fixture"
+	consumer "atlas-cashshop/kafka/consumer"
+	cashshopConsumer "atlas-cashshop/kafka/consumer/cashshop"
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/kafka/producer"
+	"atlas-cashshop/kafka/schema"
+	"atlas-cashshop/outbox"
+	"atlas-cashshop/rest/stub"
+	"atlas-cashshop/wallet"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	producer2 "github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/google/uuid"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	testAccountId   = fixture.AccountId
+	testCharacterId = uint32(2000)
+	testSerial      = uint32(10000001)
+	testTemplateId  = uint32(5000000)
+	testPrice       = uint32(2500)
+	creditCurrency  = uint32(1)
+)
+
+// purchaseSuite runs the cash shop command consumer against a real database, with Kafka and the remote services stood in for
+type purchaseSuite struct {
+	t          *testing.T
+	l          logrus.FieldLogger
+	ctx        context.Context
+	db         *gorm.DB
+	dispatcher *consumer.Dispatcher
+	memory     *producer.Memory
+	relay      *outbox.RelayTask
+}
+
+func newPurchaseSuite(t *testing.T) *purchaseSuite {
+	db := fixture.Open(t)
+
+	t.Setenv(cashshop.EnvCommandTopic, "cash-shop-command")
+	t.Setenv(cashshop.EnvEventTopicStatus, "cash-shop-status")
+	t.Setenv(consumer.EnvRetryAttempts, "1")
+
+	characters := stub.NewServer()
+	t.Cleanup(characters.Close)
+	data := stub.NewServer()
+	t.Cleanup(data.Close)
+	t.Setenv("CHARACTERS", characters.BaseUrl())
+	t.Setenv("INVENTORY", characters.BaseUrl())
+	t.Setenv("DATA", data.BaseUrl())
+
+	if err := characters.Put(fmt.Sprintf(character.ById, testCharacterId), character.RestModel{Id: testCharacterId, AccountId: testAccountId, JobId: 100}); err != nil {
+		t.Fatalf("Unable to stub character: %v", err)
+	}
+	if err := data.Put(fmt.Sprintf(commodity.ById, testSerial), commodity.RestModel{Id: testSerial, ItemId: testTemplateId, Count: 1, Price: testPrice, OnSale: true}); err != nil {
+		t.Fatalf("Unable to stub commodity: %v", err)
+	}
+
+	// Each suite runs as its own tenant so it does not see the rows of another
+	l := logrus.New()
+	ctx := fixture.Tenant(t)
+
+	s := &purchaseSuite{
+		t:          t,
+		l:          l,
+		ctx:        ctx,
+		db:         db,
+		dispatcher: consumer.NewDispatcher(l),
+		memory:     producer.NewMemory(),
+	}
+	s.relay = outbox.NewRelayTaskWithProvider(l, db, time.Second, s.memory.HeaderProvider)
+	cashshopConsumer.InitHandlers(l)(db)(s.dispatcher.Register)
+
+	if _, err := account.NewProcessor(l, ctx, db).ProvisionAndEmit(testAccountId); err != nil {
+		t.Fatalf("Unable to provision account: %v", err)
+	}
+	return s
+}
+
+func (s *purchaseSuite) fund(credit uint32) {
+	if _, err := wallet.NewProcessor(s.l, s.ctx, s.db).UpdateAndEmit(testAccountId, credit, 0, 0); err != nil {
+		s.t.Fatalf("Unable to fund wallet: %v", err)
+	}
+}
+
+// purchase sends a REQUEST_PURCHASE command and returns the cash shop status events it caused
+func (s *purchaseSuite) purchase(requestId uuid.UUID, serialNumber uint32) []kafka.Message {
+	s.relay.Run()
+	s.memory.Drain(cashshop.EnvEventTopicStatus)
+
+	c := cashshop.Command[cashshop.RequestPurchaseCommandBody]{
+		CharacterId: testCharacterId,
+		RequestId:   requestId,
+		Type:        cashshop.CommandTypeRequestPurchase,
+		Body: cashshop.RequestPurchaseCommandBody{
+			Currency:     creditCurrency,
+			SerialNumber: serialNumber,
+		},
+	}
+	if err := s.dispatcher.Provider(s.ctx)(cashshop.EnvCommandTopic)(producer2.SingleMessageProvider(producer2.CreateKey(int(testCharacterId)), c)); err != nil {
+		s.t.Fatalf("Unable to dispatch purchase command: %v", err)
+	}
+	s.relay.Run()
+	return s.memory.Drain(cashshop.EnvEventTopicStatus)
+}
+
+func decodeStatusEvent[E any](t *testing.T, msg kafka.Message) cashshop.StatusEvent[E] {
+	var e cashshop.StatusEvent[E]
+	if err := json.Unmarshal(msg.Value, &e); err != nil {
+		t.Fatalf("Unable to decode status event: %v", err)
+	}
+	return e
+}
+
+func TestPurchase(t *testing.T) {
+	s := newPurchaseSuite(t)
+	s.fund(10000)
+
+	requestId := uuid.New()
+	ms := s.purchase(requestId, testSerial)
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 cash shop status event, got [%d]", len(ms))
+	}
+	if v := schema.VersionOf(ms[0].Headers); v != cashshop.StatusEventVersion {
+		t.Errorf("Expected schema version [%d], got [%d]", cashshop.StatusEventVersion, v)
+	}
+
+	e := decodeStatusEvent[cashshop.PurchaseEventBody](t, ms[0])
+	if e.Type != cashshop.StatusEventTypePurchase {
+		t.Fatalf("Expected [%s] event, got [%s]", cashshop.StatusEventTypePurchase, e.Type)
+	}
+	if e.RequestId != requestId {
+		t.Errorf("Expected request [%s] to be echoed, got [%s]", requestId, e.RequestId)
+	}
+	if e.CharacterId != testCharacterId || e.Body.TemplateId != testTemplateId || e.Body.Price != testPrice || e.Body.Currency != creditCurrency {
+		t.Errorf("Unexpected purchase event %+v", e)
+	}
+
+	w, err := wallet.NewProcessor(s.l, s.ctx, s.db).GetByAccountId(testAccountId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve wallet: %v", err)
+	}
+	if w.Credit() != 10000-testPrice {
+		t.Errorf("Expected credit [%d] after purchase, got [%d]", 10000-testPrice, w.Credit())
+	}
+
+	a, err := asset.NewProcessor(s.l, s.ctx, s.db).GetById(e.Body.AssetId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve purchased asset: %v", err)
+	}
+	if a.CompartmentId() != e.Body.CompartmentId || a.TemplateId() != testTemplateId {
+		t.Errorf("Expected asset of template [%d] in compartment [%s], got template [%d] in [%s]", testTemplateId, e.Body.CompartmentId, a.TemplateId(), a.CompartmentId())
+	}
+}
+
+func TestPurchaseInsufficientFunds(t *testing.T) {
+	s := newPurchaseSuite(t)
+	s.fund(testPrice - 1)
+
+	requestId := uuid.New()
+	ms := s.purchase(requestId, testSerial)
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 cash shop status event, got [%d]", len(ms))
+	}
+	e := decodeStatusEvent[cashshop.ErrorEventBody](t, ms[0])
+	if e.Type != cashshop.StatusEventTypeError || e.Body.Error != cashshop.ErrorCodeNotEnoughCash {
+		t.Fatalf("Expected [%s] error event, got [%s] [%s]", cashshop.ErrorCodeNotEnoughCash, e.Type, e.Body.Error)
+	}
+	if e.RequestId != requestId {
+		t.Errorf("Expected request [%s] to be echoed, got [%s]", requestId, e.RequestId)
+	}
+
+	w, err := wallet.NewProcessor(s.l, s.ctx, s.db).GetByAccountId(testAccountId)
+	if err != nil {
+		t.Fatalf("Unable to retrieve wallet: %v", err)
+	}
+	if w.Credit() != testPrice-1 {
+		t.Errorf("Expected credit to be untouched, got [%d]", w.Credit())
+	}
+}
+
+func TestPurchaseReceipt(t *testing.T) {
+	s := newPurchaseSuite(t)
+	s.fund(10000)
+	s.relay.Run()
+	s.memory.Drain(cashshop.EnvEventTopicStatus)
+
+	requestId := uuid.New()
+	ctx := correlation.WithRequestId(s.ctx, requestId)
+	m, err := cashshop3.NewProcessor(s.l, ctx, s.db).PurchaseAndEmit(testCharacterId, creditCurrency, testSerial)
+	if err != nil {
+		t.Fatalf("Unable to purchase: %v", err)
+	}
+	if m.RequestId() != requestId || m.Price() != testPrice || m.Asset().TemplateId() != testTemplateId {
+		t.Errorf("Unexpected receipt for request [%s] price [%d] template [%d]", m.RequestId(), m.Price(), m.Asset().TemplateId())
+	}
+	if m.Wallet().Credit() != 10000-testPrice {
+		t.Errorf("Expected receipt to hold credit [%d], got [%d]", 10000-testPrice, m.Wallet().Credit())
+	}
+
+	s.relay.Run()
+	ms := s.memory.Drain(cashshop.EnvEventTopicStatus)
+	if len(ms) != 1 || decodeStatusEvent[cashshop.PurchaseEventBody](t, ms[0]).Body.AssetId != m.Asset().Id() {
+		t.Fatalf("Expected a PURCHASE event for asset [%s]", m.Asset().Id())
+	}
+
+	rm, err := cashshop3.Transform(m)
+	if err != nil {
+		t.Fatalf("Unable to transform receipt: %v", err)
+	}
+	b, err := jsonapi.Marshal(rm)
+	if err != nil {
+		t.Fatalf("Unable to marshal receipt: %v", err)
+	}
+	var doc jsonapi.Document
+	if err = json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("Unable to decode receipt document: %v", err)
+	}
+	included := make(map[string]int)
+	for _, d := range doc.Included {
+		included[d.Type]++
+	}
+	if included["assets"] != 1 || included["items"] != 1 || included["wallets"] != 1 {
+		t.Errorf("Expected the asset, item and wallet to be included, got %v", included)
+	}
+}
+
+func TestPurchaseUnknownCommodity(t *testing.T) {
+	s := newPurchaseSuite(t)
+	s.fund(testPrice)
+
+	if _, err := cashshop3.NewProcessor(s.l, s.ctx, s.db).PurchaseAndEmit(testCharacterId, creditCurrency, testSerial+1); !errors.Is(err, commodity.ErrNotFound) {
+		t.Errorf("Expected a purchase of a commodity missing from DATA to fail with [%v], got [%v]", commodity.ErrNotFound, err)
+	}
+
+	ms := s.purchase(uuid.New(), testSerial+1)
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 cash shop status event, got [%d]", len(ms))
+	}
+	if e := decodeStatusEvent[cashshop.ErrorEventBody](t, ms[0]); e.Type != cashshop.StatusEventTypeError || e.Body.Error != cashshop.ErrorCodeCommodityNotFound {
+		t.Errorf("Expected a [%s] error event, got %+v", cashshop.ErrorCodeCommodityNotFound, e)
+	}
+}
+
+func TestPurchaseVersion1Command(t *testing.T) {
+	s := newPurchaseSuite(t)
+	s.fund(10000)
+	s.relay.Run()
+	s.memory.Drain(cashshop.EnvEventTopicStatus)
+
+	// Producers still on version 1 carry the requestId in the body
+	requestId := uuid.New()
+	value := fmt.Sprintf(`{"characterId":%d,"type":"%s","body":{"requestId":"%s","currency":%d,"serialNumber":%d}}`, testCharacterId, cashshop.CommandTypeRequestPurchase, requestId, creditCurrency, testSerial)
+	headers, err := producer.Headers(s.ctx)
+	if err != nil {
+		t.Fatalf("Unable to build headers: %v", err)
+	}
+	headers[schema.HeaderVersion] = "1"
+	msg := kafka.Message{Value: []byte(value), Headers: producer.KafkaHeaders(headers)}
+	if err = s.dispatcher.Forward(s.ctx, cashshop.EnvCommandTopic, []kafka.Message{msg}); err != nil {
+		t.Fatalf("Unable to dispatch purchase command: %v", err)
+	}
+	s.relay.Run()
+
+	ms := s.memory.Drain(cashshop.EnvEventTopicStatus)
+	if len(ms) != 1 {
+		t.Fatalf("Expected 1 cash shop status event, got [%d]", len(ms))
+	}
+	if e := decodeStatusEvent[cashshop.PurchaseEventBody](t, ms[0]); e.Type != cashshop.StatusEventTypePurchase || e.RequestId != requestId {
+		t.Errorf("Expected a purchase echoing request [%s], got %+v", requestId, e)
+	}
+}
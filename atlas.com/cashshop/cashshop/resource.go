@@ -0,0 +1,73 @@
+package cashshop
+
+import (
+	"atlas-cashshop/cashshop/commodity"
+	"atlas-cashshop/correlation"
+	"atlas-cashshop/rest"
+	"atlas-cashshop/wallet"
+	"errors"
+	"github.com/Chronicle20/atlas-rest/server"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// InitResource initializes the cash shop purchase resource
+func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
+	return func(db *gorm.DB) server.RouteInitializer {
+		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(ErrInsufficientFunds, http.StatusUnprocessableEntity, "INSUFFICIENT_FUNDS", "Insufficient funds")
+			rest.RegisterError(ErrInventoryFull, http.StatusConflict, "INVENTORY_FULL", "Cash inventory full")
+
+			r := router.PathPrefix("/characters/{characterId}/cash-shop/purchases").Subrouter()
+			r.HandleFunc("", rest.RegisterInputHandler[RestModel](l)(si)("purchase", handlePurchase(db))).Methods(http.MethodPost)
+		}
+	}
+}
+
+// handlePurchase handles the POST request which purchases a commodity for a character
+// The request id is taken from the id of the purchase when the caller supplies one, so it can be matched to the PURCHASE event
+func handlePurchase(db *gorm.DB) rest.InputHandler[RestModel] {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext, input RestModel) http.HandlerFunc {
+		return rest.ParseCharacterId(d.Logger(), func(characterId uint32) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				if input.SerialNumber == 0 {
+					rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("serialNumber", "a purchase needs the serial number of a commodity"))
+					return
+				}
+				if !wallet.ValidCurrency(input.Currency) {
+					rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("currency", "currency must be 1 (credit) or 2 (points)"))
+					return
+				}
+
+				requestId := input.Id
+				if requestId == uuid.Nil {
+					requestId = uuid.New()
+				}
+				ctx := correlation.WithRequestId(d.Context(), requestId)
+
+				m, err := NewProcessor(d.Logger(), ctx, db).PurchaseAndEmit(characterId, input.Currency, input.SerialNumber)
+				if errors.Is(err, commodity.ErrNotFound) {
+					rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("serialNumber", "no commodity has the serial number"))
+					return
+				}
+				if err != nil {
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+
+				res, err := Transform(m)
+				if err != nil {
+					d.Logger().WithError(err).Errorf("Creating REST model.")
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+
+				rest.MarshalCreated[RestModel](d.Logger())(w)(c.ServerInformation())(res)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cashshop
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/wallet"
+	"github.com/google/uuid"
+	"github.com/jtumidanski/api2go/jsonapi"
+)
+
+// RestModel represents a cash shop purchase for REST API
+// A request names the commodity and currency, and the response includes the asset, its item and the wallet after payment
+type RestModel struct {
+	Id           uuid.UUID        `json:"-"`
+	CharacterId  uint32           `json:"characterId"`
+	Currency     uint32           `json:"currency"`
+	SerialNumber uint32           `json:"serialNumber"`
+	Price        uint32           `json:"price"`
+	Asset        asset.RestModel  `json:"-"`
+	Wallet       wallet.RestModel `json:"-"`
+}
+
+// GetName returns the resource name
+func (r RestModel) GetName() string {
+	return "purchases"
+}
+
+// GetID returns the resource ID
+func (r RestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID. A purchase request may leave it out and have one assigned
+func (r *RestModel) SetID(strId string) error {
+	if strId == "" {
+		r.Id = uuid.Nil
+		return nil
+	}
+	id, err := uuid.Parse(strId)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// GetReferences returns the references for this resource
+func (r RestModel) GetReferences() []jsonapi.Reference {
+	return []jsonapi.Reference{
+		{
+			Type: "assets",
+			Name: "asset",
+		},
+		{
+			Type: "wallets",
+			Name: "wallet",
+		},
+	}
+}
+
+// GetReferencedIDs returns the referenced IDs for this resource
+func (r RestModel) GetReferencedIDs() []jsonapi.ReferenceID {
+	return []jsonapi.ReferenceID{
+		{
+			ID:   r.Asset.GetID(),
+			Type: r.Asset.GetName(),
+			Name: "asset",
+		},
+		{
+			ID:   r.Wallet.GetID(),
+			Type: r.Wallet.GetName(),
+			Name: "wallet",
+		},
+	}
+}
+
+// GetReferencedStructs returns the referenced structs for this resource
+func (r RestModel) GetReferencedStructs() []jsonapi.MarshalIdentifier {
+	return []jsonapi.MarshalIdentifier{r.Asset, r.Wallet}
+}
+
+// SetToOneReferenceID sets a to-one reference ID
+func (r *RestModel) SetToOneReferenceID(name, ID string) error {
+	if ID == "" {
+		return nil
+	}
+	if name == "asset" {
+		return r.Asset.SetID(ID)
+	}
+	if name == "wallet" {
+		return r.Wallet.SetID(ID)
+	}
+	return nil
+}
+
+// SetToManyReferenceIDs sets to-many reference IDs
+func (r *RestModel) SetToManyReferenceIDs(name string, IDs []string) error {
+	return nil
+}
+
+// Transform converts a Receipt to a RestModel
+func Transform(m Receipt) (RestModel, error) {
+	a, err := asset.Transform(m.Asset())
+	if err != nil {
+		return RestModel{}, err
+	}
+	w, err := wallet.Transform(m.Wallet())
+	if err != nil {
+		return RestModel{}, err
+	}
+	return RestModel{
+		Id:           m.RequestId(),
+		CharacterId:  m.CharacterId(),
+		Currency:     m.Currency(),
+		SerialNumber: m.SerialNumber(),
+		Price:        m.Price(),
+		Asset:        a,
+		Wallet:       w,
+	}, nil
+}
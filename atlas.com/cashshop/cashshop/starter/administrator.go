@@ -0,0 +1,26 @@
+package starter
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// createEntity records a starter package grant unless the character already received one
+// The returned flag is false when the grant was previously recorded
+func createEntity(db *gorm.DB, tenantId uuid.UUID, characterId uint32, accountId uint32, compartmentId uuid.UUID) (Entity, bool, error) {
+	entity := Entity{
+		TenantId:      tenantId,
+		CharacterId:   characterId,
+		AccountId:     accountId,
+		CompartmentId: compartmentId,
+		CreatedAt:     time.Now(),
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity)
+	if result.Error != nil {
+		return Entity{}, false, result.Error
+	}
+	return entity, result.RowsAffected == 1, nil
+}
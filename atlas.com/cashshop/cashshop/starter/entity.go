@@ -0,0 +1,30 @@
+package starter
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Entity represents the starter package granted to a character in the database
+type Entity struct {
+	TenantId      uuid.UUID `gorm:"primaryKey;type:uuid"`
+	CharacterId   uint32    `gorm:"primaryKey"`
+	AccountId     uint32    `gorm:"not null"`
+	CompartmentId uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_starter_grants"
+}
+
+// Make converts an Entity to a Model
+func Make(e Entity) (Model, error) {
+	return Model{
+		characterId:   e.CharacterId,
+		accountId:     e.AccountId,
+		compartmentId: e.CompartmentId,
+		createdAt:     e.CreatedAt,
+	}, nil
+}
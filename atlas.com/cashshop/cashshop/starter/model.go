@@ -0,0 +1,34 @@
+package starter
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Model represents the starter package granted to a character
+type Model struct {
+	characterId   uint32
+	accountId     uint32
+	compartmentId uuid.UUID
+	createdAt     time.Time
+}
+
+// CharacterId returns the character which received the starter package
+func (m Model) CharacterId() uint32 {
+	return m.characterId
+}
+
+// AccountId returns the account of the character
+func (m Model) AccountId() uint32 {
+	return m.accountId
+}
+
+// CompartmentId returns the compartment which received the starter items
+func (m Model) CompartmentId() uuid.UUID {
+	return m.compartmentId
+}
+
+// CreatedAt returns when the starter package was granted
+func (m Model) CreatedAt() time.Time {
+	return m.createdAt
+}
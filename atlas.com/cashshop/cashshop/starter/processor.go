@@ -0,0 +1,137 @@
+package starter
+
+import (
+	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/item"
+	"atlas-cashshop/character"
+	"atlas-cashshop/configuration"
+	"atlas-cashshop/database"
+	"atlas-cashshop/kafka/message"
+	"atlas-cashshop/outbox"
+	"context"
+	"errors"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrInsufficientCapacity is returned when the compartment cannot hold the whole starter package
+// It is not a rejection, so the command is retried and then dead-lettered, and can be replayed once the compartment has room
+var ErrInsufficientCapacity = errors.New("compartment cannot hold the starter package")
+
+// Processor grants the tenant configured starter package to new characters
+type Processor interface {
+	Grant(mb *message.Buffer) func(characterId uint32) error
+	GrantAndEmit(characterId uint32) error
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l    logrus.FieldLogger
+	ctx  context.Context
+	db   *gorm.DB
+	t    tenant.Model
+	chaP character.Processor
+	cfgP configuration.Processor
+	cicP compartment.Processor
+	itmP item.Processor
+	astP asset.Processor
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:    l,
+		ctx:  ctx,
+		db:   db,
+		t:    tenant.MustFromContext(ctx),
+		chaP: character.NewProcessor(l, ctx),
+		cfgP: configuration.NewProcessor(l, ctx),
+		cicP: compartment.NewProcessor(l, ctx, db),
+		itmP: item.NewProcessor(l, ctx, db),
+		astP: asset.NewProcessor(l, ctx, db),
+	}
+}
+
+func (p *ProcessorImpl) withTransaction(tx *gorm.DB) *ProcessorImpl {
+	return &ProcessorImpl{
+		l:    p.l,
+		ctx:  p.ctx,
+		db:   tx,
+		t:    p.t,
+		chaP: p.chaP,
+		cfgP: p.cfgP,
+		cicP: p.cicP.WithTransaction(tx),
+		itmP: p.itmP.WithTransaction(tx),
+		astP: p.astP.WithTransaction(tx),
+	}
+}
+
+// Grant places the starter items into the compartment matching the character's job
+// A character receives the starter package at most once. A package which does not fit in the compartment is not granted at all, so it can be delivered in full later
+func (p *ProcessorImpl) Grant(mb *message.Buffer) func(characterId uint32) error {
+	return func(characterId uint32) error {
+		cfg, err := p.cfgP.GetTenantConfiguration()
+		if err != nil {
+			p.l.WithError(err).Errorf("Unable to retrieve tenant configuration for starter package of character [%d].", characterId)
+			return err
+		}
+		if len(cfg.StarterItems()) == 0 {
+			return nil
+		}
+
+		c, err := p.chaP.GetById()(characterId)
+		if err != nil {
+			p.l.WithError(err).Errorf("Unable to retrieve character [%d] for starter package.", characterId)
+			return err
+		}
+
+		return database.ExecuteTransaction(p.db, func(tx *gorm.DB) error {
+			ccm, err := p.cicP.WithTransaction(tx).GetByAccountIdAndType(c.AccountId(), compartment.TypeFromJobId(c.JobId()))
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to locate compartment for starter package of character [%d].", characterId)
+				return err
+			}
+
+			_, created, err := createEntity(tx, p.t.Id(), characterId, c.AccountId(), ccm.Id())
+			if err != nil {
+				return err
+			}
+			if !created {
+				p.l.Debugf("Character [%d] already received the starter package.", characterId)
+				return nil
+			}
+
+			free := int(ccm.Capacity()) - len(ccm.Assets())
+			if free < len(cfg.StarterItems()) {
+				p.l.Errorf("Compartment [%s] has room for [%d] of the [%d] starter items of character [%d].", ccm.Id(), free, len(cfg.StarterItems()), characterId)
+				return ErrInsufficientCapacity
+			}
+
+			now := time.Now()
+			for _, si := range cfg.StarterItems() {
+				im, err := p.itmP.WithTransaction(tx).Create(mb)(si.TemplateId())(si.Quantity())(0)(characterId)(si.Expiration(now))
+				if err != nil {
+					p.l.WithError(err).Errorf("Unable to create starter item [%d] for character [%d].", si.TemplateId(), characterId)
+					return err
+				}
+				_, err = p.astP.WithTransaction(tx).Create(mb)(ccm.Id())(im.Id())
+				if err != nil {
+					p.l.WithError(err).Errorf("Unable to create asset for starter item [%d] for character [%d].", si.TemplateId(), characterId)
+					return err
+				}
+			}
+			p.l.Debugf("Granted starter package to character [%d] in compartment [%s].", characterId, ccm.Id())
+			return nil
+		})
+	}
+}
+
+// GrantAndEmit grants the starter package and emits the resulting events
+func (p *ProcessorImpl) GrantAndEmit(characterId uint32) error {
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.withTransaction(tx).Grant(buf)(characterId)
+	})
+}
@@ -0,0 +1,95 @@
+package starter_test
+
+import (
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/cashshop/starter"
+	"atlas-cashshop/character"
+	"atlas-cashshop/configuration"
+	"atlas-cashshop/database/fixture"
+	"atlas-cashshop/rest/stub"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+)
+
+const testCharacterId = uint32(2000)
+
+// stubServices serves the explorer character and a starter package of two items, the second of which has no days configured
+func stubServices(t *testing.T, ctx context.Context) {
+	services := stub.NewServer()
+	t.Cleanup(services.Close)
+	t.Setenv("CHARACTERS", services.BaseUrl())
+	t.Setenv("CONFIGURATIONS", services.BaseUrl())
+
+	if err := services.Put(fmt.Sprintf(character.ById, testCharacterId), character.RestModel{Id: testCharacterId, AccountId: fixture.AccountId, JobId: 100}); err != nil {
+		t.Fatalf("Unable to stub character: %v", err)
+	}
+	cfg := configuration.RestModel{
+		Id: tenant.MustFromContext(ctx).Id().String(),
+		CashShop: configuration.CashShopRestModel{StarterItems: []configuration.StarterItemRestModel{
+			{TemplateId: 5000000, Quantity: 1, Days: 7},
+			{TemplateId: 5000001},
+		}},
+	}
+	if err := services.Put(fmt.Sprintf(configuration.ById, cfg.Id), cfg); err != nil {
+		t.Fatalf("Unable to stub configuration: %v", err)
+	}
+}
+
+func TestGrant(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	stubServices(t, ctx)
+
+	if err := starter.NewProcessor(l, ctx, db).GrantAndEmit(testCharacterId); err != nil {
+		t.Fatalf("Unable to grant starter package: %v", err)
+	}
+	cm, err := compartment.NewProcessor(l, ctx, db).GetByAccountIdAndType(fixture.AccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	if len(cm.Assets()) != 2 {
+		t.Fatalf("Expected 2 starter assets, got [%d]", len(cm.Assets()))
+	}
+	for _, a := range cm.Assets() {
+		if !a.Expiration().After(time.Now().Add(6 * 24 * time.Hour)) {
+			t.Errorf("Expected starter item [%d] to last for days, expires [%s]", a.TemplateId(), a.Expiration())
+		}
+	}
+}
+
+func TestGrantWithoutRoom(t *testing.T) {
+	l, ctx, db := fixture.Provision(t)
+	stubServices(t, ctx)
+
+	cp := compartment.NewProcessor(l, ctx, db)
+	cm, err := cp.GetByAccountIdAndType(fixture.AccountId, compartment.TypeExplorer)
+	if err != nil {
+		t.Fatalf("Unable to retrieve compartment: %v", err)
+	}
+	if _, err = cp.UpdateCapacityAndEmit(cm.Id(), 1); err != nil {
+		t.Fatalf("Unable to shrink compartment: %v", err)
+	}
+
+	sp := starter.NewProcessor(l, ctx, db)
+	if err = sp.GrantAndEmit(testCharacterId); !errors.Is(err, starter.ErrInsufficientCapacity) {
+		t.Fatalf("Expected a package which does not fit to be refused, got [%v]", err)
+	}
+	if cm, err = cp.GetById(cm.Id()); err != nil || len(cm.Assets()) != 0 {
+		t.Fatalf("Expected no starter item to be granted, got [%d] [%v]", len(cm.Assets()), err)
+	}
+
+	// Once there is room the package is delivered, as it was not recorded as granted
+	if _, err = cp.UpdateCapacityAndEmit(cm.Id(), compartment.DefaultCapacity); err != nil {
+		t.Fatalf("Unable to grow compartment: %v", err)
+	}
+	if err = sp.GrantAndEmit(testCharacterId); err != nil {
+		t.Fatalf("Unable to grant starter package: %v", err)
+	}
+	if cm, err = cp.GetById(cm.Id()); err != nil || len(cm.Assets()) != 2 {
+		t.Errorf("Expected 2 starter assets, got [%d] [%v]", len(cm.Assets()), err)
+	}
+}
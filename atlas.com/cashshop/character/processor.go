@@ -3,14 +3,18 @@ package character
 import (
 	"atlas-cashshop/character/inventory"
 	"context"
+	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/requests"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
 )
 
+var ErrNotFound = errors.New("character not found")
+
 type Processor interface {
 	GetById(decorators ...model.Decorator[Model]) func(characterId uint32) (Model, error)
+	GetIdsByAccountId(accountId uint32) ([]uint32, error)
 	InventoryDecorator(m Model) Model
 }
 
@@ -31,13 +35,23 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
 	return p
 }
 
+// GetById retrieves the character, or ErrNotFound when CHARACTERS has none
 func (p *ProcessorImpl) GetById(decorators ...model.Decorator[Model]) func(characterId uint32) (Model, error) {
 	return func(characterId uint32) (Model, error) {
 		mp := requests.Provider[RestModel, Model](p.l, p.ctx)(requestById(characterId), Extract)
-		return model.Map(model.Decorate(decorators))(mp)()
+		m, err := model.Map(model.Decorate(decorators))(mp)()
+		if errors.Is(err, requests.ErrNotFound) {
+			return Model{}, ErrNotFound
+		}
+		return m, err
 	}
 }
 
+// GetIdsByAccountId returns the ids of every character of an account, across all worlds
+func (p *ProcessorImpl) GetIdsByAccountId(accountId uint32) ([]uint32, error) {
+	return requests.Provider[[]RestModel, []uint32](p.l, p.ctx)(requestByAccountId(accountId), ExtractIds)()
+}
+
 func (p *ProcessorImpl) InventoryDecorator(m Model) Model {
 	i, err := p.ip.GetByCharacterId(m.Id())
 	if err != nil {
@@ -7,8 +7,9 @@ import (
 )
 
 const (
-	Resource = "characters"
-	ById     = Resource + "/%d"
+	Resource    = "characters"
+	ById        = Resource + "/%d"
+	ByAccountId = Resource + "?accountId=%d"
 )
 
 func getBaseRequest() string {
@@ -18,3 +19,7 @@ func getBaseRequest() string {
 func requestById(id uint32) requests.Request[RestModel] {
 	return rest.MakeGetRequest[RestModel](fmt.Sprintf(getBaseRequest()+ById, id))
 }
+
+func requestByAccountId(accountId uint32) requests.Request[[]RestModel] {
+	return rest.MakeGetRequest[[]RestModel](fmt.Sprintf(getBaseRequest()+ByAccountId, accountId))
+}
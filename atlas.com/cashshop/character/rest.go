@@ -117,3 +117,12 @@ func Extract(m RestModel) (Model, error) {
 		stance:             m.Stance,
 	}, nil
 }
+
+// ExtractIds returns the ids of the characters
+func ExtractIds(rms []RestModel) ([]uint32, error) {
+	ids := make([]uint32, 0, len(rms))
+	for _, rm := range rms {
+		ids = append(ids, rm.Id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,37 @@
+package configuration
+
+import "time"
+
+// Model is the cash shop portion of a tenant configuration
+type Model struct {
+	starterItems []StarterItem
+}
+
+// StarterItems returns the cash items granted to every new character
+func (m Model) StarterItems() []StarterItem {
+	return m.starterItems
+}
+
+// StarterItem is a cash item granted to a new character
+type StarterItem struct {
+	templateId uint32
+	quantity   uint32
+	days       uint32
+}
+
+func (i StarterItem) TemplateId() uint32 {
+	return i.templateId
+}
+
+func (i StarterItem) Quantity() uint32 {
+	return i.quantity
+}
+
+func (i StarterItem) Days() uint32 {
+	return i.days
+}
+
+// Expiration returns when a starter item granted now expires
+func (i StarterItem) Expiration(now time.Time) time.Time {
+	return now.AddDate(0, 0, int(i.days))
+}
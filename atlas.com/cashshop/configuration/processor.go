@@ -0,0 +1,31 @@
+package configuration
+
+import (
+	"context"
+	"github.com/Chronicle20/atlas-rest/requests"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+)
+
+type Processor interface {
+	GetTenantConfiguration() (Model, error)
+}
+
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	t   tenant.Model
+}
+
+func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
+	p := &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		t:   tenant.MustFromContext(ctx),
+	}
+	return p
+}
+
+func (p *ProcessorImpl) GetTenantConfiguration() (Model, error) {
+	return requests.Provider[RestModel, Model](p.l, p.ctx)(requestByTenantId(p.t.Id()), Extract)()
+}
@@ -0,0 +1,21 @@
+package configuration
+
+import (
+	"atlas-cashshop/rest"
+	"fmt"
+	"github.com/Chronicle20/atlas-rest/requests"
+	"github.com/google/uuid"
+)
+
+const (
+	Resource = "configurations/tenants"
+	ById     = Resource + "/%s"
+)
+
+func getBaseRequest() string {
+	return requests.RootUrl("CONFIGURATIONS")
+}
+
+func requestByTenantId(tenantId uuid.UUID) requests.Request[RestModel] {
+	return rest.MakeGetRequest[RestModel](fmt.Sprintf(getBaseRequest()+ById, tenantId.String()))
+}
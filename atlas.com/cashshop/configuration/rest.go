@@ -0,0 +1,48 @@
+package configuration
+
+import "atlas-cashshop/cashshop/item"
+
+type RestModel struct {
+	Id       string            `json:"-"`
+	CashShop CashShopRestModel `json:"cashShop"`
+}
+
+func (r RestModel) GetName() string {
+	return "tenants"
+}
+
+func (r RestModel) GetID() string {
+	return r.Id
+}
+
+func (r *RestModel) SetID(strId string) error {
+	r.Id = strId
+	return nil
+}
+
+type CashShopRestModel struct {
+	StarterItems []StarterItemRestModel `json:"starterItems"`
+}
+
+type StarterItemRestModel struct {
+	TemplateId uint32 `json:"templateId"`
+	Quantity   uint32 `json:"quantity"`
+	Days       uint32 `json:"days"`
+}
+
+func Extract(rm RestModel) (Model, error) {
+	items := make([]StarterItem, 0, len(rm.CashShop.StarterItems))
+	for _, si := range rm.CashShop.StarterItems {
+		quantity := si.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		// An item without days would expire the moment it is granted, so it lasts as long as a purchased one
+		days := si.Days
+		if days == 0 {
+			days = item.DefaultExpirationDays
+		}
+		items = append(items, StarterItem{templateId: si.TemplateId, quantity: quantity, days: days})
+	}
+	return Model{starterItems: items}, nil
+}
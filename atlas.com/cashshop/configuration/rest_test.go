@@ -0,0 +1,17 @@
+package configuration
+
+import (
+	"atlas-cashshop/cashshop/item"
+	"testing"
+)
+
+func TestExtractDefaults(t *testing.T) {
+	m, err := Extract(RestModel{CashShop: CashShopRestModel{StarterItems: []StarterItemRestModel{{TemplateId: 5000000}}}})
+	if err != nil {
+		t.Fatalf("Unable to extract configuration: %v", err)
+	}
+	si := m.StarterItems()[0]
+	if si.Quantity() != 1 || si.Days() != item.DefaultExpirationDays {
+		t.Errorf("Expected an unset quantity and days to default to 1 and [%d], got [%d] and [%d]", item.DefaultExpirationDays, si.Quantity(), si.Days())
+	}
+}
@@ -0,0 +1,21 @@
+package correlation
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type requestIdKey struct{}
+
+// WithRequestId returns a context carrying the request ID of the command being handled
+func WithRequestId(ctx context.Context, requestId uuid.UUID) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestId returns the request ID of the command being handled, or uuid.Nil when the work was not requested by a command
+func RequestId(ctx context.Context) uuid.UUID {
+	if id, ok := ctx.Value(requestIdKey{}).(uuid.UUID); ok {
+		return id
+	}
+	return uuid.Nil
+}
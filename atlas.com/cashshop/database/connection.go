@@ -4,7 +4,6 @@ import (
 	"atlas-cashshop/retry"
 	"fmt"
 	"github.com/sirupsen/logrus"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"os"
 	"strconv"
@@ -58,21 +57,122 @@ func (d *DSNBuilder) Build() string {
 }
 
 type Configuration struct {
-	dsn        string
-	migrations []Migrator
+	dialector  gorm.Dialector
+	replicas   []gorm.Dialector
+	migrations []Migration
 }
 
 type Configurator func(c *Configuration)
 
-func SetMigrations(migrations ...Migrator) Configurator {
+// SetMigrations applies the pending migrations once connected
+func SetMigrations(migrations ...Migration) Configurator {
 	return func(c *Configuration) {
 		c.migrations = migrations
 	}
 }
 
-type Migrator func(db *gorm.DB) error
+// SetDialector connects to the given database rather than the one configured by the environment
+func SetDialector(dialector gorm.Dialector) Configurator {
+	return func(c *Configuration) {
+		c.dialector = dialector
+	}
+}
+
+// SetReplicas serves the reads of query-only providers from the given databases rather than the replicas configured by the environment
+func SetReplicas(dialectors ...gorm.Dialector) Configurator {
+	return func(c *Configuration) {
+		c.replicas = dialectors
+	}
+}
 
 func Connect(l logrus.FieldLogger, configurators ...Configurator) *gorm.DB {
+	db, err := Open(l, configurators...)
+	if err != nil {
+		l.WithError(err).Fatalf("Unable to open database.")
+	}
+	return db
+}
+
+// Open connects to the database selected by DB_DIALECT and applies its pending migrations
+func Open(l logrus.FieldLogger, configurators ...Configurator) (*gorm.DB, error) {
+	c := &Configuration{
+		dialector:  dialectorFromEnv(),
+		replicas:   replicasFromEnv(),
+		migrations: make([]Migration, 0),
+	}
+	for _, configurator := range configurators {
+		configurator(c)
+	}
+
+	db, err := open(c.dialector)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to connect to database.")
+		return nil, err
+	}
+
+	if len(c.replicas) > 0 {
+		r := &replicas{}
+		for _, d := range c.replicas {
+			rdb, err := open(d)
+			if err != nil {
+				l.WithError(err).Errorf("Failed to connect to read replica.")
+				return nil, err
+			}
+			r.dbs = append(r.dbs, rdb)
+		}
+		if err = db.Use(r); err != nil {
+			return nil, err
+		}
+		l.Infof("Serving reads from [%d] replicas.", len(r.dbs))
+	}
+
+	if len(c.migrations) == 0 {
+		return db, nil
+	}
+	if err = Migrate(l, db, c.migrations); err != nil {
+		l.WithError(err).Errorf("Migrating schema.")
+		return nil, err
+	}
+	return db, nil
+}
+
+// open connects to the database, retrying while it is unavailable
+// Every failure to connect is retried, so the error returned is always ErrMaxRetries wrapping the last one
+func open(dialector gorm.Dialector) (*gorm.DB, error) {
+	var db *gorm.DB
+	tryToConnect := func(attempt int) (bool, error) {
+		var err error
+		// Translated errors surface a duplicate key as gorm.ErrDuplicatedKey on either dialect
+		db, err = gorm.Open(dialector, &gorm.Config{TranslateError: true})
+		if err != nil {
+			return true, err
+		}
+		return false, nil
+	}
+	err := retry.Try(tryToConnect, 10)
+	return db, err
+}
+
+func dialectorFromEnv() gorm.Dialector {
+	if Dialect() == DialectSQLite {
+		return SQLite(Path())
+	}
+	return Postgres(dsnBuilderFromEnv().Build())
+}
+
+// replicasFromEnv returns the read replicas of the primary. An embedded SQLite database has none
+func replicasFromEnv() []gorm.Dialector {
+	if Dialect() == DialectSQLite {
+		return nil
+	}
+	var result []gorm.Dialector
+	for _, h := range ReplicaHosts() {
+		result = append(result, Postgres(dsnBuilderFromEnv().SetHost(h).Build()))
+	}
+	return result
+}
+
+func dsnBuilderFromEnv() *DSNBuilder {
 	dsnBuilder := NewDSNBuilder()
 	user, ok := os.LookupEnv("DB_USER")
 	if ok {
@@ -101,36 +201,5 @@ func Connect(l logrus.FieldLogger, configurators ...Configurator) *gorm.DB {
 	if ok {
 		dsnBuilder = dsnBuilder.SetDatabaseName(databaseName)
 	}
-
-	c := &Configuration{
-		dsn:        dsnBuilder.Build(),
-		migrations: make([]Migrator, 0),
-	}
-	for _, configurator := range configurators {
-		configurator(c)
-	}
-
-	var db *gorm.DB
-	tryToConnect := func(attempt int) (bool, error) {
-		var err error
-		db, err = gorm.Open(postgres.Open(dsnBuilder.Build()), &gorm.Config{})
-		if err != nil {
-			return true, err
-		}
-		return false, err
-	}
-
-	err := retry.Try(tryToConnect, 10)
-	if err != nil {
-		l.WithError(err).Fatalf("Failed to connect to database.")
-	}
-
-	// Migrate the schema
-	for _, m := range c.migrations {
-		err = m(db)
-		if err != nil {
-			l.WithError(err).Fatalf("Migrating schema.")
-		}
-	}
-	return db
+	return dsnBuilder
 }
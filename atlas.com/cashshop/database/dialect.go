@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	EnvDialect = "DB_DIALECT"
+	EnvPath    = "DB_PATH"
+
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+
+	// MemoryPath keeps an SQLite database in memory for the lifetime of the process
+	MemoryPath = "file::memory:?cache=shared"
+
+	// SQLiteBusyTimeout is how long a connection waits for a lock held by another before failing with a locked database
+	SQLiteBusyTimeout = 5 * time.Second
+)
+
+// Dialect returns the configured database dialect, falling back to DialectPostgres
+func Dialect() string {
+	if v, ok := os.LookupEnv(EnvDialect); ok && v == DialectSQLite {
+		return DialectSQLite
+	}
+	return DialectPostgres
+}
+
+// Path returns the file of the SQLite database, falling back to MemoryPath
+func Path() string {
+	if v, ok := os.LookupEnv(EnvPath); ok && v != "" {
+		return v
+	}
+	return MemoryPath
+}
+
+// SQLite opens an embedded database at the path
+func SQLite(path string) gorm.Dialector {
+	return sqlite.Open(sqliteDSN(path))
+}
+
+// sqliteDSN shares one cache between the connections of the pool and has them wait out each other's locks
+// The driver only passes query parameters on to SQLite for a file: URI, so a plain path is turned into one
+func sqliteDSN(path string) string {
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	if !strings.Contains(path, "cache=") {
+		path += sep + "cache=shared"
+		sep = "&"
+	}
+	return path + sep + fmt.Sprintf("_pragma=busy_timeout(%d)", SQLiteBusyTimeout.Milliseconds())
+}
+
+// Postgres opens a Postgres database at the DSN
+func Postgres(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
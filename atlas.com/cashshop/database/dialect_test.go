@@ -0,0 +1,19 @@
+package database
+
+import "testing"
+
+func TestSQLiteDSN(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{MemoryPath, "file::memory:?cache=shared&_pragma=busy_timeout(5000)"},
+		{"cashshop.db", "file:cashshop.db?cache=shared&_pragma=busy_timeout(5000)"},
+		{"file:test?mode=memory&cache=private", "file:test?mode=memory&cache=private&_pragma=busy_timeout(5000)"},
+	}
+	for _, c := range cases {
+		if dsn := sqliteDSN(c.path); dsn != c.expected {
+			t.Errorf("Expected DSN [%s] for [%s], got [%s]", c.expected, c.path, dsn)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package fixture
+
+import (
+	"atlas-cashshop/account"
+	"atlas-cashshop/database"
+	"atlas-cashshop/database/migrations"
+	"context"
+	"os"
+	"testing"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EnvTestDatabase optionally holds the DSN of a Postgres database to run tests against, rather than an embedded SQLite database
+const EnvTestDatabase = "TEST_DB_DSN"
+
+// AccountId is the account Provision prepares
+const AccountId = uint32(1000)
+
+// Open returns a database with every migration applied. Without TEST_DB_DSN each call opens its own in-memory SQLite database
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+	dialector := database.SQLite("file:" + uuid.NewString() + "?mode=memory&cache=shared")
+	if dsn, ok := os.LookupEnv(EnvTestDatabase); ok {
+		dialector = database.Postgres(dsn)
+	}
+	db, err := database.Open(logrus.New(), database.SetDialector(dialector), database.SetMigrations(migrations.All()...))
+	if err != nil {
+		t.Fatalf("Unable to open test database: %v", err)
+	}
+	return db
+}
+
+// Tenant returns a context of a new tenant, so a test does not see the rows of another sharing the database
+func Tenant(t *testing.T) context.Context {
+	t.Helper()
+	te, err := tenant.Create(uuid.New(), "GMS", 83, 1)
+	if err != nil {
+		t.Fatalf("Unable to create tenant: %v", err)
+	}
+	return tenant.WithContext(context.Background(), te)
+}
+
+// Provision opens a database and provisions the cash shop data of AccountId as a new tenant
+func Provision(t *testing.T) (logrus.FieldLogger, context.Context, *gorm.DB) {
+	t.Helper()
+	db := Open(t)
+	l := logrus.New()
+	ctx := Tenant(t)
+	if _, err := account.NewProcessor(l, ctx, db).ProvisionAndEmit(AccountId); err != nil {
+		t.Fatalf("Unable to provision account: %v", err)
+	}
+	return l, ctx, db
+}
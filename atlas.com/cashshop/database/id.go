@@ -0,0 +1,12 @@
+package database
+
+import "github.com/google/uuid"
+
+// AssignId gives an entity about to be created a random ID unless it has one, so it does not depend on a database generated default
+// Entities call it from their BeforeCreate hook
+func AssignId(id *uuid.UUID) error {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var errDryRun = errors.New("dry run")
+
+// migrationLockKey identifies the Postgres advisory lock held while migrating, so replicas starting together migrate one at a time
+var migrationLockKey = func() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("atlas-cashshop/schema_migrations"))
+	return int64(h.Sum64() >> 1)
+}()
+
+// MigrationStatus reports whether a known migration has been applied
+type MigrationStatus struct {
+	Version   uint64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrate applies the pending migrations in version order
+// Versions applied by a newer release are left alone, so an older replica can still start during a rolling deploy
+func Migrate(l logrus.FieldLogger, db *gorm.DB, ms []Migration) error {
+	return withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		known := make(map[uint64]struct{}, len(ms))
+		for _, m := range ms {
+			known[m.Version] = struct{}{}
+		}
+		for v, a := range applied {
+			if _, ok := known[v]; !ok {
+				l.Warnf("Database has migration [%d] [%s] applied, which this release does not know.", v, a.Name)
+			}
+		}
+
+		pending, err := pendingMigrations(conn, ms)
+		if err != nil {
+			return err
+		}
+		for _, m := range pending {
+			l.Infof("Applying migration [%d] [%s].", m.Version, m.Name)
+			err = run(conn, m.NoTransaction, func(tx *gorm.DB) error {
+				if err := m.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&AppliedMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				l.WithError(err).Errorf("Unable to apply migration [%d] [%s].", m.Version, m.Name)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Revert reverts the last steps applied migrations, most recent first
+func Revert(l logrus.FieldLogger, db *gorm.DB, ms []Migration, steps int) error {
+	return withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn, ms, steps)
+		if err != nil {
+			return err
+		}
+		for _, m := range applied {
+			l.Infof("Reverting migration [%d] [%s].", m.Version, m.Name)
+			err = run(conn, m.NoTransaction, func(tx *gorm.DB) error {
+				if err := m.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&AppliedMigration{}, m.Version).Error
+			})
+			if err != nil {
+				l.WithError(err).Errorf("Unable to revert migration [%d] [%s].", m.Version, m.Name)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PlanMigrate reports the statements Migrate would execute without changing the schema
+// The migrations are applied within a transaction which is rolled back. Those which cannot run in a transaction are only rendered
+func PlanMigrate(db *gorm.DB, ms []Migration) ([]PlannedMigration, error) {
+	var result []PlannedMigration
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		pending, err := pendingMigrations(conn, ms)
+		if err != nil {
+			return err
+		}
+		result, err = plan(conn, pending, func(m Migration) Step { return m.Up })
+		return err
+	})
+	return result, err
+}
+
+// PlanRevert reports the statements Revert would execute without changing the schema
+func PlanRevert(db *gorm.DB, ms []Migration, steps int) ([]PlannedMigration, error) {
+	var result []PlannedMigration
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn, ms, steps)
+		if err != nil {
+			return err
+		}
+		result, err = plan(conn, applied, func(m Migration) Step { return m.Down })
+		return err
+	})
+	return result, err
+}
+
+// Status reports each known migration in version order and when it was applied
+func Status(db *gorm.DB, ms []Migration) ([]MigrationStatus, error) {
+	if err := db.AutoMigrate(&AppliedMigration{}); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]MigrationStatus, 0, len(ms))
+	for _, m := range sorted(ms) {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			at := a.AppliedAt
+			s.AppliedAt = &at
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// withMigrationLock runs f on a single connection. On Postgres the connection holds an advisory lock, so other replicas wait until f completes
+// SQLite databases are embedded in one process and need no lock
+func withMigrationLock(db *gorm.DB, f func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		// A new session keeps the statements run on the pinned connection from sharing their clauses
+		conn = conn.Session(&gorm.Session{})
+		if conn.Dialector.Name() == DialectPostgres {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+		}
+		if err := conn.AutoMigrate(&AppliedMigration{}); err != nil {
+			return err
+		}
+		return f(conn)
+	})
+}
+
+func run(conn *gorm.DB, noTransaction bool, f func(tx *gorm.DB) error) error {
+	if noTransaction {
+		return f(conn)
+	}
+	return conn.Transaction(f)
+}
+
+func plan(conn *gorm.DB, ms []Migration, step func(m Migration) Step) ([]PlannedMigration, error) {
+	result := make([]PlannedMigration, 0, len(ms))
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		for _, m := range ms {
+			s := step(m)
+			if s == nil {
+				return ErrIrreversible
+			}
+			r := newRecorder()
+			if err := s(tx.Session(&gorm.Session{Logger: r, DryRun: m.NoTransaction})); err != nil {
+				return err
+			}
+			result = append(result, PlannedMigration{Version: m.Version, Name: m.Name, Statements: r.statements})
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return result, nil
+}
+
+func pendingMigrations(db *gorm.DB, ms []Migration) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	var result []Migration
+	for _, m := range sorted(ms) {
+		if _, ok := applied[m.Version]; !ok {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+// appliedMigrations returns the last steps applied migrations, most recent first
+func appliedMigrations(db *gorm.DB, ms []Migration, steps int) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	known := sorted(ms)
+	var result []Migration
+	for i := len(known) - 1; i >= 0 && len(result) < steps; i-- {
+		m := known[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return nil, ErrIrreversible
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+func appliedVersions(db *gorm.DB) (map[uint64]AppliedMigration, error) {
+	var es []AppliedMigration
+	if err := db.Find(&es).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint64]AppliedMigration, len(es))
+	for _, e := range es {
+		result[e.Version] = e
+	}
+	return result, nil
+}
+
+func sorted(ms []Migration) []Migration {
+	result := append([]Migration(nil), ms...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
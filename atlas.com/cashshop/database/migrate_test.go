@@ -0,0 +1,111 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type testWidget struct {
+	Id   uint32 `gorm:"primaryKey"`
+	Name string `gorm:"not null"`
+}
+
+func (e testWidget) TableName() string {
+	return "widgets"
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version: 2,
+			Name:    "widget name index",
+			Up:      SQL("CREATE INDEX idx_widgets_name ON widgets (name)"),
+			Down:    SQL("DROP INDEX idx_widgets_name"),
+		},
+		{
+			Version: 1,
+			Name:    "widgets",
+			Up: func(db *gorm.DB) error {
+				return db.Migrator().CreateTable(&testWidget{})
+			},
+			Down: func(db *gorm.DB) error {
+				return db.Migrator().DropTable(&testWidget{})
+			},
+		},
+	}
+}
+
+func testDatabase(t *testing.T) *gorm.DB {
+	db, err := Open(logrus.New(), SetDialector(SQLite("file:"+uuid.NewString()+"?mode=memory&cache=shared")))
+	if err != nil {
+		t.Fatalf("Unable to open database: %v", err)
+	}
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	db := testDatabase(t)
+	ms := testMigrations()
+
+	ps, err := PlanMigrate(db, ms)
+	if err != nil {
+		t.Fatalf("Unable to plan migrations: %v", err)
+	}
+	if len(ps) != 2 || ps[0].Version != 1 || ps[1].Version != 2 || len(ps[0].Statements) == 0 {
+		t.Fatalf("Expected a plan for versions 1 and 2 in order, got %+v", ps)
+	}
+	if db.Migrator().HasTable(&testWidget{}) {
+		t.Fatalf("Expected a dry run to leave the schema untouched")
+	}
+
+	if err = Migrate(logrus.New(), db, ms); err != nil {
+		t.Fatalf("Unable to migrate: %v", err)
+	}
+	if !db.Migrator().HasIndex(&testWidget{}, "idx_widgets_name") {
+		t.Errorf("Expected migrations to be applied")
+	}
+	// Applied migrations are not applied again
+	if err = Migrate(logrus.New(), db, ms); err != nil {
+		t.Fatalf("Unable to migrate again: %v", err)
+	}
+
+	ss, err := Status(db, ms)
+	if err != nil {
+		t.Fatalf("Unable to retrieve status: %v", err)
+	}
+	for _, s := range ss {
+		if s.AppliedAt == nil {
+			t.Errorf("Expected migration [%d] to be applied", s.Version)
+		}
+	}
+}
+
+func TestRevert(t *testing.T) {
+	db := testDatabase(t)
+	ms := testMigrations()
+	if err := Migrate(logrus.New(), db, ms); err != nil {
+		t.Fatalf("Unable to migrate: %v", err)
+	}
+
+	if err := Revert(logrus.New(), db, ms, 1); err != nil {
+		t.Fatalf("Unable to revert: %v", err)
+	}
+	if db.Migrator().HasIndex(&testWidget{}, "idx_widgets_name") || !db.Migrator().HasTable(&testWidget{}) {
+		t.Errorf("Expected only the last migration to be reverted")
+	}
+	ss, err := Status(db, ms)
+	if err != nil {
+		t.Fatalf("Unable to retrieve status: %v", err)
+	}
+	if ss[0].AppliedAt == nil || ss[1].AppliedAt != nil {
+		t.Errorf("Expected version 2 to be pending after revert, got %+v", ss)
+	}
+
+	ms[1].Down = nil
+	if _, err = PlanRevert(db, ms, 1); err != ErrIrreversible {
+		t.Errorf("Expected a migration without a down step to be irreversible, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var ErrIrreversible = errors.New("migration cannot be reverted")
+
+// Migration is a versioned change to the schema. Applied versions are recorded in schema_migrations, so each is applied once
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      Step
+	Down    Step
+	// NoTransaction applies the migration outside a transaction, for statements which cannot run in one such as CREATE INDEX CONCURRENTLY
+	NoTransaction bool
+}
+
+// Step changes the schema of the database
+type Step func(db *gorm.DB) error
+
+// SQL returns a step which executes the statements in order
+func SQL(statements ...string) Step {
+	return func(db *gorm.DB) error {
+		for _, s := range statements {
+			if err := db.Exec(s).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Dialects returns a step which runs the step given for the dialect of the database
+func Dialects(steps map[string]Step) Step {
+	return func(db *gorm.DB) error {
+		s, ok := steps[db.Dialector.Name()]
+		if !ok {
+			return errors.New("migration has no step for dialect " + db.Dialector.Name())
+		}
+		return s(db)
+	}
+}
+
+// AppliedMigration records a migration applied to the database
+type AppliedMigration struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (e AppliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// PlannedMigration is a migration a dry run would apply or revert, along with the statements it would execute
+type PlannedMigration struct {
+	Version    uint64
+	Name       string
+	Statements []string
+}
+
+// recorder captures the statements a step executes. Queries which only read the schema are left out
+type recorder struct {
+	logger.Interface
+	statements []string
+}
+
+func newRecorder() *recorder {
+	return &recorder{Interface: logger.Discard}
+}
+
+func (r *recorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *recorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	s := strings.ToUpper(strings.TrimSpace(sql))
+	if strings.HasPrefix(s, "SELECT") || strings.HasPrefix(s, "PRAGMA") {
+		return
+	}
+	r.statements = append(r.statements, sql)
+}
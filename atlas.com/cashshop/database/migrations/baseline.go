@@ -0,0 +1,213 @@
+package migrations
+
+import (
+	"atlas-cashshop/database"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The baseline tables are frozen copies of the entities as they stood when versioned migrations were introduced
+// Applying the baseline to a database created by the former AutoMigrate at startup adopts it without changes
+
+type baselineWallet struct {
+	Id        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId  uuid.UUID `gorm:"not null;uniqueIndex:idx_accounts_tenant_account"`
+	AccountId uint32    `gorm:"not null;uniqueIndex:idx_accounts_tenant_account"`
+	Credit    uint32    `gorm:"not null;default=0"`
+	Points    uint32    `gorm:"not null;default=0"`
+	Prepaid   uint32    `gorm:"not null;default=0"`
+}
+
+func (e baselineWallet) TableName() string {
+	return "accounts"
+}
+
+type baselineWishlistItem struct {
+	Id           uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId     uuid.UUID `gorm:"not null"`
+	CharacterId  uint32    `gorm:"not null"`
+	SerialNumber uint32    `gorm:"not null"`
+}
+
+func (e baselineWishlistItem) TableName() string {
+	return "wishlist_items"
+}
+
+type baselineItem struct {
+	Id                 uint32    `gorm:"primaryKey;autoIncrement:true"`
+	TenantId           uuid.UUID `gorm:"not null"`
+	CashId             int64     `gorm:"not null"`
+	TemplateId         uint32    `gorm:"not null"`
+	Quantity           uint32    `gorm:"not null"`
+	Flag               uint16    `gorm:"not null"`
+	PurchasedBy        uint32    `gorm:"not null"`
+	Expiration         time.Time `gorm:"not null"`
+	PurchaserDeletedAt *time.Time
+}
+
+func (e baselineItem) TableName() string {
+	return "items"
+}
+
+type baselineCompartment struct {
+	Id        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId  uuid.UUID `gorm:"not null;uniqueIndex:idx_cash_compartments_tenant_account_type"`
+	AccountId uint32    `gorm:"not null;uniqueIndex:idx_cash_compartments_tenant_account_type"`
+	Type      byte      `gorm:"not null;uniqueIndex:idx_cash_compartments_tenant_account_type"`
+	Capacity  uint32    `gorm:"not null;default:55"`
+}
+
+func (e baselineCompartment) TableName() string {
+	return "cash_compartments"
+}
+
+type baselineAsset struct {
+	Id            uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId      uuid.UUID `gorm:"not null"`
+	CompartmentId uuid.UUID `gorm:"not null"`
+	ItemId        uint32    `gorm:"not null"`
+}
+
+func (e baselineAsset) TableName() string {
+	return "cash_assets"
+}
+
+type baselineReservation struct {
+	TenantId      uuid.UUID `gorm:"primaryKey;type:uuid"`
+	ItemId        uint32    `gorm:"primaryKey;autoIncrement:false"`
+	OwnerId       uint32    `gorm:"not null"`
+	TransactionId uuid.UUID `gorm:"type:uuid;not null"`
+	Expiration    time.Time `gorm:"not null;index"`
+}
+
+func (e baselineReservation) TableName() string {
+	return "cash_asset_reservations"
+}
+
+type baselineCompartmentTransaction struct {
+	TenantId        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	CompartmentId   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TransactionId   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Operation       string    `gorm:"primaryKey"`
+	CompartmentType byte      `gorm:"not null"`
+	AssetId         uint32    `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"not null"`
+}
+
+func (e baselineCompartmentTransaction) TableName() string {
+	return "cash_compartment_transactions"
+}
+
+type baselineAccountArchive struct {
+	Id        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId  uuid.UUID `gorm:"not null;index:idx_cash_account_archives_account"`
+	AccountId uint32    `gorm:"not null;index:idx_cash_account_archives_account"`
+	Snapshot  string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (e baselineAccountArchive) TableName() string {
+	return "cash_account_archives"
+}
+
+type baselineStarterGrant struct {
+	TenantId      uuid.UUID `gorm:"primaryKey;type:uuid"`
+	CharacterId   uint32    `gorm:"primaryKey"`
+	AccountId     uint32    `gorm:"not null"`
+	CompartmentId uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
+
+func (e baselineStarterGrant) TableName() string {
+	return "cash_starter_grants"
+}
+
+type baselineAuditEntry struct {
+	Id          uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId    uuid.UUID `gorm:"type:uuid;not null;index:idx_cash_audit_subject,priority:1"`
+	SubjectType string    `gorm:"not null;index:idx_cash_audit_subject,priority:2"`
+	SubjectId   uint32    `gorm:"not null;index:idx_cash_audit_subject,priority:3"`
+	Action      string    `gorm:"not null"`
+	Details     string    `gorm:"type:text;not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+func (e baselineAuditEntry) TableName() string {
+	return "cash_audit_entries"
+}
+
+type baselineOutboxMessage struct {
+	Id            uint64     `gorm:"primaryKey;autoIncrement:true"`
+	TenantId      uuid.UUID  `gorm:"type:uuid;not null"`
+	Topic         string     `gorm:"not null"`
+	Key           []byte     `gorm:""`
+	Value         []byte     `gorm:"not null"`
+	Headers       string     `gorm:"type:text;not null"`
+	Status        string     `gorm:"not null;index:idx_cash_outbox_pending,priority:1"`
+	Attempts      uint32     `gorm:"not null"`
+	LastError     string     `gorm:"type:text"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_cash_outbox_pending,priority:2"`
+	CreatedAt     time.Time  `gorm:"not null"`
+	SentAt        *time.Time `gorm:""`
+}
+
+func (e baselineOutboxMessage) TableName() string {
+	return "cash_outbox"
+}
+
+type baselineDeadLetter struct {
+	Id               uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	TenantId         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_cash_dead_letter_message,priority:1"`
+	Handler          string     `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:2"`
+	Topic            string     `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:3"`
+	MessagePartition int        `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:4"`
+	MessageOffset    int64      `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:5"`
+	Key              []byte     `gorm:""`
+	Value            []byte     `gorm:"not null"`
+	Headers          string     `gorm:"type:text;not null"`
+	Error            string     `gorm:"type:text;not null"`
+	Attempts         int        `gorm:"not null"`
+	Status           string     `gorm:"not null;index"`
+	CreatedAt        time.Time  `gorm:"not null"`
+	ReplayedAt       *time.Time `gorm:""`
+}
+
+func (e baselineDeadLetter) TableName() string {
+	return "cash_dead_letters"
+}
+
+func baselineTables() []interface{} {
+	return []interface{}{
+		&baselineWallet{},
+		&baselineWishlistItem{},
+		&baselineItem{},
+		&baselineCompartment{},
+		&baselineAsset{},
+		&baselineReservation{},
+		&baselineCompartmentTransaction{},
+		&baselineAccountArchive{},
+		&baselineStarterGrant{},
+		&baselineAuditEntry{},
+		&baselineOutboxMessage{},
+		&baselineDeadLetter{},
+	}
+}
+
+func baseline() database.Migration {
+	return database.Migration{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(db *gorm.DB) error {
+			if err := mergeDuplicates(db); err != nil {
+				return err
+			}
+			return db.AutoMigrate(baselineTables()...)
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(baselineTables()...)
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// mergeDuplicates folds the duplicate wallets and compartments left by the former, racy provisioning into one row each
+// A database adopted by the baseline may hold them, and the unique indexes of the baseline cannot be created until they are gone
+func mergeDuplicates(db *gorm.DB) error {
+	if err := mergeDuplicateWallets(db); err != nil {
+		return err
+	}
+	return mergeDuplicateCompartments(db)
+}
+
+type duplicateGroup struct {
+	TenantId  uuid.UUID
+	AccountId uint32
+	Type      byte
+}
+
+// mergeDuplicateWallets keeps the first wallet of an account, holding the balances of all of them
+func mergeDuplicateWallets(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&baselineWallet{}) {
+		return nil
+	}
+	var gs []duplicateGroup
+	err := db.Model(&baselineWallet{}).Select("tenant_id, account_id").Group("tenant_id, account_id").Having("COUNT(*) > 1").Scan(&gs).Error
+	if err != nil {
+		return err
+	}
+	for _, g := range gs {
+		var ws []baselineWallet
+		if err = db.Where("tenant_id = ? AND account_id = ?", g.TenantId, g.AccountId).Order("id").Find(&ws).Error; err != nil {
+			return err
+		}
+		keep := ws[0]
+		for _, w := range ws[1:] {
+			keep.Credit += w.Credit
+			keep.Points += w.Points
+			keep.Prepaid += w.Prepaid
+			if err = db.Delete(&baselineWallet{}, "id = ?", w.Id).Error; err != nil {
+				return err
+			}
+		}
+		err = db.Model(&baselineWallet{}).Where("id = ?", keep.Id).Updates(map[string]interface{}{"credit": keep.Credit, "points": keep.Points, "prepaid": keep.Prepaid}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDuplicateCompartments keeps the first compartment of each type of an account, moving the assets of the others into it
+// The kept compartment takes the largest capacity, so it holds every moved asset
+func mergeDuplicateCompartments(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&baselineCompartment{}) {
+		return nil
+	}
+	var gs []duplicateGroup
+	err := db.Model(&baselineCompartment{}).Select("tenant_id, account_id, type").Group("tenant_id, account_id, type").Having("COUNT(*) > 1").Scan(&gs).Error
+	if err != nil {
+		return err
+	}
+	for _, g := range gs {
+		var cs []baselineCompartment
+		if err = db.Where("tenant_id = ? AND account_id = ? AND type = ?", g.TenantId, g.AccountId, g.Type).Order("id").Find(&cs).Error; err != nil {
+			return err
+		}
+		keep := cs[0]
+		for _, c := range cs[1:] {
+			if c.Capacity > keep.Capacity {
+				keep.Capacity = c.Capacity
+			}
+			if db.Migrator().HasTable(&baselineAsset{}) {
+				if err = db.Model(&baselineAsset{}).Where("compartment_id = ?", c.Id).Update("compartment_id", keep.Id).Error; err != nil {
+					return err
+				}
+			}
+			if err = db.Delete(&baselineCompartment{}, "id = ?", c.Id).Error; err != nil {
+				return err
+			}
+		}
+		if err = db.Model(&baselineCompartment{}).Where("id = ?", keep.Id).Update("capacity", keep.Capacity).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import "atlas-cashshop/database"
+
+// cashIdSequenceMigration creates the source of cash ids, replacing random ids which were probed for collisions
+// Ids issued before the migration were drawn at random from the positive 63 bit range. The sequence counts up from 1, so it reaches an old id only by chance, which the unique cash id index then rejects
+func cashIdSequenceMigration() database.Migration {
+	return database.Migration{
+		Version: 3,
+		Name:    "cash id sequence",
+		Up: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: database.SQL("CREATE SEQUENCE IF NOT EXISTS cash_item_ids AS bigint START WITH 1"),
+			database.DialectSQLite: database.SQL(
+				"CREATE TABLE IF NOT EXISTS cash_item_id_counter (id INTEGER PRIMARY KEY CHECK (id = 1), value INTEGER NOT NULL)",
+				"INSERT OR IGNORE INTO cash_item_id_counter (id, value) VALUES (1, 0)",
+			),
+		}),
+		Down: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: database.SQL("DROP SEQUENCE IF EXISTS cash_item_ids"),
+			database.DialectSQLite:   database.SQL("DROP TABLE IF EXISTS cash_item_id_counter"),
+		}),
+	}
+}
@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"atlas-cashshop/database"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `usage:
+  migrate status                       list migrations and when they were applied
+  migrate up [-dry-run]                apply pending migrations
+  migrate down [-steps n] [-dry-run]   revert the last n applied migrations (default 1)`
+
+// Command runs the migrate command line against the configured database and returns the exit code
+func Command(l logrus.FieldLogger, args []string) int {
+	return command(l, os.Stdout, args)
+}
+
+func command(l logrus.FieldLogger, out io.Writer, args []string) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprintln(out, usage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	fs.SetOutput(out)
+	dryRun := fs.Bool("dry-run", false, "print the statements which would be executed without changing the schema")
+	steps := fs.Int("steps", 1, "number of migrations to revert")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	db, err := database.Open(l)
+	if err != nil {
+		return 1
+	}
+
+	switch args[0] {
+	case "status":
+		ss, err := database.Status(db, All())
+		if err != nil {
+			l.WithError(err).Errorf("Unable to retrieve migration status.")
+			return 1
+		}
+		for _, s := range ss {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.UTC().Format("2006-01-02T15:04:05Z")
+			}
+			_, _ = fmt.Fprintf(out, "%6d  %-40s  %s\n", s.Version, s.Name, applied)
+		}
+		return 0
+	case "up":
+		if *dryRun {
+			return printPlan(l, out)(database.PlanMigrate(db, All()))
+		}
+		if err = database.Migrate(l, db, All()); err != nil {
+			return 1
+		}
+		return 0
+	case "down":
+		if *dryRun {
+			return printPlan(l, out)(database.PlanRevert(db, All(), *steps))
+		}
+		if err = database.Revert(l, db, All(), *steps); err != nil {
+			return 1
+		}
+		return 0
+	default:
+		_, _ = fmt.Fprintln(out, usage)
+		return 2
+	}
+}
+
+func printPlan(l logrus.FieldLogger, out io.Writer) func(ps []database.PlannedMigration, err error) int {
+	return func(ps []database.PlannedMigration, err error) int {
+		if err != nil {
+			l.WithError(err).Errorf("Unable to plan migrations.")
+			return 1
+		}
+		if len(ps) == 0 {
+			_, _ = fmt.Fprintln(out, "Nothing to do.")
+			return 0
+		}
+		for _, p := range ps {
+			_, _ = fmt.Fprintf(out, "-- %d %s\n", p.Version, p.Name)
+			for _, s := range p.Statements {
+				_, _ = fmt.Fprintf(out, "%s;\n", s)
+			}
+		}
+		return 0
+	}
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import "atlas-cashshop/database"
+
+// deadLetterClaimMigration records when a replay claimed a dead letter, so a claim left by a stopped replica can be taken over
+func deadLetterClaimMigration() database.Migration {
+	return database.Migration{
+		Version: 6,
+		Name:    "dead letter claim",
+		Up: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: database.SQL("ALTER TABLE cash_dead_letters ADD COLUMN IF NOT EXISTS claimed_at timestamptz"),
+			database.DialectSQLite:   database.SQL("ALTER TABLE cash_dead_letters ADD COLUMN claimed_at datetime"),
+		}),
+		Down: database.SQL("ALTER TABLE cash_dead_letters DROP COLUMN claimed_at"),
+	}
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import "atlas-cashshop/database"
+
+// itemDiscardTimeMigration records when a player discarded an item, which is kept rather than deleted so support can trace it
+func itemDiscardTimeMigration() database.Migration {
+	return database.Migration{
+		Version: 5,
+		Name:    "item discard time",
+		Up: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: database.SQL("ALTER TABLE items ADD COLUMN IF NOT EXISTS discarded_at timestamptz"),
+			database.DialectSQLite:   database.SQL("ALTER TABLE items ADD COLUMN discarded_at datetime"),
+		}),
+		Down: database.SQL("ALTER TABLE items DROP COLUMN discarded_at"),
+	}
+}
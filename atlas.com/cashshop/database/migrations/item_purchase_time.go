@@ -0,0 +1,23 @@
+package migrations
+
+import "atlas-cashshop/database"
+
+// itemPurchaseTimeMigration records when each item was created, so assets can be listed in purchase order
+// Items which existed before the migration take the time it was applied, which places them before any later purchase
+func itemPurchaseTimeMigration() database.Migration {
+	return database.Migration{
+		Version: 4,
+		Name:    "item purchase time",
+		Up: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: database.SQL(
+				"ALTER TABLE items ADD COLUMN IF NOT EXISTS purchased_at timestamptz",
+				"UPDATE items SET purchased_at = now() WHERE purchased_at IS NULL",
+			),
+			database.DialectSQLite: database.SQL(
+				"ALTER TABLE items ADD COLUMN purchased_at datetime",
+				"UPDATE items SET purchased_at = CURRENT_TIMESTAMP WHERE purchased_at IS NULL",
+			),
+		}),
+		Down: database.SQL("ALTER TABLE items DROP COLUMN purchased_at"),
+	}
+}
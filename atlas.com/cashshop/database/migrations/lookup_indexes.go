@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"atlas-cashshop/database"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// lookupIndexes are created by version 2. Wallets and compartments were already unique per account from the baseline
+// Creating a unique index fails while duplicates exist, which need to be resolved by hand before the migration is retried
+var lookupIndexes = []struct {
+	name    string
+	unique  bool
+	table   string
+	columns string
+}{
+	{"idx_items_tenant_cash", true, "items", "tenant_id, cash_id"},
+	{"idx_items_tenant_purchased_by", false, "items", "tenant_id, purchased_by"},
+	{"idx_cash_assets_tenant_compartment_item", true, "cash_assets", "tenant_id, compartment_id, item_id"},
+	{"idx_cash_assets_tenant_item", false, "cash_assets", "tenant_id, item_id"},
+	{"idx_wishlist_items_tenant_character", false, "wishlist_items", "tenant_id, character_id"},
+}
+
+// createLookupIndexes renders the statements creating each lookup index. modifier is placed between INDEX and the index name
+func createLookupIndexes(modifier string) []string {
+	result := make([]string, 0, len(lookupIndexes))
+	for _, i := range lookupIndexes {
+		s := "CREATE INDEX "
+		if i.unique {
+			s = "CREATE UNIQUE INDEX "
+		}
+		result = append(result, s+strings.TrimSpace(modifier+" IF NOT EXISTS "+i.name)+" ON "+i.table+" ("+i.columns+")")
+	}
+	return result
+}
+
+// createLookupIndexesConcurrently builds each lookup index concurrently on Postgres
+// A concurrent build which fails leaves an invalid index behind, which IF NOT EXISTS would skip, so it is dropped and built again
+func createLookupIndexesConcurrently(db *gorm.DB) error {
+	statements := createLookupIndexes("CONCURRENTLY")
+	for n, i := range lookupIndexes {
+		var invalid int64
+		if err := db.Raw("SELECT COUNT(*) FROM pg_index WHERE indexrelid = to_regclass(?) AND NOT indisvalid", i.name).Scan(&invalid).Error; err != nil {
+			return err
+		}
+		if invalid > 0 {
+			if err := db.Exec("DROP INDEX CONCURRENTLY IF EXISTS " + i.name).Error; err != nil {
+				return err
+			}
+		}
+		if err := db.Exec(statements[n]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropLookupIndexes(modifier string) []string {
+	result := make([]string, 0, len(lookupIndexes))
+	for _, i := range lookupIndexes {
+		result = append(result, "DROP INDEX "+strings.TrimSpace(modifier+" IF EXISTS "+i.name))
+	}
+	return result
+}
+
+// lookupIndexesMigration indexes the tenant scoped lookups and enforces a unique cash id and one asset per item in a compartment
+// On Postgres the indexes are built concurrently so the tables stay writable. An index left invalid by a failed build is rebuilt when the migration is retried
+func lookupIndexesMigration() database.Migration {
+	return database.Migration{
+		Version: 2,
+		Name:    "lookup indexes",
+		Up: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: createLookupIndexesConcurrently,
+			database.DialectSQLite:   database.SQL(createLookupIndexes("")...),
+		}),
+		Down: database.Dialects(map[string]database.Step{
+			database.DialectPostgres: database.SQL(dropLookupIndexes("CONCURRENTLY")...),
+			database.DialectSQLite:   database.SQL(dropLookupIndexes("")...),
+		}),
+		NoTransaction: true,
+	}
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import "atlas-cashshop/database"
+
+// All returns the schema migrations of the service in version order
+// A migration is never edited once released. Schema changes are made by appending a migration with the next version
+func All() []database.Migration {
+	return []database.Migration{
+		baseline(),
+		lookupIndexesMigration(),
+		cashIdSequenceMigration(),
+		itemPurchaseTimeMigration(),
+		itemDiscardTimeMigration(),
+		deadLetterClaimMigration(),
+	}
+}
@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"atlas-cashshop/database"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func testDatabase(t *testing.T) *gorm.DB {
+	db, err := database.Open(logrus.New(), database.SetDialector(database.SQLite("file:"+uuid.NewString()+"?mode=memory&cache=shared")), database.SetMigrations(All()...))
+	if err != nil {
+		t.Fatalf("Unable to open database: %v", err)
+	}
+	return db
+}
+
+func TestAll(t *testing.T) {
+	db := testDatabase(t)
+
+	ss, err := database.Status(db, All())
+	if err != nil {
+		t.Fatalf("Unable to retrieve status: %v", err)
+	}
+	for _, s := range ss {
+		if s.AppliedAt == nil {
+			t.Errorf("Expected migration [%d] [%s] to be applied", s.Version, s.Name)
+		}
+	}
+	for _, i := range lookupIndexes {
+		if !db.Migrator().HasIndex(i.table, i.name) {
+			t.Errorf("Expected index [%s] on [%s]", i.name, i.table)
+		}
+	}
+
+	if err = database.Revert(logrus.New(), db, All(), len(All())-1); err != nil {
+		t.Fatalf("Unable to revert to the baseline: %v", err)
+	}
+	for _, i := range lookupIndexes {
+		if db.Migrator().HasIndex(i.table, i.name) {
+			t.Errorf("Expected index [%s] on [%s] to be dropped", i.name, i.table)
+		}
+	}
+}
+
+func TestUniqueCashId(t *testing.T) {
+	db := testDatabase(t)
+	tenantId := uuid.New()
+
+	item := func(cashId int64) *baselineItem {
+		return &baselineItem{TenantId: tenantId, CashId: cashId, TemplateId: 5000000, Quantity: 1, Expiration: time.Now()}
+	}
+	if err := db.Create(item(1)).Error; err != nil {
+		t.Fatalf("Unable to create item: %v", err)
+	}
+	if err := db.Create(item(1)).Error; !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("Expected a duplicated key creating a second item with the same cash id, got %v", err)
+	}
+	if err := db.Create(&baselineItem{TenantId: uuid.New(), CashId: 1, Expiration: time.Now()}).Error; err != nil {
+		t.Errorf("Expected another tenant to reuse the cash id, got %v", err)
+	}
+}
+
+func TestBaselineMergesDuplicates(t *testing.T) {
+	db, err := database.Open(logrus.New(), database.SetDialector(database.SQLite("file:"+uuid.NewString()+"?mode=memory&cache=shared")))
+	if err != nil {
+		t.Fatalf("Unable to open database: %v", err)
+	}
+	// The former AutoMigrate created these tables without unique indexes
+	err = database.SQL(
+		"CREATE TABLE accounts (id text PRIMARY KEY, tenant_id text NOT NULL, account_id integer NOT NULL, credit integer NOT NULL DEFAULT 0, points integer NOT NULL DEFAULT 0, prepaid integer NOT NULL DEFAULT 0)",
+		"CREATE TABLE cash_compartments (id text PRIMARY KEY, tenant_id text NOT NULL, account_id integer NOT NULL, type integer NOT NULL, capacity integer NOT NULL DEFAULT 55)",
+		"CREATE TABLE cash_assets (id text PRIMARY KEY, tenant_id text NOT NULL, compartment_id text NOT NULL, item_id integer NOT NULL)",
+	)(db)
+	if err != nil {
+		t.Fatalf("Unable to create former tables: %v", err)
+	}
+
+	tenantId := uuid.New()
+	first, second := uuid.MustParse("00000000-0000-0000-0000-000000000001"), uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	for _, w := range []baselineWallet{{Id: first, TenantId: tenantId, AccountId: 1, Credit: 100}, {Id: second, TenantId: tenantId, AccountId: 1, Credit: 50, Points: 5}} {
+		if err = db.Create(&w).Error; err != nil {
+			t.Fatalf("Unable to create wallet: %v", err)
+		}
+	}
+	for _, c := range []baselineCompartment{{Id: first, TenantId: tenantId, AccountId: 1, Type: 1, Capacity: 55}, {Id: second, TenantId: tenantId, AccountId: 1, Type: 1, Capacity: 60}} {
+		if err = db.Create(&c).Error; err != nil {
+			t.Fatalf("Unable to create compartment: %v", err)
+		}
+	}
+	if err = db.Create(&baselineAsset{Id: uuid.New(), TenantId: tenantId, CompartmentId: second, ItemId: 1}).Error; err != nil {
+		t.Fatalf("Unable to create asset: %v", err)
+	}
+
+	if err = database.Migrate(logrus.New(), db, All()); err != nil {
+		t.Fatalf("Unable to migrate database holding duplicates: %v", err)
+	}
+
+	var ws []baselineWallet
+	if err = db.Find(&ws).Error; err != nil {
+		t.Fatalf("Unable to retrieve wallets: %v", err)
+	}
+	if len(ws) != 1 || ws[0].Id != first || ws[0].Credit != 150 || ws[0].Points != 5 {
+		t.Errorf("Expected one wallet holding both balances, got %+v", ws)
+	}
+	var cs []baselineCompartment
+	if err = db.Find(&cs).Error; err != nil {
+		t.Fatalf("Unable to retrieve compartments: %v", err)
+	}
+	if len(cs) != 1 || cs[0].Id != first || cs[0].Capacity != 60 {
+		t.Errorf("Expected one compartment with the largest capacity, got %+v", cs)
+	}
+	var as []baselineAsset
+	if err = db.Find(&as).Error; err != nil {
+		t.Fatalf("Unable to retrieve assets: %v", err)
+	}
+	if len(as) != 1 || as[0].CompartmentId != first {
+		t.Errorf("Expected the asset to move into the kept compartment, got %+v", as)
+	}
+}
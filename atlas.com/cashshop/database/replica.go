@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// EnvReplicaHosts lists the hosts of Postgres read replicas, separated by commas. Replicas share the credentials, port and database name of the primary
+	EnvReplicaHosts = "DB_REPLICA_HOSTS"
+
+	replicasPluginName = "atlas:replicas"
+)
+
+// replicas is registered on the primary connection, so every session derived from it can find the replica connections
+type replicas struct {
+	dbs  []*gorm.DB
+	next atomic.Uint64
+}
+
+func (r *replicas) Name() string {
+	return replicasPluginName
+}
+
+// Initialize marks the request of the context as having written whenever a statement modifies the primary
+func (r *replicas) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("atlas:mark_written", markWritten); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("atlas:mark_written", markWritten); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("atlas:mark_written", markWritten); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("atlas:mark_written", markWritten)
+}
+
+func (r *replicas) pick() *gorm.DB {
+	return r.dbs[r.next.Add(1)%uint64(len(r.dbs))]
+}
+
+func markWritten(db *gorm.DB) {
+	if w, ok := db.Statement.Context.Value(writesKey{}).(*atomic.Bool); ok {
+		w.Store(true)
+	}
+}
+
+type writesKey struct{}
+
+// WithReadYourWrites starts tracking writes for a request. Once the request writes to the primary, Read keeps its reads on the primary as well
+// Writes are only seen when made with the context, such as those made through outbox.Emit
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writesKey{}).(*atomic.Bool); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, writesKey{}, &atomic.Bool{})
+}
+
+func hasWritten(ctx context.Context) bool {
+	w, ok := ctx.Value(writesKey{}).(*atomic.Bool)
+	return ok && w.Load()
+}
+
+// Read returns the connection a query-only provider should read from
+// A replica serves the read unless db is a transaction, the request of the context has written, or no replica is configured
+func Read(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if isTransaction(db) || hasWritten(ctx) {
+		return db
+	}
+	r, ok := db.Config.Plugins[replicasPluginName].(*replicas)
+	if !ok || len(r.dbs) == 0 {
+		return db
+	}
+	return r.pick().WithContext(ctx)
+}
+
+// ReplicaHosts returns the configured read replica hosts
+func ReplicaHosts() []string {
+	var result []string
+	v, ok := os.LookupEnv(EnvReplicaHosts)
+	if !ok {
+		return nil
+	}
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			result = append(result, h)
+		}
+	}
+	return result
+}
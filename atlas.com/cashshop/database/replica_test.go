@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func memoryDSN() string {
+	return "file:" + uuid.NewString() + "?mode=memory&cache=shared"
+}
+
+// readWidget reports which database served the read, by the name of the widget each holds
+func readWidget(t *testing.T, db *gorm.DB) string {
+	var w testWidget
+	if err := db.First(&w, 1).Error; err != nil {
+		t.Fatalf("Unable to read widget: %v", err)
+	}
+	return w.Name
+}
+
+func TestRead(t *testing.T) {
+	replicaDSN := memoryDSN()
+	replica, err := Open(logrus.New(), SetDialector(SQLite(replicaDSN)), SetMigrations(testMigrations()...))
+	if err != nil {
+		t.Fatalf("Unable to open replica: %v", err)
+	}
+	if err = replica.Create(&testWidget{Id: 1, Name: "replica"}).Error; err != nil {
+		t.Fatalf("Unable to create replica widget: %v", err)
+	}
+	db, err := Open(logrus.New(), SetDialector(SQLite(memoryDSN())), SetReplicas(SQLite(replicaDSN)), SetMigrations(testMigrations()...))
+	if err != nil {
+		t.Fatalf("Unable to open primary: %v", err)
+	}
+	if err = db.Create(&testWidget{Id: 1, Name: "primary"}).Error; err != nil {
+		t.Fatalf("Unable to create primary widget: %v", err)
+	}
+
+	ctx := WithReadYourWrites(context.Background())
+	if n := readWidget(t, Read(ctx, db)); n != "replica" {
+		t.Errorf("Expected a read to be served by the replica, got [%s]", n)
+	}
+	_ = db.Transaction(func(tx *gorm.DB) error {
+		if n := readWidget(t, Read(ctx, tx)); n != "primary" {
+			t.Errorf("Expected a read within a transaction to be served by the primary, got [%s]", n)
+		}
+		return nil
+	})
+
+	if err = db.WithContext(ctx).Create(&testWidget{Id: 2, Name: "written"}).Error; err != nil {
+		t.Fatalf("Unable to create widget: %v", err)
+	}
+	if n := readWidget(t, Read(ctx, db)); n != "primary" {
+		t.Errorf("Expected a read after a write to be served by the primary, got [%s]", n)
+	}
+	if n := readWidget(t, Read(WithReadYourWrites(context.Background()), db)); n != "replica" {
+		t.Errorf("Expected the read of another request to be served by the replica, got [%s]", n)
+	}
+}
+
+func TestReadWithoutReplicas(t *testing.T) {
+	db := testDatabase(t)
+	if r := Read(context.Background(), db); r != db {
+		t.Errorf("Expected reads to be served by the primary when no replica is configured")
+	}
+}
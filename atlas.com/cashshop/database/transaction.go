@@ -17,6 +17,11 @@ func ExecuteTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
 }
 
 // isTransaction checks if the *gorm.DB is already in a transaction
+// The root connection also carries a ConnPool, so only a pool which can commit identifies a transaction
 func isTransaction(db *gorm.DB) bool {
-	return db.Statement != nil && db.Statement.ConnPool != nil
+	if db.Statement == nil || db.Statement.ConnPool == nil {
+		return false
+	}
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
 }
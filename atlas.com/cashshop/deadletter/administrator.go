@@ -0,0 +1,66 @@
+package deadletter
+
+import (
+	"atlas-cashshop/kafka/message/deadletter"
+	"encoding/json"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// createEntity records a dead letter unless the handler already dead lettered the same message
+func createEntity(db *gorm.DB, tenantId uuid.UUID, e deadletter.Event) error {
+	hs, err := json.Marshal(e.Headers)
+	if err != nil {
+		return err
+	}
+	entity := Entity{
+		Id:               uuid.New(),
+		TenantId:         tenantId,
+		Handler:          e.Handler,
+		Topic:            e.Topic,
+		MessagePartition: e.Partition,
+		MessageOffset:    e.Offset,
+		Key:              e.Key,
+		Value:            e.Value,
+		Headers:          string(hs),
+		Error:            e.Error,
+		Attempts:         e.Attempts,
+		Status:           string(StatusPending),
+		CreatedAt:        e.FailedAt,
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity).Error
+}
+
+// claimEntity marks a pending dead letter REPLAYING, reporting whether it was claimed
+// A claim made before staleBefore is taken over, as the replica which made it stopped before recording the outcome
+func claimEntity(db *gorm.DB, tenantId uuid.UUID, id uuid.UUID, now time.Time, staleBefore time.Time) (bool, error) {
+	result := db.Model(&Entity{}).
+		Where("tenant_id = ? AND id = ?", tenantId, id).
+		Where("status = ? OR (status = ? AND claimed_at < ?)", string(StatusPending), string(StatusReplaying), staleBefore).
+		Updates(map[string]interface{}{
+			"status":     string(StatusReplaying),
+			"claimed_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// markReplayed records that a replay of the dead letter succeeded
+func markReplayed(db *gorm.DB, tenantId uuid.UUID, id uuid.UUID, attempts int, now time.Time) error {
+	return db.Model(&Entity{}).Where("tenant_id = ? AND id = ?", tenantId, id).Updates(map[string]interface{}{
+		"status":      string(StatusReplayed),
+		"attempts":    attempts,
+		"replayed_at": now,
+	}).Error
+}
+
+// markFailed records that a replay of the dead letter failed again and releases it for another replay
+func markFailed(db *gorm.DB, tenantId uuid.UUID, id uuid.UUID, attempts int, cause error) error {
+	return db.Model(&Entity{}).Where("tenant_id = ? AND id = ?", tenantId, id).Updates(map[string]interface{}{
+		"status":     string(StatusPending),
+		"attempts":   attempts,
+		"error":      cause.Error(),
+		"claimed_at": nil,
+	}).Error
+}
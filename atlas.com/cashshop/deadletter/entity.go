@@ -0,0 +1,57 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Entity represents a consumed message whose handler failed after exhausting its retries
+// A message is recorded once per handler, so redelivery of the dead letter event is harmless
+type Entity struct {
+	Id               uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	TenantId         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_cash_dead_letter_message,priority:1"`
+	Handler          string     `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:2"`
+	Topic            string     `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:3"`
+	MessagePartition int        `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:4"`
+	MessageOffset    int64      `gorm:"not null;uniqueIndex:idx_cash_dead_letter_message,priority:5"`
+	Key              []byte     `gorm:""`
+	Value            []byte     `gorm:"not null"`
+	Headers          string     `gorm:"type:text;not null"`
+	Error            string     `gorm:"type:text;not null"`
+	Attempts         int        `gorm:"not null"`
+	Status           string     `gorm:"not null;index"`
+	CreatedAt        time.Time  `gorm:"not null"`
+	ReplayedAt       *time.Time `gorm:""`
+	ClaimedAt        *time.Time `gorm:""`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_dead_letters"
+}
+
+// Make converts an Entity to a Model
+func Make(e Entity) (Model, error) {
+	headers := make(map[string]string)
+	if e.Headers != "" {
+		if err := json.Unmarshal([]byte(e.Headers), &headers); err != nil {
+			return Model{}, err
+		}
+	}
+	return Model{
+		id:         e.Id,
+		handler:    e.Handler,
+		topic:      e.Topic,
+		partition:  e.MessagePartition,
+		offset:     e.MessageOffset,
+		key:        e.Key,
+		value:      e.Value,
+		headers:    headers,
+		error:      e.Error,
+		attempts:   e.Attempts,
+		status:     Status(e.Status),
+		createdAt:  e.CreatedAt,
+		replayedAt: e.ReplayedAt,
+	}, nil
+}
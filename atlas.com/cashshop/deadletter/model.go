@@ -0,0 +1,97 @@
+package deadletter
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Status identifies whether a dead letter still awaits recovery
+type Status string
+
+const (
+	StatusPending   = Status("PENDING")
+	StatusReplaying = Status("REPLAYING")
+	StatusReplayed  = Status("REPLAYED")
+)
+
+// Model represents a dead lettered message
+type Model struct {
+	id         uuid.UUID
+	handler    string
+	topic      string
+	partition  int
+	offset     int64
+	key        []byte
+	value      []byte
+	headers    map[string]string
+	error      string
+	attempts   int
+	status     Status
+	createdAt  time.Time
+	replayedAt *time.Time
+}
+
+// Id returns the dead letter ID
+func (m Model) Id() uuid.UUID {
+	return m.id
+}
+
+// Handler returns the name of the handler which failed
+func (m Model) Handler() string {
+	return m.handler
+}
+
+// Topic returns the topic the message was consumed from
+func (m Model) Topic() string {
+	return m.topic
+}
+
+// Partition returns the partition the message was consumed from
+func (m Model) Partition() int {
+	return m.partition
+}
+
+// Offset returns the offset of the message within its partition
+func (m Model) Offset() int64 {
+	return m.offset
+}
+
+// Key returns the original message key
+func (m Model) Key() []byte {
+	return m.key
+}
+
+// Value returns the original message value
+func (m Model) Value() []byte {
+	return m.value
+}
+
+// Headers returns the original message headers
+func (m Model) Headers() map[string]string {
+	return m.headers
+}
+
+// Error returns the last error the handler reported
+func (m Model) Error() string {
+	return m.error
+}
+
+// Attempts returns how many times the handler was run for the message, replays included
+func (m Model) Attempts() int {
+	return m.attempts
+}
+
+// Status returns whether the dead letter was recovered
+func (m Model) Status() Status {
+	return m.status
+}
+
+// CreatedAt returns when the message was dead lettered
+func (m Model) CreatedAt() time.Time {
+	return m.createdAt
+}
+
+// ReplayedAt returns when the message was successfully replayed, if it was
+func (m Model) ReplayedAt() *time.Time {
+	return m.replayedAt
+}
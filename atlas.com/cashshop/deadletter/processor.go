@@ -0,0 +1,167 @@
+package deadletter
+
+import (
+	"atlas-cashshop/kafka/message/deadletter"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrAlreadyReplayed = errors.New("dead letter already replayed")
+var ErrUnknownHandler = errors.New("no handler registered for dead letter")
+var ErrReplayFailed = errors.New("dead letter replay failed")
+var ErrReplayInProgress = errors.New("dead letter replay in progress")
+
+// ReplayClaimTimeout is how long a replay holds its claim on a dead letter before another replay may take it over
+const ReplayClaimTimeout = 5 * time.Minute
+
+// Processor records, retrieves and replays dead letters
+type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
+	ByIdProvider(id uuid.UUID) model.Provider[Model]
+	GetById(id uuid.UUID) (Model, error)
+	AllProvider(status Status) model.Provider[[]Model]
+	GetAll(status Status) ([]Model, error)
+	Record(e deadletter.Event) error
+	Replay(id uuid.UUID) (Model, error)
+}
+
+// ProcessorImpl implements the Processor interface
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	db  *gorm.DB
+	t   tenant.Model
+}
+
+// NewProcessor creates a new Processor
+func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		db:  db,
+		t:   tenant.MustFromContext(ctx),
+	}
+}
+
+// WithTransaction returns a new Processor with the given transaction
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   p.l,
+		ctx: p.ctx,
+		db:  tx,
+		t:   p.t,
+	}
+}
+
+// ByIdProvider retrieves a dead letter by ID
+func (p *ProcessorImpl) ByIdProvider(id uuid.UUID) model.Provider[Model] {
+	return model.Map(Make)(getByIdProvider(p.t.Id())(id)(p.db))
+}
+
+// GetById retrieves a dead letter by ID
+func (p *ProcessorImpl) GetById(id uuid.UUID) (Model, error) {
+	return p.ByIdProvider(id)()
+}
+
+// AllProvider retrieves the dead letters of the tenant with a status, or all of them when the status is empty
+func (p *ProcessorImpl) AllProvider(status Status) model.Provider[[]Model] {
+	return model.SliceMap(Make)(getAllProvider(p.t.Id())(status)(p.db))(model.ParallelMap())
+}
+
+// GetAll retrieves the dead letters of the tenant with a status, or all of them when the status is empty
+func (p *ProcessorImpl) GetAll(status Status) ([]Model, error) {
+	return p.AllProvider(status)()
+}
+
+// Record stores a dead letter. Recording the same message for the same handler again has no effect
+func (p *ProcessorImpl) Record(e deadletter.Event) error {
+	err := createEntity(p.db, p.t.Id(), e)
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to record dead letter of handler [%s] for [%s] partition [%d] offset [%d].", e.Handler, e.Topic, e.Partition, e.Offset)
+	}
+	return err
+}
+
+// Replay runs the handler which dead lettered the message once more with the original message and headers
+// The message is handed to this service's handler directly rather than republished, so other consumers of the topic do not see it twice
+// The dead letter is claimed before the handler runs, so concurrent replays cannot apply the same message twice
+// The handler runs outside of any transaction, as it opens its own
+func (p *ProcessorImpl) Replay(id uuid.UUID) (Model, error) {
+	m, err := p.GetById(id)
+	if err != nil {
+		return Model{}, err
+	}
+	if m.Status() == StatusReplayed {
+		return Model{}, ErrAlreadyReplayed
+	}
+	r, ok := getReplayer(m.Handler())
+	if !ok {
+		return Model{}, ErrUnknownHandler
+	}
+	if err = p.claim(id); err != nil {
+		return Model{}, err
+	}
+
+	headers := make([]kafka.Header, 0, len(m.Headers()))
+	for k, v := range m.Headers() {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	msg := kafka.Message{
+		Topic:     m.Topic(),
+		Partition: m.Partition(),
+		Offset:    m.Offset(),
+		Key:       m.Key(),
+		Value:     m.Value(),
+		Headers:   headers,
+	}
+
+	p.l.Infof("Replaying dead letter [%s] of handler [%s].", m.Id(), m.Handler())
+	cause := r(p.l, p.ctx, msg)
+	if cause != nil {
+		p.l.WithError(cause).Errorf("Replay of dead letter [%s] failed.", m.Id())
+		err = markFailed(p.db, p.t.Id(), m.Id(), m.Attempts()+1, cause)
+	} else {
+		err = markReplayed(p.db, p.t.Id(), m.Id(), m.Attempts()+1, time.Now())
+	}
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to record the outcome of replaying dead letter [%s]. It stays claimed until the claim expires.", m.Id())
+		return Model{}, err
+	}
+
+	m, err = p.GetById(id)
+	if err != nil {
+		return Model{}, err
+	}
+	if cause != nil {
+		return m, fmt.Errorf("%w: %w", ErrReplayFailed, cause)
+	}
+	return m, nil
+}
+
+// claim marks the dead letter REPLAYING and commits, so another replay of it is refused while the handler runs
+func (p *ProcessorImpl) claim(id uuid.UUID) error {
+	now := time.Now()
+	claimed, err := claimEntity(p.db, p.t.Id(), id, now, now.Add(-ReplayClaimTimeout))
+	if err != nil {
+		return err
+	}
+	if claimed {
+		return nil
+	}
+	m, err := p.GetById(id)
+	if err != nil {
+		return err
+	}
+	if m.Status() == StatusReplayed {
+		return ErrAlreadyReplayed
+	}
+	return ErrReplayInProgress
+}
@@ -0,0 +1,127 @@
+package deadletter_test
+
+import (
+	"atlas-cashshop/database"
+	"atlas-cashshop/database/fixture"
+	"atlas-cashshop/deadletter"
+	deadletterMessage "atlas-cashshop/kafka/message/deadletter"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// record dead letters a message for handler and returns it
+func record(t *testing.T, l logrus.FieldLogger, ctx context.Context, db *gorm.DB, handler string) deadletter.Model {
+	p := deadletter.NewProcessor(l, ctx, db)
+	err := p.Record(deadletterMessage.Event{
+		Handler:  handler,
+		Topic:    "COMMAND_TOPIC_TEST",
+		Value:    []byte("{}"),
+		Headers:  map[string]string{},
+		Error:    "handler failed",
+		Attempts: 5,
+		FailedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Unable to record dead letter: %v", err)
+	}
+	ms, err := p.GetAll(deadletter.StatusPending)
+	if err != nil || len(ms) != 1 {
+		t.Fatalf("Expected 1 pending dead letter, got [%d]: %v", len(ms), err)
+	}
+	return ms[0]
+}
+
+// replay runs the replay in the background, so a replay which blocks on the database fails the test rather than hanging it
+func replay(t *testing.T, p deadletter.Processor, id uuid.UUID) (deadletter.Model, error) {
+	type result struct {
+		m   deadletter.Model
+		err error
+	}
+	c := make(chan result, 1)
+	go func() {
+		m, err := p.Replay(id)
+		c <- result{m, err}
+	}()
+	select {
+	case r := <-c:
+		return r.m, r.err
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Replay of dead letter [%s] did not finish", id)
+		return deadletter.Model{}, nil
+	}
+}
+
+func TestReplayRunsHandlerWithItsOwnTransaction(t *testing.T) {
+	db := fixture.Open(t)
+	l := logrus.New()
+	ctx := fixture.Tenant(t)
+	handler := "test_replay_" + uuid.NewString()
+	deadletter.RegisterReplayer(handler, func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) error {
+		return database.ExecuteTransaction(db.WithContext(ctx), func(tx *gorm.DB) error {
+			return tx.Exec("SELECT 1").Error
+		})
+	})
+	dl := record(t, l, ctx, db, handler)
+
+	m, err := replay(t, deadletter.NewProcessor(l, ctx, db), dl.Id())
+	if err != nil {
+		t.Fatalf("Unable to replay dead letter: %v", err)
+	}
+	if m.Status() != deadletter.StatusReplayed || m.Attempts() != 6 || m.ReplayedAt() == nil {
+		t.Errorf("Expected dead letter to be replayed on attempt 6, got [%s] after [%d] attempts", m.Status(), m.Attempts())
+	}
+	if _, err = replay(t, deadletter.NewProcessor(l, ctx, db), dl.Id()); !errors.Is(err, deadletter.ErrAlreadyReplayed) {
+		t.Errorf("Expected a second replay to be refused, got [%v]", err)
+	}
+}
+
+func TestReplayFailureReleasesClaim(t *testing.T) {
+	db := fixture.Open(t)
+	l := logrus.New()
+	ctx := fixture.Tenant(t)
+	handler := "test_replay_" + uuid.NewString()
+	cause := errors.New("still failing")
+	deadletter.RegisterReplayer(handler, func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) error {
+		return cause
+	})
+	dl := record(t, l, ctx, db, handler)
+
+	m, err := replay(t, deadletter.NewProcessor(l, ctx, db), dl.Id())
+	if !errors.Is(err, deadletter.ErrReplayFailed) || !errors.Is(err, cause) {
+		t.Fatalf("Expected replay to fail with the cause, got [%v]", err)
+	}
+	if m.Status() != deadletter.StatusPending || m.Attempts() != 6 || m.Error() != cause.Error() {
+		t.Errorf("Expected failed replay to leave the dead letter pending with its error, got [%s] [%s] after [%d] attempts", m.Status(), m.Error(), m.Attempts())
+	}
+	if _, err = replay(t, deadletter.NewProcessor(l, ctx, db), dl.Id()); !errors.Is(err, deadletter.ErrReplayFailed) {
+		t.Errorf("Expected the dead letter to be replayable again, got [%v]", err)
+	}
+}
+
+func TestReplayRefusedWhileInProgress(t *testing.T) {
+	db := fixture.Open(t)
+	l := logrus.New()
+	ctx := fixture.Tenant(t)
+	handler := "test_replay_" + uuid.NewString()
+	var id uuid.UUID
+	var concurrent error
+	deadletter.RegisterReplayer(handler, func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) error {
+		_, concurrent = deadletter.NewProcessor(l, ctx, db).Replay(id)
+		return nil
+	})
+	id = record(t, l, ctx, db, handler).Id()
+
+	if _, err := replay(t, deadletter.NewProcessor(l, ctx, db), id); err != nil {
+		t.Fatalf("Unable to replay dead letter: %v", err)
+	}
+	if !errors.Is(concurrent, deadletter.ErrReplayInProgress) {
+		t.Errorf("Expected a replay during another to be refused, got [%v]", concurrent)
+	}
+}
@@ -0,0 +1,34 @@
+package deadletter
+
+import (
+	"atlas-cashshop/database"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// getByIdProvider retrieves a dead letter by ID
+func getByIdProvider(tenantId uuid.UUID) func(id uuid.UUID) database.EntityProvider[Entity] {
+	return func(id uuid.UUID) database.EntityProvider[Entity] {
+		return func(db *gorm.DB) model.Provider[Entity] {
+			return database.Query[Entity](db, &Entity{TenantId: tenantId, Id: id})
+		}
+	}
+}
+
+// getAllProvider retrieves the dead letters of a tenant, newest first. An empty status matches every dead letter
+func getAllProvider(tenantId uuid.UUID) func(status Status) database.EntityProvider[[]Entity] {
+	return func(status Status) database.EntityProvider[[]Entity] {
+		return func(db *gorm.DB) model.Provider[[]Entity] {
+			return func() ([]Entity, error) {
+				var entities []Entity
+				q := db.Where("tenant_id = ?", tenantId)
+				if status != "" {
+					q = q.Where("status = ?", string(status))
+				}
+				result := q.Order("created_at DESC").Find(&entities)
+				return entities, result.Error
+			}
+		}
+	}
+}
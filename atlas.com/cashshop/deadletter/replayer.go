@@ -0,0 +1,32 @@
+package deadletter
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+// Replayer runs a handler once against a message, reporting whether it failed
+type Replayer func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) error
+
+var replayers = &registry{replayers: make(map[string]Replayer)}
+
+type registry struct {
+	mu        sync.RWMutex
+	replayers map[string]Replayer
+}
+
+// RegisterReplayer makes a handler available for replaying the messages it dead lettered
+func RegisterReplayer(handler string, r Replayer) {
+	replayers.mu.Lock()
+	defer replayers.mu.Unlock()
+	replayers.replayers[handler] = r
+}
+
+func getReplayer(handler string) (Replayer, bool) {
+	replayers.mu.RLock()
+	defer replayers.mu.RUnlock()
+	r, ok := replayers.replayers[handler]
+	return r, ok
+}
@@ -0,0 +1,102 @@
+package deadletter
+
+import (
+	"atlas-cashshop/rest"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/Chronicle20/atlas-rest/server"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// InitResource initializes the dead letter resources
+func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
+	return func(db *gorm.DB) server.RouteInitializer {
+		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(ErrAlreadyReplayed, http.StatusConflict, "ALREADY_REPLAYED", "Dead letter already replayed")
+			rest.RegisterError(ErrUnknownHandler, http.StatusUnprocessableEntity, "UNKNOWN_HANDLER", "No handler registered for dead letter")
+			rest.RegisterError(ErrReplayInProgress, http.StatusConflict, "REPLAY_IN_PROGRESS", "Dead letter replay in progress")
+			rest.RegisterError(ErrReplayFailed, http.StatusBadGateway, "REPLAY_FAILED", "Dead letter replay failed")
+
+			register := rest.RegisterHandler(l)(si)
+			r := router.PathPrefix("/cash-shop/dead-letters").Subrouter()
+			r.HandleFunc("", register("get_dead_letters", handleGetDeadLetters(db))).Methods(http.MethodGet)
+			r.HandleFunc("/{deadLetterId}", register("get_dead_letter", handleGetDeadLetter(db))).Methods(http.MethodGet)
+			r.HandleFunc("/{deadLetterId}/replay", register("replay_dead_letter", handleReplayDeadLetter(db))).Methods(http.MethodPost)
+		}
+	}
+}
+
+// handleGetDeadLetters handles the GET request for dead letters, optionally filtered by status
+func handleGetDeadLetters(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			status := Status(r.URL.Query().Get("status"))
+			if status != "" && status != StatusPending && status != StatusReplaying && status != StatusReplayed {
+				rest.WriteError(d.Logger())(w)(rest.InvalidParameter("status").WithDetail("status must be PENDING, REPLAYING or REPLAYED"))
+				return
+			}
+
+			res, err := model.SliceMap(Transform)(NewProcessor(d.Logger(), d.Context(), db).AllProvider(status))(model.ParallelMap())()
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Creating REST model.")
+				rest.WriteError(d.Logger())(w)(err)
+				return
+			}
+
+			query := r.URL.Query()
+			queryParams := jsonapi.ParseQueryFields(&query)
+			server.MarshalResponse[[]RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(res)
+		}
+	}
+}
+
+// handleGetDeadLetter handles the GET request for a single dead letter
+func handleGetDeadLetter(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseDeadLetterId(d.Logger(), func(deadLetterId uuid.UUID) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				res, err := model.Map(Transform)(NewProcessor(d.Logger(), d.Context(), db).ByIdProvider(deadLetterId))()
+				if err != nil {
+					d.Logger().WithError(err).Errorf("Creating REST model.")
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+
+				query := r.URL.Query()
+				queryParams := jsonapi.ParseQueryFields(&query)
+				server.MarshalResponse[RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(res)
+			}
+		})
+	}
+}
+
+// handleReplayDeadLetter handles the POST request which runs the failed handler again with the original message
+// A replay which fails again is recorded on the dead letter and answered with 502
+func handleReplayDeadLetter(db *gorm.DB) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseDeadLetterId(d.Logger(), func(deadLetterId uuid.UUID) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				m, err := NewProcessor(d.Logger(), d.Context(), db).Replay(deadLetterId)
+				if err != nil {
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+
+				res, err := Transform(m)
+				if err != nil {
+					d.Logger().WithError(err).Errorf("Creating REST model.")
+					rest.WriteError(d.Logger())(w)(err)
+					return
+				}
+
+				query := r.URL.Query()
+				queryParams := jsonapi.ParseQueryFields(&query)
+				server.MarshalResponse[RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(res)
+			}
+		})
+	}
+}
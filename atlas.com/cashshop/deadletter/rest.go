@@ -0,0 +1,62 @@
+package deadletter
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RestModel represents a dead letter for the REST API
+type RestModel struct {
+	Id         uuid.UUID         `json:"-"`
+	Handler    string            `json:"handler"`
+	Topic      string            `json:"topic"`
+	Partition  int               `json:"partition"`
+	Offset     int64             `json:"offset"`
+	Key        string            `json:"key"`
+	Value      string            `json:"value"`
+	Headers    map[string]string `json:"headers"`
+	Error      string            `json:"error"`
+	Attempts   int               `json:"attempts"`
+	Status     string            `json:"status"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	ReplayedAt *time.Time        `json:"replayedAt,omitempty"`
+}
+
+// GetName returns the resource name
+func (r RestModel) GetName() string {
+	return "dead-letters"
+}
+
+// GetID returns the resource ID
+func (r RestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID
+func (r *RestModel) SetID(strId string) error {
+	id, err := uuid.Parse(strId)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// Transform converts a Model to a RestModel. The value is the original JSON payload
+func Transform(m Model) (RestModel, error) {
+	return RestModel{
+		Id:         m.Id(),
+		Handler:    m.Handler(),
+		Topic:      m.Topic(),
+		Partition:  m.Partition(),
+		Offset:     m.Offset(),
+		Key:        string(m.Key()),
+		Value:      string(m.Value()),
+		Headers:    m.Headers(),
+		Error:      m.Error(),
+		Attempts:   m.Attempts(),
+		Status:     string(m.Status()),
+		CreatedAt:  m.CreatedAt(),
+		ReplayedAt: m.ReplayedAt(),
+	}, nil
+}
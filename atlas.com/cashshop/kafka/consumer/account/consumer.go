@@ -1,14 +1,12 @@
 package account
 
 import (
-	"atlas-cashshop/cashshop/inventory"
+	account2 "atlas-cashshop/account"
 	consumer2 "atlas-cashshop/kafka/consumer"
 	"atlas-cashshop/kafka/message/account"
-	"atlas-cashshop/wallet"
 	"context"
 	"github.com/Chronicle20/atlas-kafka/consumer"
 	"github.com/Chronicle20/atlas-kafka/handler"
-	"github.com/Chronicle20/atlas-kafka/message"
 	"github.com/Chronicle20/atlas-kafka/topic"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/sirupsen/logrus"
@@ -28,54 +26,41 @@ func InitHandlers(l logrus.FieldLogger) func(db *gorm.DB) func(rf func(topic str
 		return func(rf func(topic string, handler handler.Handler) (string, error)) {
 			var t string
 			t, _ = topic.EnvProvider(l)(account.EnvEventTopicStatus)()
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleStatusEventCreated(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleStatusEventDeleted(db))))
+			_, _ = rf(t, consumer2.AdaptHandler(db, "account_status_created", handleStatusEventCreated(db)))
+			_, _ = rf(t, consumer2.AdaptHandler(db, "account_status_deleted", handleStatusEventDeleted(db)))
 		}
 	}
 }
 
-func handleStatusEventCreated(db *gorm.DB) message.Handler[account.StatusEvent] {
-	return func(l logrus.FieldLogger, ctx context.Context, e account.StatusEvent) {
+func handleStatusEventCreated(db *gorm.DB) consumer2.Handler[account.StatusEvent] {
+	return func(l logrus.FieldLogger, ctx context.Context, e account.StatusEvent) error {
 		if e.Status != account.EventStatusCreated {
-			return
+			return nil
 		}
-		l.Debugf("Account [%d] was created. Initializing cash shop information...", e.AccountId)
+		l.Debugf("Account [%d] was created. Provisioning cash shop information...", e.AccountId)
 
-		// Create wallet
-		_, err := wallet.NewProcessor(l, ctx, db).CreateAndEmit(e.AccountId, 0, 0, 0)
+		// Provision wallet and default compartments. Anything already present is left untouched, so redelivery is safe
+		_, err := account2.NewProcessor(l, ctx, db).ProvisionAndEmit(e.AccountId)
 		if err != nil {
-			l.WithError(err).Errorf("Could not create wallet for account [%d].", e.AccountId)
-			return
-		}
-
-		// Create inventory with default compartments
-		// Default capacity is 55, but this can be changed as needed
-		_, err = inventory.NewProcessor(l, ctx, db).CreateAndEmit(e.AccountId)
-		if err != nil {
-			l.WithError(err).Errorf("Could not create inventory for account [%d].", e.AccountId)
-			return
+			l.WithError(err).Errorf("Could not provision cash shop information for account [%d].", e.AccountId)
+			return err
 		}
+		return nil
 	}
 }
 
-func handleStatusEventDeleted(db *gorm.DB) message.Handler[account.StatusEvent] {
-	return func(l logrus.FieldLogger, ctx context.Context, e account.StatusEvent) {
+func handleStatusEventDeleted(db *gorm.DB) consumer2.Handler[account.StatusEvent] {
+	return func(l logrus.FieldLogger, ctx context.Context, e account.StatusEvent) error {
 		if e.Status != account.EventStatusDeleted {
-			return
-		}
-
-		// Delete wallet
-		err := wallet.NewProcessor(l, ctx, db).DeleteAndEmit(e.AccountId)
-		if err != nil {
-			l.WithError(err).Errorf("Could not delete wallet for account [%d].", e.AccountId)
-			return
+			return nil
 		}
 
-		// Delete inventory, compartments, and assets
-		err = inventory.NewProcessor(l, ctx, db).DeleteAndEmit(e.AccountId)
+		// Archive, then delete wallet, inventory, compartments, assets, items, and wishlists
+		_, err := account2.NewProcessor(l, ctx, db).DeleteAndEmit(e.AccountId)
 		if err != nil {
-			l.WithError(err).Errorf("Could not delete inventory for account [%d].", e.AccountId)
-			return
+			l.WithError(err).Errorf("Could not delete cash shop data for account [%d].", e.AccountId)
+			return err
 		}
+		return nil
 	}
 }
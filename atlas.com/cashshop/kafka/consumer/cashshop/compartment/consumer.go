@@ -2,14 +2,15 @@ package compartment
 
 import (
 	compartment2 "atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/correlation"
 	consumer2 "atlas-cashshop/kafka/consumer"
 	"atlas-cashshop/kafka/message/cashshop/compartment"
 	"context"
 	"github.com/Chronicle20/atlas-kafka/consumer"
 	"github.com/Chronicle20/atlas-kafka/handler"
-	"github.com/Chronicle20/atlas-kafka/message"
 	"github.com/Chronicle20/atlas-kafka/topic"
 	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -27,26 +28,110 @@ func InitHandlers(l logrus.FieldLogger) func(db *gorm.DB) func(rf func(topic str
 		return func(rf func(topic string, handler handler.Handler) (string, error)) {
 			var t string
 			t, _ = topic.EnvProvider(l)(compartment.EnvCommandTopic)()
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleAcceptCommand(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleReleaseCommand(db))))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_compartment_accept", handleAcceptCommand(db), handleFailure(db, acceptTarget)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_compartment_release", handleReleaseCommand(db), handleFailure(db, releaseTarget)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_compartment_reserve", handleReserveCommand(db), handleFailure(db, reserveTarget)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_compartment_cancel_reservation", handleCancelReservationCommand(db), handleFailure(db, cancelReservationTarget)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_compartment_move", handleMoveCommand(db), handleFailure(db, moveTarget)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_compartment_discard", handleDiscardCommand(db), handleFailure(db, discardTarget)))
 		}
 	}
 }
 
-func handleAcceptCommand(db *gorm.DB) message.Handler[compartment.Command[compartment.AcceptCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.AcceptCommandBody]) {
+func handleAcceptCommand(db *gorm.DB) consumer2.Handler[compartment.Command[compartment.AcceptCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.AcceptCommandBody]) error {
 		if c.Type != compartment.CommandAccept {
-			return
+			return nil
 		}
-		_ = compartment2.NewProcessor(l, ctx, db).AcceptAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.ReferenceId, c.Body.TransactionId)
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return compartment2.NewProcessor(l, ctx, db).AcceptAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.ReferenceId, c.Body.TransactionId)
 	}
 }
 
-func handleReleaseCommand(db *gorm.DB) message.Handler[compartment.Command[compartment.ReleaseCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.ReleaseCommandBody]) {
+func handleReleaseCommand(db *gorm.DB) consumer2.Handler[compartment.Command[compartment.ReleaseCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.ReleaseCommandBody]) error {
 		if c.Type != compartment.CommandRelease {
-			return
+			return nil
+		}
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return compartment2.NewProcessor(l, ctx, db).ReleaseAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.AssetId, c.Body.TransactionId)
+	}
+}
+
+func handleReserveCommand(db *gorm.DB) consumer2.Handler[compartment.Command[compartment.ReserveCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.ReserveCommandBody]) error {
+		if c.Type != compartment.CommandReserve {
+			return nil
+		}
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return compartment2.NewProcessor(l, ctx, db).ReserveAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.AssetId, c.Body.CharacterId, c.Body.TransactionId)
+	}
+}
+
+func handleCancelReservationCommand(db *gorm.DB) consumer2.Handler[compartment.Command[compartment.CancelReservationCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.CancelReservationCommandBody]) error {
+		if c.Type != compartment.CommandCancelReservation {
+			return nil
+		}
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return compartment2.NewProcessor(l, ctx, db).CancelReservationAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.AssetId, c.Body.TransactionId)
+	}
+}
+
+func handleMoveCommand(db *gorm.DB) consumer2.Handler[compartment.Command[compartment.MoveCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.MoveCommandBody]) error {
+		if c.Type != compartment.CommandMove {
+			return nil
+		}
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return compartment2.NewProcessor(l, ctx, db).MoveAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.AssetId, c.Body.DestinationCompartmentId, c.Body.TransactionId)
+	}
+}
+
+func handleDiscardCommand(db *gorm.DB) consumer2.Handler[compartment.Command[compartment.DiscardCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[compartment.DiscardCommandBody]) error {
+		if c.Type != compartment.CommandDiscard {
+			return nil
+		}
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return compartment2.NewProcessor(l, ctx, db).DiscardAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.AssetId, c.Body.TransactionId)
+	}
+}
+
+// target returns the compartment and transaction a command body addresses
+type target[E any] func(b E) (uuid.UUID, uuid.UUID)
+
+func acceptTarget(b compartment.AcceptCommandBody) (uuid.UUID, uuid.UUID) {
+	return b.CompartmentId, b.TransactionId
+}
+
+func releaseTarget(b compartment.ReleaseCommandBody) (uuid.UUID, uuid.UUID) {
+	return b.CompartmentId, b.TransactionId
+}
+
+func reserveTarget(b compartment.ReserveCommandBody) (uuid.UUID, uuid.UUID) {
+	return b.CompartmentId, b.TransactionId
+}
+
+func cancelReservationTarget(b compartment.CancelReservationCommandBody) (uuid.UUID, uuid.UUID) {
+	return b.CompartmentId, b.TransactionId
+}
+
+func moveTarget(b compartment.MoveCommandBody) (uuid.UUID, uuid.UUID) {
+	return b.CompartmentId, b.TransactionId
+}
+
+func discardTarget(b compartment.DiscardCommandBody) (uuid.UUID, uuid.UUID) {
+	return b.CompartmentId, b.TransactionId
+}
+
+// handleFailure reports a command which was given up on to the client as an error status event on the compartment it addressed
+func handleFailure[E any](db *gorm.DB, t target[E]) consumer2.FailureHandler[compartment.Command[E]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c compartment.Command[E], err error) {
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		compartmentId, transactionId := t(c.Body)
+		if ferr := compartment2.NewProcessor(l, ctx, db).FailAndEmit(compartmentId, compartment2.CompartmentType(c.CompartmentType), transactionId, err); ferr != nil {
+			l.WithError(ferr).Errorf("Unable to report failed [%s] command on compartment [%s].", c.Type, compartmentId)
 		}
-		_ = compartment2.NewProcessor(l, ctx, db).ReleaseAndEmit(c.AccountId, c.Body.CompartmentId, compartment2.CompartmentType(c.CompartmentType), c.Body.AssetId, c.Body.TransactionId)
 	}
 }
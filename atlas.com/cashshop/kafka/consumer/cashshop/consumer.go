@@ -2,15 +2,13 @@ package cashshop
 
 import (
 	cashshop3 "atlas-cashshop/cashshop"
+	"atlas-cashshop/correlation"
 	consumer2 "atlas-cashshop/kafka/consumer"
 	"atlas-cashshop/kafka/message/cashshop"
-	"atlas-cashshop/kafka/producer"
-	cashshop2 "atlas-cashshop/kafka/producer/cashshop"
 	"context"
 	"github.com/Chronicle20/atlas-constants/inventory"
 	"github.com/Chronicle20/atlas-kafka/consumer"
 	"github.com/Chronicle20/atlas-kafka/handler"
-	"github.com/Chronicle20/atlas-kafka/message"
 	"github.com/Chronicle20/atlas-kafka/topic"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/sirupsen/logrus"
@@ -30,66 +28,80 @@ func InitHandlers(l logrus.FieldLogger) func(db *gorm.DB) func(rf func(topic str
 		return func(rf func(topic string, handler handler.Handler) (string, error)) {
 			var t string
 			t, _ = topic.EnvProvider(l)(cashshop.EnvCommandTopic)()
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandRequestPurchase(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandRequestInventoryIncreaseByType(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandRequestInventoryIncreaseByItem(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandRequestStorageIncrease(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandRequestStorageIncreaseByItem(db))))
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandRequestCharacterSlotIncreaseByItem(db))))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_shop_request_purchase", handleCommandRequestPurchase(db), handleFailure[cashshop.RequestPurchaseCommandBody](db)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_shop_request_inventory_increase_by_type", handleCommandRequestInventoryIncreaseByType(db), handleFailure[cashshop.RequestInventoryIncreaseByTypeCommandBody](db)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_shop_request_inventory_increase_by_item", handleCommandRequestInventoryIncreaseByItem(db), handleFailure[cashshop.RequestInventoryIncreaseByItemCommandBody](db)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_shop_request_storage_increase", handleCommandRequestStorageIncrease(db), handleFailure[cashshop.RequestStorageIncreaseBody](db)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_shop_request_storage_increase_by_item", handleCommandRequestStorageIncreaseByItem(db), handleFailure[cashshop.RequestCharacterSlotIncreaseByItemCommandBody](db)))
+			_, _ = rf(t, consumer2.AdaptHandlerWithFailure(db, "cash_shop_request_character_slot_increase_by_item", handleCommandRequestCharacterSlotIncreaseByItem(db), handleFailure[cashshop.RequestCharacterSlotIncreaseByItemCommandBody](db)))
 		}
 	}
 }
 
-func handleCommandRequestPurchase(db *gorm.DB) message.Handler[cashshop.Command[cashshop.RequestPurchaseCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestPurchaseCommandBody]) {
+func handleCommandRequestPurchase(db *gorm.DB) consumer2.Handler[cashshop.Command[cashshop.RequestPurchaseCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestPurchaseCommandBody]) error {
 		if c.Type != cashshop.CommandTypeRequestPurchase {
-			return
+			return nil
 		}
-		_ = cashshop3.NewProcessor(l, ctx, db).PurchaseAndEmit(c.CharacterId, c.Body.Currency, c.Body.SerialNumber)
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		_, err := cashshop3.NewProcessor(l, ctx, db).PurchaseAndEmit(c.CharacterId, c.Body.Currency, c.Body.SerialNumber)
+		return err
 	}
 }
 
-func handleCommandRequestInventoryIncreaseByType(db *gorm.DB) message.Handler[cashshop.Command[cashshop.RequestInventoryIncreaseByTypeCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestInventoryIncreaseByTypeCommandBody]) {
+func handleCommandRequestInventoryIncreaseByType(db *gorm.DB) consumer2.Handler[cashshop.Command[cashshop.RequestInventoryIncreaseByTypeCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestInventoryIncreaseByTypeCommandBody]) error {
 		if c.Type != cashshop.CommandTypeRequestInventoryIncreaseByType {
-			return
+			return nil
 		}
-		_ = cashshop3.NewProcessor(l, ctx, db).PurchaseInventoryIncreaseByTypeAndEmit(c.CharacterId, c.Body.Currency, inventory.Type(c.Body.InventoryType))
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return cashshop3.NewProcessor(l, ctx, db).PurchaseInventoryIncreaseByTypeAndEmit(c.CharacterId, c.Body.Currency, inventory.Type(c.Body.InventoryType))
 	}
 }
 
-func handleCommandRequestInventoryIncreaseByItem(db *gorm.DB) message.Handler[cashshop.Command[cashshop.RequestInventoryIncreaseByItemCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestInventoryIncreaseByItemCommandBody]) {
+func handleCommandRequestInventoryIncreaseByItem(db *gorm.DB) consumer2.Handler[cashshop.Command[cashshop.RequestInventoryIncreaseByItemCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestInventoryIncreaseByItemCommandBody]) error {
 		if c.Type != cashshop.CommandTypeRequestInventoryIncreaseByItem {
-			return
+			return nil
 		}
-		_ = cashshop3.NewProcessor(l, ctx, db).PurchaseInventoryIncreaseByItemAndEmit(c.CharacterId, c.Body.Currency, c.Body.SerialNumber)
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		return cashshop3.NewProcessor(l, ctx, db).PurchaseInventoryIncreaseByItemAndEmit(c.CharacterId, c.Body.Currency, c.Body.SerialNumber)
 	}
 }
 
-func handleCommandRequestStorageIncrease(db *gorm.DB) message.Handler[cashshop.Command[cashshop.RequestStorageIncreaseBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestStorageIncreaseBody]) {
+func handleCommandRequestStorageIncrease(db *gorm.DB) consumer2.Handler[cashshop.Command[cashshop.RequestStorageIncreaseBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestStorageIncreaseBody]) error {
 		if c.Type != cashshop.CommandTypeRequestStorageIncrease {
-			return
+			return nil
 		}
-		_ = producer.ProviderImpl(l)(ctx)(cashshop.EnvEventTopicStatus)(cashshop2.ErrorStatusEventProvider(c.CharacterId, "UNKNOWN_ERROR"))
+		return cashshop3.ErrNotSupported
 	}
 }
 
-func handleCommandRequestStorageIncreaseByItem(db *gorm.DB) message.Handler[cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]) {
+func handleCommandRequestStorageIncreaseByItem(db *gorm.DB) consumer2.Handler[cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]) error {
 		if c.Type != cashshop.CommandTypeRequestStorageIncreaseByItem {
-			return
+			return nil
 		}
-		_ = producer.ProviderImpl(l)(ctx)(cashshop.EnvEventTopicStatus)(cashshop2.ErrorStatusEventProvider(c.CharacterId, "UNKNOWN_ERROR"))
+		return cashshop3.ErrNotSupported
 	}
 }
 
-func handleCommandRequestCharacterSlotIncreaseByItem(db *gorm.DB) message.Handler[cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]) {
+func handleCommandRequestCharacterSlotIncreaseByItem(db *gorm.DB) consumer2.Handler[cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[cashshop.RequestCharacterSlotIncreaseByItemCommandBody]) error {
 		if c.Type != cashshop.CommandTypeRequestCharacterSlotIncreaseByItem {
-			return
+			return nil
+		}
+		return cashshop3.ErrNotSupported
+	}
+}
+
+// handleFailure reports a command which was given up on to the client as an error status event
+func handleFailure[E any](db *gorm.DB) consumer2.FailureHandler[cashshop.Command[E]] {
+	return func(l logrus.FieldLogger, ctx context.Context, c cashshop.Command[E], err error) {
+		ctx = correlation.WithRequestId(ctx, c.RequestId)
+		if ferr := cashshop3.NewProcessor(l, ctx, db).FailAndEmit(c.CharacterId, err); ferr != nil {
+			l.WithError(ferr).Errorf("Unable to report failed [%s] command of character [%d].", c.Type, c.CharacterId)
 		}
-		_ = producer.ProviderImpl(l)(ctx)(cashshop.EnvEventTopicStatus)(cashshop2.ErrorStatusEventProvider(c.CharacterId, "UNKNOWN_ERROR"))
 	}
 }
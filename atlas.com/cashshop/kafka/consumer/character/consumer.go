@@ -1,13 +1,13 @@
 package character
 
 import (
+	character2 "atlas-cashshop/cashshop/character"
+	"atlas-cashshop/cashshop/starter"
 	consumer2 "atlas-cashshop/kafka/consumer"
 	"atlas-cashshop/kafka/message/character"
-	"atlas-cashshop/wishlist"
 	"context"
 	"github.com/Chronicle20/atlas-kafka/consumer"
 	"github.com/Chronicle20/atlas-kafka/handler"
-	"github.com/Chronicle20/atlas-kafka/message"
 	"github.com/Chronicle20/atlas-kafka/topic"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/sirupsen/logrus"
@@ -27,16 +27,34 @@ func InitHandlers(l logrus.FieldLogger) func(db *gorm.DB) func(rf func(topic str
 		return func(rf func(topic string, handler handler.Handler) (string, error)) {
 			var t string
 			t, _ = topic.EnvProvider(l)(character.EnvEventTopicStatus)()
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleStatusEventDeleted(db))))
+			_, _ = rf(t, consumer2.AdaptHandler(db, "character_status_created", handleStatusEventCreated(db)))
+			_, _ = rf(t, consumer2.AdaptHandler(db, "character_status_deleted", handleStatusEventDeleted(db)))
 		}
 	}
 }
 
-func handleStatusEventDeleted(db *gorm.DB) message.Handler[character.StatusEvent[character.DeletedStatusEventBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, e character.StatusEvent[character.DeletedStatusEventBody]) {
+func handleStatusEventCreated(db *gorm.DB) consumer2.Handler[character.StatusEvent[character.CreatedStatusEventBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, e character.StatusEvent[character.CreatedStatusEventBody]) error {
+		if e.Type != character.StatusEventTypeCreated {
+			return nil
+		}
+		err := starter.NewProcessor(l, ctx, db).GrantAndEmit(e.CharacterId)
+		if err != nil {
+			l.WithError(err).Errorf("Could not grant starter package to character [%d].", e.CharacterId)
+		}
+		return err
+	}
+}
+
+func handleStatusEventDeleted(db *gorm.DB) consumer2.Handler[character.StatusEvent[character.DeletedStatusEventBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, e character.StatusEvent[character.DeletedStatusEventBody]) error {
 		if e.Type != character.StatusEventTypeDeleted {
-			return
+			return nil
+		}
+		err := character2.NewProcessor(l, ctx, db).DeleteAndEmit(e.CharacterId)
+		if err != nil {
+			l.WithError(err).Errorf("Could not clean up cash shop state for character [%d].", e.CharacterId)
 		}
-		_ = wishlist.NewProcessor(l, ctx, db).DeleteAllAndEmit(e.CharacterId)
+		return err
 	}
 }
@@ -0,0 +1,46 @@
+package deadletter
+
+import (
+	deadletter2 "atlas-cashshop/deadletter"
+	consumer2 "atlas-cashshop/kafka/consumer"
+	"atlas-cashshop/kafka/message/deadletter"
+	"atlas-cashshop/retry"
+	"context"
+	"github.com/Chronicle20/atlas-kafka/consumer"
+	"github.com/Chronicle20/atlas-kafka/handler"
+	"github.com/Chronicle20/atlas-kafka/message"
+	"github.com/Chronicle20/atlas-kafka/topic"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func InitConsumers(l logrus.FieldLogger) func(func(config consumer.Config, decorators ...model.Decorator[consumer.Config])) func(consumerGroupId string) {
+	return func(rf func(config consumer.Config, decorators ...model.Decorator[consumer.Config])) func(consumerGroupId string) {
+		return func(consumerGroupId string) {
+			rf(consumer2.NewConfig(l)("cash_shop_dead_letter")(deadletter.EnvEventTopicDeadLetter)(consumerGroupId), consumer.SetHeaderParsers(consumer.SpanHeaderParser, consumer.TenantHeaderParser))
+		}
+	}
+}
+
+func InitHandlers(l logrus.FieldLogger) func(db *gorm.DB) func(rf func(topic string, handler handler.Handler) (string, error)) {
+	return func(db *gorm.DB) func(rf func(topic string, handler handler.Handler) (string, error)) {
+		return func(rf func(topic string, handler handler.Handler) (string, error)) {
+			var t string
+			t, _ = topic.EnvProvider(l)(deadletter.EnvEventTopicDeadLetter)()
+			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleDeadLetter(db))))
+		}
+	}
+}
+
+// handleDeadLetter records a dead letter. It is retried like any handler, but never dead lettered itself
+func handleDeadLetter(db *gorm.DB) message.Handler[deadletter.Event] {
+	return func(l logrus.FieldLogger, ctx context.Context, e deadletter.Event) {
+		err := retry.TryWithConfig(func(attempt int) (bool, error) {
+			return true, deadletter2.NewProcessor(l, ctx, db).Record(e)
+		}, consumer2.RetryConfig())
+		if err != nil {
+			l.WithError(err).Errorf("Unable to record dead letter of handler [%s] for [%s] partition [%d] offset [%d]. It remains on the dead letter topic.", e.Handler, e.Topic, e.Partition, e.Offset)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"atlas-cashshop/kafka/producer"
+	"atlas-cashshop/kafka/schema"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/Chronicle20/atlas-kafka/handler"
+	producer2 "github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-kafka/topic"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Dispatcher feeds messages straight to registered handlers in place of Kafka consumers, so handlers can be exercised without a broker
+type Dispatcher struct {
+	l        logrus.FieldLogger
+	mu       sync.Mutex
+	handlers map[string][]handler.Handler
+	offsets  map[string]int64
+}
+
+func NewDispatcher(l logrus.FieldLogger) *Dispatcher {
+	return &Dispatcher{
+		l:        l,
+		handlers: make(map[string][]handler.Handler),
+		offsets:  make(map[string]int64),
+	}
+}
+
+// Register adds a handler for the topic. It is shaped like the registration function InitHandlers expects
+func (d *Dispatcher) Register(topic string, h handler.Handler) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[topic] = append(d.handlers[topic], h)
+	return topic + "-" + strconv.Itoa(len(d.handlers[topic])), nil
+}
+
+// Dispatch runs every handler of the message's topic in the order they were registered
+func (d *Dispatcher) Dispatch(ctx context.Context, msg kafka.Message) error {
+	d.mu.Lock()
+	hs := append([]handler.Handler(nil), d.handlers[msg.Topic]...)
+	msg.Offset = d.offsets[msg.Topic]
+	d.offsets[msg.Topic]++
+	d.mu.Unlock()
+
+	var result error
+	for _, h := range hs {
+		if _, err := h(d.l, ctx, msg); err != nil {
+			result = errors.Join(result, err)
+		}
+	}
+	return result
+}
+
+// Provider delivers produced messages to the handlers of their topic, carrying the headers of the context like ProviderImpl would
+func (d *Dispatcher) Provider(ctx context.Context) producer.Provider {
+	return func(token string) producer2.MessageProducer {
+		return func(p model.Provider[[]kafka.Message]) error {
+			headers, err := producer.Headers(ctx)
+			if err != nil {
+				return err
+			}
+			hs := producer.KafkaHeaders(schema.WithVersion(token, headers))
+
+			ms, err := p()
+			if err != nil {
+				return err
+			}
+			for i := range ms {
+				ms[i].Headers = append(ms[i].Headers, hs...)
+			}
+			return d.Forward(ctx, token, ms)
+		}
+	}
+}
+
+// Forward delivers messages captured for the topic to its handlers, as a consumer of the topic would receive them
+func (d *Dispatcher) Forward(ctx context.Context, token string, ms []kafka.Message) error {
+	name, err := topic.EnvProvider(d.l)(token)()
+	if err != nil {
+		return err
+	}
+	for _, msg := range ms {
+		msg.Topic = name
+		if err = d.Dispatch(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package consumer
+
+import (
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/kafka/schema"
+	"context"
+	"testing"
+
+	producer2 "github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+func TestDispatcher_Provider(t *testing.T) {
+	t.Setenv(cashshop.EnvEventTopicStatus, "cash-shop-status")
+	d := NewDispatcher(logrus.New())
+
+	var received []kafka.Message
+	_, _ = d.Register("cash-shop-status", func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) (bool, error) {
+		received = append(received, msg)
+		return true, nil
+	})
+	_, _ = d.Register("other", func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) (bool, error) {
+		t.Errorf("Unexpected message for another topic")
+		return true, nil
+	})
+
+	p := producer2.SingleMessageProvider(producer2.CreateKey(1), map[string]string{"type": cashshop.StatusEventTypePurchase})
+	for i := 0; i < 2; i++ {
+		if err := d.Provider(context.Background())(cashshop.EnvEventTopicStatus)(p); err != nil {
+			t.Fatalf("Unexpected error dispatching: %v", err)
+		}
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 messages, got [%d]", len(received))
+	}
+	for i, msg := range received {
+		if msg.Topic != "cash-shop-status" || msg.Offset != int64(i) {
+			t.Errorf("Expected message [%d] on [cash-shop-status] at offset [%d], got [%s] at [%d]", i, i, msg.Topic, msg.Offset)
+		}
+		if v := schema.VersionOf(msg.Headers); v != cashshop.StatusEventVersion {
+			t.Errorf("Expected schema version [%d], got [%d]", cashshop.StatusEventVersion, v)
+		}
+	}
+}
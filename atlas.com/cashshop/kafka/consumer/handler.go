@@ -0,0 +1,169 @@
+package consumer
+
+import (
+	"atlas-cashshop/account/archive"
+	"atlas-cashshop/cashshop"
+	"atlas-cashshop/cashshop/inventory/compartment"
+	"atlas-cashshop/database"
+	"atlas-cashshop/deadletter"
+	deadletterMessage "atlas-cashshop/kafka/message/deadletter"
+	"atlas-cashshop/kafka/producer"
+	deadletterProducer "atlas-cashshop/kafka/producer/deadletter"
+	"atlas-cashshop/kafka/schema"
+	"atlas-cashshop/retry"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Chronicle20/atlas-kafka/handler"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	EnvRetryAttempts       = "HANDLER_RETRY_ATTEMPTS"
+	EnvRetryInitialBackoff = "HANDLER_RETRY_INITIAL_BACKOFF"
+	EnvRetryMaxBackoff     = "HANDLER_RETRY_MAX_BACKOFF"
+
+	DefaultRetryAttempts       = 5
+	DefaultRetryInitialBackoff = 200 * time.Millisecond
+	DefaultRetryMaxBackoff     = 5 * time.Second
+)
+
+// Handler handles a decoded message and reports whether it failed
+type Handler[M any] func(l logrus.FieldLogger, ctx context.Context, m M) error
+
+// RetryConfig returns how often and how patiently a failing handler is retried, doubling the backoff per attempt
+func RetryConfig() retry.Config {
+	attempts := DefaultRetryAttempts
+	if v, ok := os.LookupEnv(EnvRetryAttempts); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			attempts = n
+		}
+	}
+	return retry.NewConfig(attempts, durationFromEnv(EnvRetryInitialBackoff, DefaultRetryInitialBackoff), durationFromEnv(EnvRetryMaxBackoff, DefaultRetryMaxBackoff), 2)
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+// FailureHandler is told about a message which was given up on, because it was rejected or its retries were exhausted
+type FailureHandler[M any] func(l logrus.FieldLogger, ctx context.Context, m M, err error)
+
+// AdaptHandler retries a failing handler with backoff and dead letters the message once the retries are exhausted
+// Errors which reject the command on its merits are not retried, as another attempt cannot change the outcome
+// The handler is registered under its name so its dead letters can be replayed
+// Messages written with an older schema version are upgraded before they are decoded
+func AdaptHandler[M any](db *gorm.DB, name string, h Handler[M]) handler.Handler {
+	return AdaptHandlerWithFailure(db, name, h, func(logrus.FieldLogger, context.Context, M, error) {})
+}
+
+// AdaptHandlerWithFailure adapts a handler like AdaptHandler and additionally reports a message which was given up on to f
+func AdaptHandlerWithFailure[M any](db *gorm.DB, name string, h Handler[M], f FailureHandler[M]) handler.Handler {
+	deadletter.RegisterReplayer(name, func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) error {
+		value, err := schema.Upgrade(l)(msg.Topic, schema.VersionOf(msg.Headers), msg.Value)
+		if err != nil {
+			return err
+		}
+		var m M
+		if err = json.Unmarshal(value, &m); err != nil {
+			return err
+		}
+		return h(l, database.WithReadYourWrites(ctx), m)
+	})
+
+	return func(l logrus.FieldLogger, ctx context.Context, msg kafka.Message) (bool, error) {
+		// Reads made after the handler writes stay on the primary for the rest of the message
+		ctx = database.WithReadYourWrites(ctx)
+		value, err := schema.Upgrade(l)(msg.Topic, schema.VersionOf(msg.Headers), msg.Value)
+		if err != nil {
+			// An upgrade fails the same way every time, so the message is dead lettered for replay once the upgrader is fixed
+			deadLetter(l, ctx, db, name, msg, 0, err)
+			return true, nil
+		}
+		var m M
+		if err = json.Unmarshal(value, &m); err != nil {
+			// Consistent with the library adapter, a message this handler cannot decode is not meant for it
+			return true, nil
+		}
+
+		c := RetryConfig()
+		attempts := 0
+		err = retry.TryWithConfig(func(attempt int) (bool, error) {
+			attempts = attempt
+			err := h(l, ctx, m)
+			if err == nil || IsRejection(err) {
+				return false, err
+			}
+			l.WithError(err).Warnf("Handler [%s] failed on attempt [%d] of [%d].", name, attempt, c.Retries())
+			return true, err
+		}, c)
+		if err == nil {
+			return true, nil
+		}
+		if IsRejection(err) {
+			l.WithError(err).Debugf("Handler [%s] rejected message at [%s] partition [%d] offset [%d].", name, msg.Topic, msg.Partition, msg.Offset)
+			f(l, ctx, m, err)
+			return true, nil
+		}
+		deadLetter(l, ctx, db, name, msg, attempts, err)
+		f(l, ctx, m, err)
+		return true, nil
+	}
+}
+
+// IsRejection reports whether an error rejects a command on its merits rather than reporting a failure to process it
+func IsRejection(err error) bool {
+	if cashshop.IsRejection(err) || compartment.IsRejection(err) {
+		return true
+	}
+	for _, r := range []error{
+		gorm.ErrRecordNotFound,
+		archive.ErrAccountExists,
+	} {
+		if errors.Is(err, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// deadLetter publishes the failed message to the dead letter topic, which records it once consumed
+// Publishing does not depend on the database, so a failure caused by a database outage is not lost. Should publishing fail as well, the dead letter is recorded directly
+func deadLetter(l logrus.FieldLogger, ctx context.Context, db *gorm.DB, name string, msg kafka.Message, attempts int, cause error) {
+	headers := make(map[string]string)
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	e := deadletterMessage.Event{
+		Handler:   name,
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Error:     cause.Error(),
+		Attempts:  attempts,
+		FailedAt:  time.Now(),
+	}
+
+	l.WithError(cause).Errorf("Handler [%s] failed [%d] times. Dead lettering message at [%s] partition [%d] offset [%d].", name, attempts, msg.Topic, msg.Partition, msg.Offset)
+	err := producer.ProviderImpl(l)(ctx)(deadletterMessage.EnvEventTopicDeadLetter)(deadletterProducer.EventProvider(e))
+	if err == nil {
+		return
+	}
+	l.WithError(err).Errorf("Unable to publish dead letter of handler [%s]. Recording it directly.", name)
+	if err = deadletter.NewProcessor(l, ctx, db).Record(e); err != nil {
+		l.WithError(err).Errorf("Unable to record dead letter of handler [%s]. Message [%s] is lost.", name, string(msg.Value))
+	}
+}
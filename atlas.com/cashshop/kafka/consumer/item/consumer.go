@@ -7,7 +7,6 @@ import (
 	"context"
 	"github.com/Chronicle20/atlas-kafka/consumer"
 	"github.com/Chronicle20/atlas-kafka/handler"
-	"github.com/Chronicle20/atlas-kafka/message"
 	"github.com/Chronicle20/atlas-kafka/topic"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/sirupsen/logrus"
@@ -27,15 +26,15 @@ func InitHandlers(l logrus.FieldLogger) func(db *gorm.DB) func(rf func(topic str
 		return func(rf func(topic string, handler handler.Handler) (string, error)) {
 			var t string
 			t, _ = topic.EnvProvider(l)(itemMessage.EnvCommandTopic)()
-			_, _ = rf(t, message.AdaptHandler(message.PersistentConfig(handleCommandCreate(db))))
+			_, _ = rf(t, consumer2.AdaptHandler(db, "cash_item_create", handleCommandCreate(db)))
 		}
 	}
 }
 
-func handleCommandCreate(db *gorm.DB) message.Handler[itemMessage.Command[itemMessage.CreateCommandBody]] {
-	return func(l logrus.FieldLogger, ctx context.Context, command itemMessage.Command[itemMessage.CreateCommandBody]) {
+func handleCommandCreate(db *gorm.DB) consumer2.Handler[itemMessage.Command[itemMessage.CreateCommandBody]] {
+	return func(l logrus.FieldLogger, ctx context.Context, command itemMessage.Command[itemMessage.CreateCommandBody]) error {
 		if command.Type != itemMessage.CommandCreate {
-			return
+			return nil
 		}
 
 		l.Debugf("Handling create item command for character %d", command.CharacterId)
@@ -43,14 +42,17 @@ func handleCommandCreate(db *gorm.DB) message.Handler[itemMessage.Command[itemMe
 		_, err := itemModel.NewProcessor(l, ctx, db).CreateAndEmit(
 			command.Body.TemplateId,
 			command.Body.Quantity,
+			0,
 			command.Body.PurchasedBy,
+			itemModel.DefaultExpiration(),
 		)
 
 		if err != nil {
 			l.WithError(err).Errorf("Error creating item.")
-			return
+			return err
 		}
 
 		l.Debugf("Successfully created item for character %d", command.CharacterId)
+		return nil
 	}
 }
@@ -2,19 +2,30 @@ package compartment
 
 import (
 	"github.com/google/uuid"
+	"time"
 )
 
 const (
-	EnvCommandTopic = "COMMAND_TOPIC_CASH_COMPARTMENT"
-	CommandAccept   = "ACCEPT"
-	CommandRelease  = "RELEASE"
+	EnvCommandTopic          = "COMMAND_TOPIC_CASH_COMPARTMENT"
+	CommandAccept            = "ACCEPT"
+	CommandRelease           = "RELEASE"
+	CommandReserve           = "RESERVE"
+	CommandCancelReservation = "CANCEL_RESERVATION"
+	CommandMove              = "MOVE"
+	CommandDiscard           = "DISCARD"
 )
 
+// CommandVersion is the schema version of commands read from EnvCommandTopic
+// Version 2 moved the requestId from the body to the command, like in StatusEvent
+const CommandVersion = uint32(2)
+
+// Command carries the ID a client gives it. Status events caused by the command echo it
 type Command[E any] struct {
-	AccountId       uint32 `json:"accountId"`
-	CompartmentType byte   `json:"compartmentType"`
-	Type            string `json:"type"`
-	Body            E      `json:"body"`
+	AccountId       uint32    `json:"accountId"`
+	CompartmentType byte      `json:"compartmentType"`
+	RequestId       uuid.UUID `json:"requestId,omitzero"`
+	Type            string    `json:"type"`
+	Body            E         `json:"body"`
 }
 
 type AcceptCommandBody struct {
@@ -29,14 +40,56 @@ type ReleaseCommandBody struct {
 	AssetId       uint32    `json:"assetId"`
 }
 
+type ReserveCommandBody struct {
+	TransactionId uuid.UUID `json:"transactionId"`
+	CompartmentId uuid.UUID `json:"compartmentId"`
+	AssetId       uint32    `json:"assetId"`
+	CharacterId   uint32    `json:"characterId"`
+}
+
+type CancelReservationCommandBody struct {
+	TransactionId uuid.UUID `json:"transactionId"`
+	CompartmentId uuid.UUID `json:"compartmentId"`
+	AssetId       uint32    `json:"assetId"`
+}
+
+type MoveCommandBody struct {
+	TransactionId            uuid.UUID `json:"transactionId"`
+	CompartmentId            uuid.UUID `json:"compartmentId"`
+	AssetId                  uint32    `json:"assetId"`
+	DestinationCompartmentId uuid.UUID `json:"destinationCompartmentId"`
+}
+
+type DiscardCommandBody struct {
+	TransactionId uuid.UUID `json:"transactionId"`
+	CompartmentId uuid.UUID `json:"compartmentId"`
+	AssetId       uint32    `json:"assetId"`
+}
+
 const (
-	EnvEventTopicStatus     = "EVENT_TOPIC_CASH_COMPARTMENT_STATUS"
-	StatusEventTypeCreated  = "CREATED"
-	StatusEventTypeUpdated  = "UPDATED"
-	StatusEventTypeDeleted  = "DELETED"
-	StatusEventTypeAccepted = "ACCEPTED"
-	StatusEventTypeReleased = "RELEASED"
-	StatusEventTypeError    = "ERROR"
+	EnvEventTopicStatus                 = "EVENT_TOPIC_CASH_COMPARTMENT_STATUS"
+	StatusEventTypeCreated              = "CREATED"
+	StatusEventTypeUpdated              = "UPDATED"
+	StatusEventTypeDeleted              = "DELETED"
+	StatusEventTypeAccepted             = "ACCEPTED"
+	StatusEventTypeReleased             = "RELEASED"
+	StatusEventTypeReserved             = "RESERVED"
+	StatusEventTypeReservationCancelled = "RESERVATION_CANCELLED"
+	StatusEventTypeDiscarded            = "DISCARDED"
+	StatusEventTypeError                = "ERROR"
+)
+
+const (
+	ErrorCodeUnknown             = "UNKNOWN_ERROR"
+	ErrorCodeAssetCreationFailed = "ASSET_CREATION_FAILED"
+	ErrorCodeItemNotFound        = "ITEM_NOT_FOUND"
+	ErrorCodeAssetReserved       = "ASSET_RESERVED"
+	ErrorCodeAssetNotReserved    = "ASSET_NOT_RESERVED"
+	ErrorCodeCompartmentMismatch = "COMPARTMENT_MISMATCH"
+	ErrorCodeTypeMismatch        = "TYPE_MISMATCH"
+	ErrorCodeCompartmentFull     = "COMPARTMENT_FULL"
+	ErrorCodeInvalidDestination  = "INVALID_DESTINATION"
+	ErrorCodeCannotDiscard       = "CANNOT_DISCARD"
 )
 
 // StatusEvent represents a cash compartment status event
@@ -44,6 +97,7 @@ const (
 type StatusEvent[E any] struct {
 	CompartmentId   uuid.UUID `json:"compartmentId"`
 	CompartmentType byte      `json:"compartmentType"`
+	RequestId       uuid.UUID `json:"requestId,omitzero"`
 	Type            string    `json:"type"`
 	Body            E         `json:"body"`
 }
@@ -72,6 +126,24 @@ type StatusEventReleasedBody struct {
 	TransactionId uuid.UUID `json:"transactionId"`
 }
 
+type StatusEventReservedBody struct {
+	TransactionId uuid.UUID `json:"transactionId"`
+	AssetId       uint32    `json:"assetId"`
+	CharacterId   uint32    `json:"characterId"`
+	Expiration    time.Time `json:"expiration"`
+}
+
+type StatusEventReservationCancelledBody struct {
+	TransactionId uuid.UUID `json:"transactionId"`
+	AssetId       uint32    `json:"assetId"`
+}
+
+type StatusEventDiscardedBody struct {
+	TransactionId uuid.UUID `json:"transactionId"`
+	AssetId       uint32    `json:"assetId"`
+	TemplateId    uint32    `json:"templateId"`
+}
+
 type StatusEventErrorBody struct {
 	ErrorCode     string    `json:"errorCode"`
 	TransactionId uuid.UUID `json:"transactionId"`
@@ -12,10 +12,16 @@ const (
 	CommandTypeRequestCharacterSlotIncreaseByItem = "REQUEST_CHARACTER_SLOT_INCREASE_BY_ITEM"
 )
 
+// CommandVersion is the schema version of commands read from EnvCommandTopic
+// Version 2 moved the requestId from the body to the command, next to the characterId like in StatusEvent
+const CommandVersion = uint32(2)
+
+// Command carries the ID a client gives it. Status events caused by the command echo it
 type Command[E any] struct {
-	CharacterId uint32 `json:"characterId"`
-	Type        string `json:"type"`
-	Body        E      `json:"body"`
+	CharacterId uint32    `json:"characterId"`
+	RequestId   uuid.UUID `json:"requestId,omitzero"`
+	Type        string    `json:"type"`
+	Body        E         `json:"body"`
 }
 
 type RequestPurchaseCommandBody struct {
@@ -47,6 +53,10 @@ type RequestCharacterSlotIncreaseByItemCommandBody struct {
 	SerialNumber uint32 `json:"serialNumber"`
 }
 
+// StatusEventVersion is the schema version of events written to EnvEventTopicStatus
+// Version 2 added the currency to PurchaseEventBody
+const StatusEventVersion = uint32(2)
+
 const (
 	EnvEventTopicStatus                       = "EVENT_TOPIC_CASH_SHOP_STATUS"
 	StatusEventTypeInventoryCapacityIncreased = "INVENTORY_CAPACITY_INCREASED"
@@ -54,10 +64,12 @@ const (
 	StatusEventTypeError                      = "ERROR"
 )
 
+// StatusEvent echoes the request ID of the command which caused it
 type StatusEvent[E any] struct {
-	CharacterId uint32 `json:"characterId"`
-	Type        string `json:"type"`
-	Body        E      `json:"body"`
+	CharacterId uint32    `json:"characterId"`
+	RequestId   uuid.UUID `json:"requestId,omitzero"`
+	Type        string    `json:"type"`
+	Body        E         `json:"body"`
 }
 
 type InventoryCapacityIncreasedBody struct {
@@ -66,6 +78,18 @@ type InventoryCapacityIncreasedBody struct {
 	Amount        uint32 `json:"amount"`
 }
 
+// Error codes reported in ErrorEventBody. Each names the client's cash shop failure message the channel service should show
+const (
+	ErrorCodeUnknown             = "UNKNOWN_ERROR"
+	ErrorCodeRequestTimedOut     = "REQUEST_TIMED_OUT"
+	ErrorCodeNotEnoughCash       = "NOT_ENOUGH_CASH"
+	ErrorCodeInventoryFull       = "INVENTORY_FULL"
+	ErrorCodeInventorySlotLimit  = "INVENTORY_SLOT_LIMIT"
+	ErrorCodeCommodityNotFound   = "COMMODITY_NOT_FOUND"
+	ErrorCodeCharacterNotFound   = "CHARACTER_NOT_FOUND"
+	ErrorCodeRequestNotSupported = "REQUEST_NOT_SUPPORTED"
+)
+
 type ErrorEventBody struct {
 	Error      string `json:"error"`
 	CashItemId uint32 `json:"cashItemId,omitempty"`
@@ -74,6 +98,7 @@ type ErrorEventBody struct {
 type PurchaseEventBody struct {
 	TemplateId    uint32    `json:"templateId"`
 	Price         uint32    `json:"price"`
+	Currency      uint32    `json:"currency"`
 	CompartmentId uuid.UUID `json:"compartmentId"`
 	AssetId       uuid.UUID `json:"assetId"`
 	ItemId        uint32    `json:"itemId"`
@@ -0,0 +1,21 @@
+package deadletter
+
+import "time"
+
+const (
+	EnvEventTopicDeadLetter = "EVENT_TOPIC_CASH_SHOP_DEAD_LETTER"
+)
+
+// Event describes a consumed message whose handler kept failing after its retries were exhausted
+type Event struct {
+	Handler   string            `json:"handler"`
+	Topic     string            `json:"topic"`
+	Partition int               `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       []byte            `json:"key"`
+	Value     []byte            `json:"value"`
+	Headers   map[string]string `json:"headers"`
+	Error     string            `json:"error"`
+	Attempts  int               `json:"attempts"`
+	FailedAt  time.Time         `json:"failedAt"`
+}
@@ -7,6 +7,7 @@ const (
 	CommandCreate = "CREATE"
 
 	StatusCreated = "CREATED"
+	StatusDeleted = "DELETED"
 )
 
 type Command[E any] struct {
@@ -34,3 +35,8 @@ type StatusEventCreatedBody struct {
 	PurchasedBy uint32 `json:"purchasedBy"`
 	Flag        uint16 `json:"flag"`
 }
+
+type StatusEventDeletedBody struct {
+	CashId     int64  `json:"cashId"`
+	TemplateId uint32 `json:"templateId"`
+}
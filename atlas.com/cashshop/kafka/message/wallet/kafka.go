@@ -1,5 +1,7 @@
 package wallet
 
+import "github.com/google/uuid"
+
 const (
 	EnvEventTopicStatus        = "EVENT_TOPIC_WALLET_STATUS"
 	StatusEventTypeCreated     = "CREATED"
@@ -7,10 +9,12 @@ const (
 	StatusEventTypeDeleted     = "DELETED"
 )
 
+// StatusEvent echoes the request ID of the command which caused it, if any
 type StatusEvent[E any] struct {
-	AccountId uint32 `json:"accountId"`
-	Type      string `json:"type"`
-	Body      E      `json:"body"`
+	AccountId uint32    `json:"accountId"`
+	RequestId uuid.UUID `json:"requestId,omitzero"`
+	Type      string    `json:"type"`
+	Body      E         `json:"body"`
 }
 
 type StatusEventCreatedBody struct {
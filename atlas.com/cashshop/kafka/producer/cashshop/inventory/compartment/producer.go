@@ -7,15 +7,17 @@ import (
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"time"
 )
 
 // CreateStatusEventProvider creates a provider for compartment creation events
 // According to the requirements, it should always include compartmentId and type, and include capacity in the body
-func CreateStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, capacity uint32) model.Provider[[]kafka.Message] {
+func CreateStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, capacity uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(0) // Using 0 as the key since we don't have a numeric ID to use
 	value := &compartment.StatusEvent[compartment.StatusEventCreatedBody]{
 		CompartmentId:   compartmentId,
 		CompartmentType: compartmentType,
+		RequestId:       requestId,
 		Type:            compartment.StatusEventTypeCreated,
 		Body: compartment.StatusEventCreatedBody{
 			Capacity: capacity,
@@ -25,11 +27,12 @@ func CreateStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, ca
 }
 
 // UpdateStatusEventProvider creates a provider for compartment update events
-func UpdateStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, capacity uint32) model.Provider[[]kafka.Message] {
+func UpdateStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, capacity uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(0) // Using 0 as the key since we don't have a numeric ID to use
 	value := &compartment.StatusEvent[compartment.StatusEventUpdatedBody]{
 		CompartmentId:   compartmentId,
 		CompartmentType: compartmentType,
+		RequestId:       requestId,
 		Type:            compartment.StatusEventTypeUpdated,
 		Body: compartment.StatusEventUpdatedBody{
 			Capacity: capacity,
@@ -40,22 +43,24 @@ func UpdateStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, ca
 
 // DeleteStatusEventProvider creates a provider for compartment deletion events
 // According to the requirements, it should always include compartmentId and type, and have an empty body
-func DeleteStatusEventProvider(compartmentId uuid.UUID, compartmentType byte) model.Provider[[]kafka.Message] {
+func DeleteStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(0) // Using 0 as the key since we don't have a numeric ID to use
 	value := &compartment.StatusEvent[compartment.StatusEventDeletedBody]{
 		CompartmentId:   compartmentId,
 		CompartmentType: compartmentType,
+		RequestId:       requestId,
 		Type:            compartment.StatusEventTypeDeleted,
 		Body:            compartment.StatusEventDeletedBody{},
 	}
 	return producer.SingleMessageProvider(key, value)
 }
 
-func ErrorStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, error string, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
+func ErrorStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, error string, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(0) // Using 0 as the key since we don't have a numeric ID to use
 	value := &compartment.StatusEvent[compartment.StatusEventErrorBody]{
 		CompartmentId:   compartmentId,
 		CompartmentType: compartmentType,
+		RequestId:       requestId,
 		Type:            cashshop.StatusEventTypeError,
 		Body: compartment.StatusEventErrorBody{
 			ErrorCode:     error,
@@ -65,11 +70,12 @@ func ErrorStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, err
 	return producer.SingleMessageProvider(key, value)
 }
 
-func AcceptedStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
+func AcceptedStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(0) // Using 0 as the key since we don't have a numeric ID to use
 	value := &compartment.StatusEvent[compartment.StatusEventAcceptedBody]{
 		CompartmentId:   compartmentId,
 		CompartmentType: compartmentType,
+		RequestId:       requestId,
 		Type:            compartment.StatusEventTypeAccepted,
 		Body: compartment.StatusEventAcceptedBody{
 			TransactionId: transactionId,
@@ -78,11 +84,12 @@ func AcceptedStatusEventProvider(compartmentId uuid.UUID, compartmentType byte,
 	return producer.SingleMessageProvider(key, value)
 }
 
-func ReleasedStatusEventProvider(compartmentId uuid.UUID, compartmentType byte, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
+func ReleasedStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(0) // Using 0 as the key since we don't have a numeric ID to use
 	value := &compartment.StatusEvent[compartment.StatusEventReleasedBody]{
 		CompartmentId:   compartmentId,
 		CompartmentType: compartmentType,
+		RequestId:       requestId,
 		Type:            compartment.StatusEventTypeReleased,
 		Body: compartment.StatusEventReleasedBody{
 			TransactionId: transactionId,
@@ -90,3 +97,51 @@ func ReleasedStatusEventProvider(compartmentId uuid.UUID, compartmentType byte,
 	}
 	return producer.SingleMessageProvider(key, value)
 }
+
+func ReservedStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, assetId uint32, characterId uint32, expiration time.Time, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(int(assetId))
+	value := &compartment.StatusEvent[compartment.StatusEventReservedBody]{
+		CompartmentId:   compartmentId,
+		CompartmentType: compartmentType,
+		RequestId:       requestId,
+		Type:            compartment.StatusEventTypeReserved,
+		Body: compartment.StatusEventReservedBody{
+			TransactionId: transactionId,
+			AssetId:       assetId,
+			CharacterId:   characterId,
+			Expiration:    expiration,
+		},
+	}
+	return producer.SingleMessageProvider(key, value)
+}
+
+func ReservationCancelledStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, assetId uint32, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(int(assetId))
+	value := &compartment.StatusEvent[compartment.StatusEventReservationCancelledBody]{
+		CompartmentId:   compartmentId,
+		CompartmentType: compartmentType,
+		RequestId:       requestId,
+		Type:            compartment.StatusEventTypeReservationCancelled,
+		Body: compartment.StatusEventReservationCancelledBody{
+			TransactionId: transactionId,
+			AssetId:       assetId,
+		},
+	}
+	return producer.SingleMessageProvider(key, value)
+}
+
+func DiscardedStatusEventProvider(requestId uuid.UUID, compartmentId uuid.UUID, compartmentType byte, assetId uint32, templateId uint32, transactionId uuid.UUID) model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(int(assetId))
+	value := &compartment.StatusEvent[compartment.StatusEventDiscardedBody]{
+		CompartmentId:   compartmentId,
+		CompartmentType: compartmentType,
+		RequestId:       requestId,
+		Type:            compartment.StatusEventTypeDiscarded,
+		Body: compartment.StatusEventDiscardedBody{
+			TransactionId: transactionId,
+			AssetId:       assetId,
+			TemplateId:    templateId,
+		},
+	}
+	return producer.SingleMessageProvider(key, value)
+}
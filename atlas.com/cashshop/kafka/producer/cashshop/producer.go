@@ -8,10 +8,11 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-func ErrorStatusEventProvider(characterId uint32, error string) model.Provider[[]kafka.Message] {
+func ErrorStatusEventProvider(requestId uuid.UUID, characterId uint32, error string) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(characterId))
 	value := &cashshop.StatusEvent[cashshop.ErrorEventBody]{
 		CharacterId: characterId,
+		RequestId:   requestId,
 		Type:        cashshop.StatusEventTypeError,
 		Body: cashshop.ErrorEventBody{
 			Error: error,
@@ -20,10 +21,11 @@ func ErrorStatusEventProvider(characterId uint32, error string) model.Provider[[
 	return producer.SingleMessageProvider(key, value)
 }
 
-func InventoryCapacityIncreasedStatusEventProvider(characterId uint32, inventoryType byte, capacity uint32, amount uint32) model.Provider[[]kafka.Message] {
+func InventoryCapacityIncreasedStatusEventProvider(requestId uuid.UUID, characterId uint32, inventoryType byte, capacity uint32, amount uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(characterId))
 	value := &cashshop.StatusEvent[cashshop.InventoryCapacityIncreasedBody]{
 		CharacterId: characterId,
+		RequestId:   requestId,
 		Type:        cashshop.StatusEventTypeInventoryCapacityIncreased,
 		Body: cashshop.InventoryCapacityIncreasedBody{
 			InventoryType: inventoryType,
@@ -34,14 +36,16 @@ func InventoryCapacityIncreasedStatusEventProvider(characterId uint32, inventory
 	return producer.SingleMessageProvider(key, value)
 }
 
-func PurchaseStatusEventProvider(characterId uint32, templateId, price uint32, compartmentId uuid.UUID, assetId uuid.UUID, itemId uint32) model.Provider[[]kafka.Message] {
+func PurchaseStatusEventProvider(requestId uuid.UUID, characterId uint32, templateId, price uint32, currency uint32, compartmentId uuid.UUID, assetId uuid.UUID, itemId uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(characterId))
 	value := &cashshop.StatusEvent[cashshop.PurchaseEventBody]{
 		CharacterId: characterId,
+		RequestId:   requestId,
 		Type:        cashshop.StatusEventTypePurchase,
 		Body: cashshop.PurchaseEventBody{
 			TemplateId:    templateId,
 			Price:         price,
+			Currency:      currency,
 			CompartmentId: compartmentId,
 			AssetId:       assetId,
 			ItemId:        itemId,
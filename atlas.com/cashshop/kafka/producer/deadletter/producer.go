@@ -0,0 +1,13 @@
+package deadletter
+
+import (
+	"atlas-cashshop/kafka/message/deadletter"
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+)
+
+// EventProvider keys the dead letter with the original message key so letters of the same entity stay ordered
+func EventProvider(e deadletter.Event) model.Provider[[]kafka.Message] {
+	return producer.SingleMessageProvider(e.Key, &e)
+}
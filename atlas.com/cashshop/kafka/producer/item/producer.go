@@ -22,3 +22,16 @@ func CreateStatusEventProvider(id uint32, cashId int64, templateId uint32, quant
 	}
 	return producer.SingleMessageProvider(key, value)
 }
+
+func DeleteStatusEventProvider(id uint32, cashId int64, templateId uint32, purchasedBy uint32) model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(int(id))
+	value := &item.StatusEvent[item.StatusEventDeletedBody]{
+		CharacterId: purchasedBy,
+		Type:        item.StatusDeleted,
+		Body: item.StatusEventDeletedBody{
+			CashId:     cashId,
+			TemplateId: templateId,
+		},
+	}
+	return producer.SingleMessageProvider(key, value)
+}
@@ -0,0 +1,85 @@
+package producer
+
+import (
+	"atlas-cashshop/kafka/schema"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+)
+
+// Memory captures produced messages per topic token in place of Kafka, so processors can be exercised without a broker
+type Memory struct {
+	mu       sync.Mutex
+	messages map[string][]kafka.Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{messages: make(map[string][]kafka.Message)}
+}
+
+// Provider captures messages with the headers of the context, like ProviderImpl would produce them
+func (m *Memory) Provider(ctx context.Context) Provider {
+	return func(token string) producer.MessageProducer {
+		return func(p model.Provider[[]kafka.Message]) error {
+			headers, err := Headers(ctx)
+			if err != nil {
+				return err
+			}
+			return m.HeaderProvider(schema.WithVersion(token, headers))(token)(p)
+		}
+	}
+}
+
+// HeaderProvider captures messages carrying the given headers, like HeaderProviderImpl would produce them
+func (m *Memory) HeaderProvider(headers map[string]string) Provider {
+	hs := KafkaHeaders(headers)
+	return func(token string) producer.MessageProducer {
+		return func(p model.Provider[[]kafka.Message]) error {
+			ms, err := p()
+			if err != nil {
+				return err
+			}
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			for _, msg := range ms {
+				msg.Headers = append(msg.Headers, hs...)
+				m.messages[token] = append(m.messages[token], msg)
+			}
+			return nil
+		}
+	}
+}
+
+// Messages returns the messages captured for the topic in the order they were produced
+func (m *Memory) Messages(token string) []kafka.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]kafka.Message(nil), m.messages[token]...)
+}
+
+// Drain returns the messages captured for the topic and forgets them
+func (m *Memory) Drain(token string) []kafka.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ms := m.messages[token]
+	delete(m.messages, token)
+	return ms
+}
+
+// KafkaHeaders converts headers to their Kafka form, ordered by key
+func KafkaHeaders(headers map[string]string) []kafka.Header {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]kafka.Header, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, kafka.Header{Key: k, Value: []byte(headers[k])})
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package producer
+
+import (
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/kafka/schema"
+	"strconv"
+	"testing"
+
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestMemory_HeaderProvider(t *testing.T) {
+	m := NewMemory()
+	headers := map[string]string{schema.HeaderVersion: strconv.Itoa(1), "TENANT_ID": "x"}
+	ms := []kafka.Message{{Value: []byte("a")}, {Value: []byte("b")}}
+	if err := m.HeaderProvider(headers)(cashshop.EnvEventTopicStatus)(model.FixedProvider(ms)); err != nil {
+		t.Fatalf("Unexpected error producing: %v", err)
+	}
+
+	captured := m.Drain(cashshop.EnvEventTopicStatus)
+	if len(captured) != 2 || string(captured[0].Value) != "a" || string(captured[1].Value) != "b" {
+		t.Fatalf("Expected messages to be captured in order, got %v", captured)
+	}
+	if len(captured[0].Headers) != 2 {
+		t.Errorf("Expected headers to be carried, got %v", captured[0].Headers)
+	}
+	if len(m.Messages(cashshop.EnvEventTopicStatus)) != 0 {
+		t.Errorf("Expected drained messages to be forgotten")
+	}
+}
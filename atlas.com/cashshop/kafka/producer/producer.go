@@ -1,6 +1,7 @@
 package producer
 
 import (
+	"atlas-cashshop/kafka/schema"
 	"context"
 	"github.com/Chronicle20/atlas-kafka/producer"
 	"github.com/Chronicle20/atlas-kafka/topic"
@@ -9,12 +10,43 @@ import (
 
 type Provider func(token string) producer.MessageProducer
 
+// HeaderProvider produces messages carrying the given headers
+type HeaderProvider func(headers map[string]string) Provider
+
 func ProviderImpl(l logrus.FieldLogger) func(ctx context.Context) func(token string) producer.MessageProducer {
 	return func(ctx context.Context) func(token string) producer.MessageProducer {
 		sd := producer.SpanHeaderDecorator(ctx)
 		td := producer.TenantHeaderDecorator(ctx)
 		return func(token string) producer.MessageProducer {
-			return producer.Produce(l)(producer.WriterProvider(topic.EnvProvider(l)(token)))(sd, td)
+			return producer.Produce(l)(producer.WriterProvider(topic.EnvProvider(l)(token)))(sd, td, schema.HeaderDecorator(token))
+		}
+	}
+}
+
+// Headers captures the span and tenant headers of the context so messages can be produced later on its behalf
+func Headers(ctx context.Context) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, d := range []producer.HeaderDecorator{producer.SpanHeaderDecorator(ctx), producer.TenantHeaderDecorator(ctx)} {
+		hs, err := d()
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range hs {
+			result[k] = v
+		}
+	}
+	return result, nil
+}
+
+// HeaderProviderImpl produces messages carrying previously captured headers rather than those of a live context
+// The captured headers are expected to carry the schema version the messages were written with
+func HeaderProviderImpl(l logrus.FieldLogger) HeaderProvider {
+	return func(headers map[string]string) Provider {
+		hd := func() (map[string]string, error) {
+			return headers, nil
+		}
+		return func(token string) producer.MessageProducer {
+			return producer.Produce(l)(producer.WriterProvider(topic.EnvProvider(l)(token)))(hd)
 		}
 	}
 }
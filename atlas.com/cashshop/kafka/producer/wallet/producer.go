@@ -4,13 +4,15 @@ import (
 	"atlas-cashshop/kafka/message/wallet"
 	"github.com/Chronicle20/atlas-kafka/producer"
 	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 )
 
-func CreateStatusEventProvider(accountId uint32, credit uint32, points uint32, prepaid uint32) model.Provider[[]kafka.Message] {
+func CreateStatusEventProvider(requestId uuid.UUID, accountId uint32, credit uint32, points uint32, prepaid uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(accountId))
 	value := &wallet.StatusEvent[wallet.StatusEventCreatedBody]{
 		AccountId: accountId,
+		RequestId: requestId,
 		Type:      wallet.StatusEventTypeCreated,
 		Body: wallet.StatusEventCreatedBody{
 			Credit:  credit,
@@ -21,10 +23,11 @@ func CreateStatusEventProvider(accountId uint32, credit uint32, points uint32, p
 	return producer.SingleMessageProvider(key, value)
 }
 
-func UpdateStatusEventProvider(accountId uint32, credit uint32, points uint32, prepaid uint32) model.Provider[[]kafka.Message] {
+func UpdateStatusEventProvider(requestId uuid.UUID, accountId uint32, credit uint32, points uint32, prepaid uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(accountId))
 	value := &wallet.StatusEvent[wallet.StatusEventUpdatedBody]{
 		AccountId: accountId,
+		RequestId: requestId,
 		Type:      wallet.StatusEventTypeUpdated,
 		Body: wallet.StatusEventUpdatedBody{
 			Credit:  credit,
@@ -35,10 +38,11 @@ func UpdateStatusEventProvider(accountId uint32, credit uint32, points uint32, p
 	return producer.SingleMessageProvider(key, value)
 }
 
-func DeleteStatusEventProvider(accountId uint32) model.Provider[[]kafka.Message] {
+func DeleteStatusEventProvider(requestId uuid.UUID, accountId uint32) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(accountId))
 	value := &wallet.StatusEvent[wallet.StatusEventDeletedBody]{
 		AccountId: accountId,
+		RequestId: requestId,
 		Type:      wallet.StatusEventTypeDeleted,
 		Body:      wallet.StatusEventDeletedBody{},
 	}
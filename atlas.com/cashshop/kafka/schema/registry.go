@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/kafka/message/cashshop/compartment"
+	"strconv"
+	"sync"
+
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-kafka/topic"
+	"github.com/sirupsen/logrus"
+)
+
+// schemas holds the topics whose messages have moved past the initial version, keyed by topic token
+// The consumed command topics carry the upgraders which bring commands of older producers up to date
+var schemas = map[string]Schema{
+	cashshop.EnvCommandTopic:     NewSchema(cashshop.CommandVersion, map[uint32]Upgrader{1: liftRequestId}),
+	compartment.EnvCommandTopic:  NewSchema(compartment.CommandVersion, map[uint32]Upgrader{1: liftRequestId}),
+	cashshop.EnvEventTopicStatus: NewSchema(cashshop.StatusEventVersion, nil),
+}
+
+// resolved holds the registered schemas keyed by topic name
+var resolved struct {
+	once    sync.Once
+	schemas map[string]Schema
+}
+
+// ForToken returns the schema of the topic, which is at the initial version unless registered
+func ForToken(token string) Schema {
+	if s, ok := schemas[token]; ok {
+		return s
+	}
+	return NewSchema(InitialVersion, nil)
+}
+
+// ForTopic returns the schema of the topic with the resolved name
+// Topic names are resolved from the environment on first use only, as they do not change while the service runs
+func ForTopic(l logrus.FieldLogger) func(name string) Schema {
+	return func(name string) Schema {
+		resolved.once.Do(func() {
+			resolved.schemas = make(map[string]Schema, len(schemas))
+			for token, s := range schemas {
+				if t, err := topic.EnvProvider(l)(token)(); err == nil && t != "" {
+					resolved.schemas[t] = s
+				}
+			}
+		})
+		if s, ok := resolved.schemas[name]; ok {
+			return s
+		}
+		return NewSchema(InitialVersion, nil)
+	}
+}
+
+// Version returns the version this service writes messages to the topic with
+func Version(token string) uint32 {
+	return ForToken(token).Version()
+}
+
+// HeaderDecorator stamps produced messages with the version of the topic
+func HeaderDecorator(token string) producer.HeaderDecorator {
+	return func() (map[string]string, error) {
+		return map[string]string{HeaderVersion: strconv.FormatUint(uint64(Version(token)), 10)}, nil
+	}
+}
+
+// WithVersion returns a copy of the headers stamped with the version of the topic
+func WithVersion(token string, headers map[string]string) map[string]string {
+	result := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		result[k] = v
+	}
+	result[HeaderVersion] = strconv.FormatUint(uint64(Version(token)), 10)
+	return result
+}
+
+// Upgrade brings a consumed message up to the version of its topic this service understands
+func Upgrade(l logrus.FieldLogger) func(name string, version uint32, value []byte) ([]byte, error) {
+	return func(name string, version uint32, value []byte) ([]byte, error) {
+		s := ForTopic(l)(name)
+		if version > s.Version() {
+			l.Debugf("Message on [%s] was written with version [%d], newer than [%d]. Decoding the fields known to this version.", name, version, s.Version())
+		}
+		return s.Upgrade(version, value)
+	}
+}
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderVersion  = "SCHEMA_VERSION"
+	InitialVersion = uint32(1)
+)
+
+// Upgrader rewrites a message value written with one version into the version after it
+type Upgrader func(value []byte) ([]byte, error)
+
+// Schema describes the versions of the messages written to a topic
+// A change which only adds fields bumps the version without an upgrader, as older values decode as they are. Renaming, removing or reshaping a field requires an upgrader from the version before it
+type Schema struct {
+	version   uint32
+	upgraders map[uint32]Upgrader
+}
+
+// NewSchema creates a schema at version, where upgraders are keyed by the version they upgrade from
+func NewSchema(version uint32, upgraders map[uint32]Upgrader) Schema {
+	if upgraders == nil {
+		upgraders = make(map[uint32]Upgrader)
+	}
+	return Schema{version: version, upgraders: upgraders}
+}
+
+func (s Schema) Version() uint32 {
+	return s.version
+}
+
+// Upgrade brings a value written with an older version up to the current version
+// Values written with a newer version are returned as they are. Their added fields are ignored when decoded, so producers can be deployed ahead of consumers
+func (s Schema) Upgrade(version uint32, value []byte) ([]byte, error) {
+	var err error
+	for v := version; v < s.version; v++ {
+		u, ok := s.upgraders[v]
+		if !ok {
+			continue
+		}
+		if value, err = u(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// VersionOf returns the version a message was written with. Messages without the header predate versioning and are treated as the initial version
+func VersionOf(headers []kafka.Header) uint32 {
+	for _, h := range headers {
+		if h.Key != HeaderVersion {
+			continue
+		}
+		v, err := strconv.ParseUint(string(h.Value), 10, 32)
+		if err != nil || v == 0 {
+			return InitialVersion
+		}
+		return uint32(v)
+	}
+	return InitialVersion
+}
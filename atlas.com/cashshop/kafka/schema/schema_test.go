@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"atlas-cashshop/kafka/message/cashshop"
+	"atlas-cashshop/kafka/message/cashshop/compartment"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSchema_Upgrade(t *testing.T) {
+	s := NewSchema(4, map[uint32]Upgrader{
+		1: func(value []byte) ([]byte, error) { return append(value, 'b'), nil },
+		3: func(value []byte) ([]byte, error) { return append(value, 'd'), nil },
+	})
+
+	cases := []struct {
+		version  uint32
+		expected string
+	}{
+		{1, "abd"},
+		{2, "ad"},
+		{3, "ad"},
+		{4, "a"},
+		{5, "a"},
+	}
+	for _, c := range cases {
+		v, err := s.Upgrade(c.version, []byte("a"))
+		if err != nil {
+			t.Fatalf("Unexpected error upgrading version [%d]: %v", c.version, err)
+		}
+		if string(v) != c.expected {
+			t.Errorf("Expected [%s] upgrading version [%d], got [%s]", c.expected, c.version, string(v))
+		}
+	}
+}
+
+func TestSchema_UpgradeFailure(t *testing.T) {
+	cause := errors.New("malformed")
+	s := NewSchema(2, map[uint32]Upgrader{
+		1: func(value []byte) ([]byte, error) { return nil, cause },
+	})
+	if _, err := s.Upgrade(1, []byte("a")); !errors.Is(err, cause) {
+		t.Errorf("Expected upgrader error, got %v", err)
+	}
+}
+
+func TestVersionOf(t *testing.T) {
+	cases := []struct {
+		headers  []kafka.Header
+		expected uint32
+	}{
+		{nil, InitialVersion},
+		{[]kafka.Header{{Key: "TENANT_ID", Value: []byte("x")}}, InitialVersion},
+		{[]kafka.Header{{Key: HeaderVersion, Value: []byte("3")}}, 3},
+		{[]kafka.Header{{Key: HeaderVersion, Value: []byte("0")}}, InitialVersion},
+		{[]kafka.Header{{Key: HeaderVersion, Value: []byte("x")}}, InitialVersion},
+	}
+	for _, c := range cases {
+		if v := VersionOf(c.headers); v != c.expected {
+			t.Errorf("Expected version [%d] for headers %v, got [%d]", c.expected, c.headers, v)
+		}
+	}
+}
+
+func TestLiftRequestId(t *testing.T) {
+	requestId := uuid.New()
+	v1 := fmt.Sprintf(`{"characterId":2000,"type":"REQUEST_PURCHASE","body":{"requestId":"%s","currency":1,"serialNumber":10000001}}`, requestId)
+
+	value, err := ForToken(cashshop.EnvCommandTopic).Upgrade(1, []byte(v1))
+	if err != nil {
+		t.Fatalf("Unable to upgrade command: %v", err)
+	}
+	var c cashshop.Command[map[string]interface{}]
+	if err = json.Unmarshal(value, &c); err != nil {
+		t.Fatalf("Unable to decode upgraded command: %v", err)
+	}
+	if c.RequestId != requestId || c.CharacterId != 2000 || c.Body["serialNumber"] != float64(10000001) {
+		t.Errorf("Unexpected upgraded command %+v", c)
+	}
+	if _, ok := c.Body["requestId"]; ok {
+		t.Errorf("Expected the requestId to leave the body, got %v", c.Body)
+	}
+
+	// A version 2 command is decoded as it is
+	v2 := fmt.Sprintf(`{"accountId":1000,"requestId":"%s","type":"DISCARD","body":{"requestId":"other"}}`, requestId)
+	if value, err = ForToken(compartment.EnvCommandTopic).Upgrade(compartment.CommandVersion, []byte(v2)); err != nil || string(value) != v2 {
+		t.Errorf("Expected a current command to be left alone, got [%s] [%v]", value, err)
+	}
+}
+
+func TestForTopic(t *testing.T) {
+	t.Setenv(cashshop.EnvCommandTopic, "cash-shop-command")
+	if v := ForTopic(logrus.New())("cash-shop-command").Version(); v != cashshop.CommandVersion {
+		t.Errorf("Expected version [%d] of the cash shop command topic, got [%d]", cashshop.CommandVersion, v)
+	}
+	if v := ForTopic(logrus.New())("unregistered").Version(); v != InitialVersion {
+		t.Errorf("Expected unregistered topics at the initial version, got [%d]", v)
+	}
+}
@@ -0,0 +1,34 @@
+package schema
+
+import "encoding/json"
+
+// liftRequestId moves the requestId a version 1 command carried in its body up to the command itself
+// A requestId already set on the command is kept
+func liftRequestId(value []byte) ([]byte, error) {
+	var command map[string]json.RawMessage
+	if err := json.Unmarshal(value, &command); err != nil {
+		return nil, err
+	}
+	raw, ok := command["body"]
+	if !ok {
+		return value, nil
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, err
+	}
+	requestId, ok := body["requestId"]
+	if !ok {
+		return value, nil
+	}
+	delete(body, "requestId")
+	if _, ok = command["requestId"]; !ok {
+		command["requestId"] = requestId
+	}
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	command["body"] = raw
+	return json.Marshal(command)
+}
@@ -1,18 +1,28 @@
 package main
 
 import (
+	account2 "atlas-cashshop/account"
+	"atlas-cashshop/account/archive"
+	cashshop2 "atlas-cashshop/cashshop"
+	"atlas-cashshop/cashshop/grant"
 	"atlas-cashshop/cashshop/inventory"
 	"atlas-cashshop/cashshop/inventory/asset"
+	"atlas-cashshop/cashshop/inventory/asset/reservation"
 	"atlas-cashshop/cashshop/inventory/compartment"
 	item2 "atlas-cashshop/cashshop/item"
 	"atlas-cashshop/database"
+	"atlas-cashshop/database/migrations"
+	"atlas-cashshop/deadletter"
 	"atlas-cashshop/kafka/consumer/account"
 	"atlas-cashshop/kafka/consumer/cashshop"
 	compartment2 "atlas-cashshop/kafka/consumer/cashshop/compartment"
 	"atlas-cashshop/kafka/consumer/character"
+	deadletterConsumer "atlas-cashshop/kafka/consumer/deadletter"
 	itemConsumer "atlas-cashshop/kafka/consumer/item"
 	"atlas-cashshop/logger"
+	"atlas-cashshop/outbox"
 	"atlas-cashshop/service"
+	"atlas-cashshop/tasks"
 	"atlas-cashshop/tracing"
 	"atlas-cashshop/wallet"
 	"atlas-cashshop/wishlist"
@@ -46,6 +56,10 @@ func GetServer() Server {
 
 func main() {
 	l := logger.CreateLogger(serviceName)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrations.Command(l, os.Args[2:]))
+	}
+
 	l.Infoln("Starting main service.")
 
 	tdm := service.GetTeardownManager()
@@ -55,7 +69,7 @@ func main() {
 		l.WithError(err).Fatal("Unable to initialize tracer.")
 	}
 
-	db := database.Connect(l, database.SetMigrations(wallet.Migration, wishlist.Migration, item2.Migration, compartment.Migration, asset.Migration))
+	db := database.Connect(l, database.SetMigrations(migrations.All()...))
 
 	cmf := consumer.GetManager().AddConsumer(l, tdm.Context(), tdm.WaitGroup())
 	account.InitConsumers(l)(cmf)(consumerGroupId)
@@ -63,11 +77,17 @@ func main() {
 	compartment2.InitConsumers(l)(cmf)(consumerGroupId)
 	cashshop.InitConsumers(l)(cmf)(consumerGroupId)
 	itemConsumer.InitConsumers(l)(cmf)(consumerGroupId)
+	deadletterConsumer.InitConsumers(l)(cmf)(consumerGroupId)
 	account.InitHandlers(l)(db)(consumer.GetManager().RegisterHandler)
 	character.InitHandlers(l)(db)(consumer.GetManager().RegisterHandler)
 	compartment2.InitHandlers(l)(db)(consumer.GetManager().RegisterHandler)
 	cashshop.InitHandlers(l)(db)(consumer.GetManager().RegisterHandler)
 	itemConsumer.InitHandlers(l)(db)(consumer.GetManager().RegisterHandler)
+	deadletterConsumer.InitHandlers(l)(db)(consumer.GetManager().RegisterHandler)
+
+	tasks.Register(l, tdm.Context(), tdm.WaitGroup())(reservation.NewExpirationTask(l, reservation.NewDatabaseStore(db, reservation.TTL()), reservation.ExpirationTaskInterval))
+	tasks.Register(l, tdm.Context(), tdm.WaitGroup())(archive.NewRetentionTask(l, db, archive.RetentionTaskInterval))
+	tasks.Register(l, tdm.Context(), tdm.WaitGroup())(outbox.NewRelayTask(l, db, outbox.RelayInterval()))
 
 	server.New(l).
 		WithContext(tdm.Context()).
@@ -80,6 +100,10 @@ func main() {
 		AddRouteInitializer(compartment.InitResource(GetServer())(db)).
 		AddRouteInitializer(asset.InitResource(GetServer())(db)).
 		AddRouteInitializer(inventory.InitResource(GetServer())(db)).
+		AddRouteInitializer(account2.InitResource(GetServer())(db)).
+		AddRouteInitializer(deadletter.InitResource(GetServer())(db)).
+		AddRouteInitializer(cashshop2.InitResource(GetServer())(db)).
+		AddRouteInitializer(grant.InitResource(GetServer())(db)).
 		Run()
 
 	tdm.TeardownFunc(tracing.Teardown(l)(tc))
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	StatusPending = "PENDING"
+	StatusSent    = "SENT"
+)
+
+// createEntities stores messages destined for a topic in the order given
+func createEntities(db *gorm.DB, tenantId uuid.UUID, token string, headers map[string]string, ms []kafka.Message) error {
+	if len(ms) == 0 {
+		return nil
+	}
+	hs, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	entities := make([]Entity, 0, len(ms))
+	for _, m := range ms {
+		entities = append(entities, Entity{
+			TenantId:      tenantId,
+			Topic:         token,
+			Key:           m.Key,
+			Value:         m.Value,
+			Headers:       string(hs),
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	return db.Create(&entities).Error
+}
+
+// markClaimed defers the next attempt of claimed messages until the lease ends, so no other replica picks them up while they are published
+func markClaimed(db *gorm.DB, ids []uint64, until time.Time) error {
+	return db.Model(&Entity{}).Where("id IN ?", ids).Update("next_attempt_at", until).Error
+}
+
+// markSent records that a message was published
+func markSent(db *gorm.DB, id uint64, now time.Time) error {
+	return db.Model(&Entity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":  StatusSent,
+		"sent_at": now,
+	}).Error
+}
+
+// markFailed records a failed publication and when it should be attempted again
+func markFailed(db *gorm.DB, id uint64, attempts uint32, cause error, next time.Time) error {
+	return db.Model(&Entity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      cause.Error(),
+		"next_attempt_at": next,
+	}).Error
+}
+
+// deleteAllSentBefore purges published messages older than the cutoff
+func deleteAllSentBefore(db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.Where("status = ? AND sent_at < ?", StatusSent, cutoff).Delete(&Entity{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"atlas-cashshop/database"
+	"atlas-cashshop/kafka/message"
+	"atlas-cashshop/kafka/producer"
+	"atlas-cashshop/kafka/schema"
+	"context"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Emit runs f within a transaction and writes the buffered messages to the outbox as part of that transaction
+// The messages are published by the relay once the transaction commits, so they are neither lost nor sent for rolled back work
+// The transaction runs with the context, so later reads of the same request see its writes
+func Emit(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) func(f func(tx *gorm.DB, buf *message.Buffer) error) error {
+	return func(f func(tx *gorm.DB, buf *message.Buffer) error) error {
+		return database.ExecuteTransaction(db.WithContext(ctx), func(tx *gorm.DB) error {
+			buf := message.NewBuffer()
+			if err := f(tx, buf); err != nil {
+				return err
+			}
+			return write(l, ctx, tx, buf)
+		})
+	}
+}
+
+// EmitWithResult runs f within a transaction and writes the buffered messages to the outbox as part of that transaction
+func EmitWithResult[M any](l logrus.FieldLogger, ctx context.Context, db *gorm.DB) func(f func(tx *gorm.DB, buf *message.Buffer) (M, error)) (M, error) {
+	return func(f func(tx *gorm.DB, buf *message.Buffer) (M, error)) (M, error) {
+		var result M
+		err := database.ExecuteTransaction(db.WithContext(ctx), func(tx *gorm.DB) error {
+			buf := message.NewBuffer()
+			r, err := f(tx, buf)
+			if err != nil {
+				return err
+			}
+			result = r
+			return write(l, ctx, tx, buf)
+		})
+		return result, err
+	}
+}
+
+// write stores the contents of the buffer along with the span and tenant headers of the context
+// Each topic's messages are stamped with the schema version they were written with, which may no longer be current by the time they are relayed
+func write(l logrus.FieldLogger, ctx context.Context, tx *gorm.DB, buf *message.Buffer) error {
+	all := buf.GetAll()
+	if len(all) == 0 {
+		return nil
+	}
+	headers, err := producer.Headers(ctx)
+	if err != nil {
+		return err
+	}
+	t := tenant.MustFromContext(ctx)
+	for token, ms := range all {
+		if err = createEntities(tx, t.Id(), token, schema.WithVersion(token, headers), ms); err != nil {
+			l.WithError(err).Errorf("Unable to write [%d] messages for [%s] to the outbox.", len(ms), token)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Entity represents a Kafka message awaiting publication
+// The auto incremented id preserves the order in which messages were written
+type Entity struct {
+	Id            uint64     `gorm:"primaryKey;autoIncrement:true"`
+	TenantId      uuid.UUID  `gorm:"type:uuid;not null"`
+	Topic         string     `gorm:"not null"`
+	Key           []byte     `gorm:""`
+	Value         []byte     `gorm:"not null"`
+	Headers       string     `gorm:"type:text;not null"`
+	Status        string     `gorm:"not null;index:idx_cash_outbox_pending,priority:1"`
+	Attempts      uint32     `gorm:"not null"`
+	LastError     string     `gorm:"type:text"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_cash_outbox_pending,priority:2"`
+	CreatedAt     time.Time  `gorm:"not null"`
+	SentAt        *time.Time `gorm:""`
+}
+
+// TableName returns the database table name for this entity
+func (e Entity) TableName() string {
+	return "cash_outbox"
+}
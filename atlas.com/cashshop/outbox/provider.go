@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"atlas-cashshop/database"
+	"github.com/Chronicle20/atlas-model/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// getPendingProvider locks the oldest messages which are due for publication and are the first unsent message of their topic and key
+// A later message waits while an earlier one of its key is pending, whether backing off or claimed by another replica, so per key ordering survives retries
+// Rows locked by another replica are skipped so each message is relayed by a single instance at a time
+func getPendingProvider(now time.Time) func(limit int) database.EntityProvider[[]Entity] {
+	return func(limit int) database.EntityProvider[[]Entity] {
+		return func(db *gorm.DB) model.Provider[[]Entity] {
+			return func() ([]Entity, error) {
+				var entities []Entity
+				result := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+					Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+					Where("NOT EXISTS (SELECT 1 FROM cash_outbox earlier WHERE earlier.status = ? AND earlier.topic = cash_outbox.topic AND (earlier.key = cash_outbox.key OR (earlier.key IS NULL AND cash_outbox.key IS NULL)) AND earlier.id < cash_outbox.id)", StatusPending).
+					Order("id").
+					Limit(limit).
+					Find(&entities)
+				return entities, result.Error
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package outbox
+
+import (
+	"atlas-cashshop/database"
+	"atlas-cashshop/kafka/producer"
+	"encoding/json"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"os"
+	"time"
+)
+
+const (
+	EnvRelayInterval     = "OUTBOX_RELAY_INTERVAL"
+	DefaultRelayInterval = 250 * time.Millisecond
+	EnvRetention         = "OUTBOX_RETENTION"
+	DefaultRetention     = 24 * time.Hour
+	RelayBatchSize       = 100
+	PurgeInterval        = time.Minute
+	InitialBackoff       = time.Second
+	MaxBackoff           = 5 * time.Minute
+	ClaimLease           = 30 * time.Second
+)
+
+// RelayInterval returns how often pending messages are published, falling back to DefaultRelayInterval
+func RelayInterval() time.Duration {
+	return durationFromEnv(EnvRelayInterval, DefaultRelayInterval)
+}
+
+// Retention returns how long published messages are kept, falling back to DefaultRetention
+func Retention() time.Duration {
+	return durationFromEnv(EnvRetention, DefaultRetention)
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+// Backoff returns the delay before the next publication attempt, doubling per attempt up to MaxBackoff
+func Backoff(attempts uint32) time.Duration {
+	d := InitialBackoff
+	for i := uint32(1); i < attempts && d < MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > MaxBackoff {
+		return MaxBackoff
+	}
+	return d
+}
+
+// RelayTask publishes pending outbox messages and marks them sent
+type RelayTask struct {
+	l         logrus.FieldLogger
+	db        *gorm.DB
+	p         producer.HeaderProvider
+	interval  time.Duration
+	retention time.Duration
+	lastPurge time.Time
+}
+
+// NewRelayTask creates a task which relays the outbox to Kafka every interval
+func NewRelayTask(l logrus.FieldLogger, db *gorm.DB, interval time.Duration) *RelayTask {
+	return NewRelayTaskWithProvider(l, db, interval, producer.HeaderProviderImpl(l))
+}
+
+// NewRelayTaskWithProvider creates a task which relays the outbox through p every interval
+func NewRelayTaskWithProvider(l logrus.FieldLogger, db *gorm.DB, interval time.Duration, p producer.HeaderProvider) *RelayTask {
+	return &RelayTask{
+		l:         l,
+		db:        db,
+		p:         p,
+		interval:  interval,
+		retention: Retention(),
+	}
+}
+
+// Run publishes due messages until RelayBatchSize have been attempted or none remain, then purges old published messages
+// Each round claims the head message of every key, so a key advances by one message per round
+func (t *RelayTask) Run() {
+	now := time.Now()
+	for attempted := 0; attempted < RelayBatchSize; {
+		es, err := t.claim(RelayBatchSize - attempted)
+		if err != nil {
+			t.l.WithError(err).Errorf("Unable to relay outbox messages.")
+			return
+		}
+		if len(es) == 0 {
+			break
+		}
+		for _, e := range es {
+			t.relay(e)
+		}
+		attempted += len(es)
+	}
+
+	if now.Sub(t.lastPurge) < PurgeInterval {
+		return
+	}
+	t.lastPurge = now
+	count, err := deleteAllSentBefore(t.db, now.Add(-t.retention))
+	if err != nil {
+		t.l.WithError(err).Errorf("Unable to purge published outbox messages.")
+		return
+	}
+	if count > 0 {
+		t.l.Debugf("Purged [%d] published outbox messages.", count)
+	}
+}
+
+// claim leases due messages for ClaimLease in a short transaction, so they are published without holding row locks
+// A replica which stops before recording the outcome leaves them to be published again once the lease ends
+func (t *RelayTask) claim(limit int) ([]Entity, error) {
+	var es []Entity
+	err := database.ExecuteTransaction(t.db, func(tx *gorm.DB) error {
+		now := time.Now()
+		var err error
+		es, err = getPendingProvider(now)(limit)(tx)()
+		if err != nil || len(es) == 0 {
+			return err
+		}
+		ids := make([]uint64, 0, len(es))
+		for _, e := range es {
+			ids = append(ids, e.Id)
+		}
+		return markClaimed(tx, ids, now.Add(ClaimLease))
+	})
+	return es, err
+}
+
+// relay publishes a claimed message and records the outcome
+// A failed message is retried after a backoff, and holds back later messages with the same topic and key until then
+func (t *RelayTask) relay(e Entity) {
+	if err := t.publish(e); err != nil {
+		attempts := e.Attempts + 1
+		t.l.WithError(err).Warnf("Unable to publish outbox message [%d] to [%s]. Attempt [%d].", e.Id, e.Topic, attempts)
+		if err = markFailed(t.db, e.Id, attempts, err, time.Now().Add(Backoff(attempts))); err != nil {
+			t.l.WithError(err).Errorf("Unable to record failed publication of outbox message [%d].", e.Id)
+		}
+		return
+	}
+	if err := markSent(t.db, e.Id, time.Now()); err != nil {
+		t.l.WithError(err).Errorf("Unable to mark outbox message [%d] sent. It will be published again.", e.Id)
+	}
+}
+
+func (t *RelayTask) publish(e Entity) error {
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(e.Headers), &headers); err != nil {
+		return err
+	}
+	m := kafka.Message{Key: e.Key, Value: e.Value}
+	return t.p(headers)(e.Topic)(model.FixedProvider([]kafka.Message{m}))
+}
+
+func (t *RelayTask) SleepTime() time.Duration {
+	return t.interval
+}
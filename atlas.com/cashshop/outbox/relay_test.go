@@ -0,0 +1,203 @@
+package outbox
+
+import (
+	"atlas-cashshop/database"
+	"atlas-cashshop/database/migrations"
+	"atlas-cashshop/kafka/message"
+	"atlas-cashshop/kafka/producer"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	kafkaProducer "github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempts uint32
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{20, MaxBackoff},
+	}
+	for _, c := range cases {
+		if d := Backoff(c.attempts); d != c.expected {
+			t.Errorf("Expected backoff of [%s] after [%d] attempts, got [%s]", c.expected, c.attempts, d)
+		}
+	}
+}
+
+func TestRelayInterval(t *testing.T) {
+	t.Setenv(EnvRelayInterval, "")
+	if RelayInterval() != DefaultRelayInterval {
+		t.Errorf("Expected default relay interval when unset")
+	}
+	t.Setenv(EnvRelayInterval, "2s")
+	if RelayInterval() != 2*time.Second {
+		t.Errorf("Expected relay interval of 2s")
+	}
+}
+
+const (
+	testTopic      = "EVENT_TOPIC_TEST"
+	testOtherTopic = "EVENT_TOPIC_OTHER"
+)
+
+func newTestDatabase(t *testing.T) *gorm.DB {
+	db, err := database.Open(logrus.New(), database.SetDialector(database.SQLite("file:"+uuid.NewString()+"?mode=memory&cache=shared")), database.SetMigrations(migrations.All()...))
+	if err != nil {
+		t.Fatalf("Unable to open test database: %v", err)
+	}
+	return db
+}
+
+func newTestContext(t *testing.T) context.Context {
+	te, err := tenant.Create(uuid.New(), "GMS", 83, 1)
+	if err != nil {
+		t.Fatalf("Unable to create tenant: %v", err)
+	}
+	return tenant.WithContext(context.Background(), te)
+}
+
+// emitTestMessages writes a message per value, keyed by key, to topic in a single transaction
+func emitTestMessages(t *testing.T, ctx context.Context, db *gorm.DB, topic string, key []byte, values ...string) {
+	err := Emit(logrus.New(), ctx, db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		for _, v := range values {
+			if err := buf.Put(topic, model.FixedProvider([]kafka.Message{{Key: key, Value: []byte(v)}})); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unable to emit messages: %v", err)
+	}
+}
+
+// failingProvider captures messages in m, except those for which fail reports true
+func failingProvider(m *producer.Memory, fail func(kafka.Message) bool) producer.HeaderProvider {
+	return func(headers map[string]string) producer.Provider {
+		return func(token string) kafkaProducer.MessageProducer {
+			return func(p model.Provider[[]kafka.Message]) error {
+				ms, err := p()
+				if err != nil {
+					return err
+				}
+				for _, msg := range ms {
+					if fail(msg) {
+						return errors.New("broker unavailable")
+					}
+				}
+				return m.HeaderProvider(headers)(token)(model.FixedProvider(ms))
+			}
+		}
+	}
+}
+
+func values(ms []kafka.Message) []string {
+	result := make([]string, 0, len(ms))
+	for _, m := range ms {
+		result = append(result, string(m.Value))
+	}
+	return result
+}
+
+func pendingCount(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	if err := db.Model(&Entity{}).Where("status = ?", StatusPending).Count(&count).Error; err != nil {
+		t.Fatalf("Unable to count pending messages: %v", err)
+	}
+	return count
+}
+
+func TestRelayPublishesEmittedMessages(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := newTestContext(t)
+	emitTestMessages(t, ctx, db, testTopic, []byte("a"), "a1", "a2", "a3")
+	emitTestMessages(t, ctx, db, testOtherTopic, nil, "b1", "b2")
+
+	m := producer.NewMemory()
+	NewRelayTaskWithProvider(logrus.New(), db, time.Second, m.HeaderProvider).Run()
+
+	if vs := values(m.Messages(testTopic)); !slices.Equal(vs, []string{"a1", "a2", "a3"}) {
+		t.Errorf("Expected keyed messages to be published in order, got %v", vs)
+	}
+	if vs := values(m.Messages(testOtherTopic)); !slices.Equal(vs, []string{"b1", "b2"}) {
+		t.Errorf("Expected unkeyed messages to be published in order, got %v", vs)
+	}
+	if n := pendingCount(t, db); n != 0 {
+		t.Errorf("Expected every message to be marked sent, [%d] are pending", n)
+	}
+}
+
+func TestRelayRetriesFailedMessageInOrder(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := newTestContext(t)
+	emitTestMessages(t, ctx, db, testTopic, []byte("a"), "a1", "a2")
+	emitTestMessages(t, ctx, db, testTopic, []byte("b"), "b1")
+
+	m := producer.NewMemory()
+	broken := true
+	p := failingProvider(m, func(msg kafka.Message) bool {
+		return broken && string(msg.Value) == "a1"
+	})
+	rt := NewRelayTaskWithProvider(logrus.New(), db, time.Second, p)
+	rt.Run()
+
+	if vs := values(m.Drain(testTopic)); !slices.Equal(vs, []string{"b1"}) {
+		t.Fatalf("Expected only the message of the other key to be published, got %v", vs)
+	}
+	var failed Entity
+	if err := db.Where("value = ?", []byte("a1")).First(&failed).Error; err != nil {
+		t.Fatalf("Unable to retrieve failed message: %v", err)
+	}
+	if failed.Status != StatusPending || failed.Attempts != 1 || failed.LastError == "" || !failed.NextAttemptAt.After(time.Now()) {
+		t.Errorf("Expected failed message to await a retry, got %+v", failed)
+	}
+
+	// The retry becomes due while a2 has long been due, and a2 still waits for it
+	broken = false
+	if err := db.Model(&Entity{}).Where("id = ?", failed.Id).Update("next_attempt_at", time.Now()).Error; err != nil {
+		t.Fatalf("Unable to expire backoff: %v", err)
+	}
+	rt.Run()
+
+	if vs := values(m.Drain(testTopic)); !slices.Equal(vs, []string{"a1", "a2"}) {
+		t.Errorf("Expected the retried message to be published before the later one, got %v", vs)
+	}
+	if n := pendingCount(t, db); n != 0 {
+		t.Errorf("Expected every message to be marked sent, [%d] are pending", n)
+	}
+}
+
+func TestRelaySkipsKeyClaimedByAnotherReplica(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := newTestContext(t)
+	emitTestMessages(t, ctx, db, testTopic, []byte("a"), "a1", "a2")
+	emitTestMessages(t, ctx, db, testTopic, []byte("b"), "b1")
+
+	var claimed Entity
+	if err := db.Where("value = ?", []byte("a1")).First(&claimed).Error; err != nil {
+		t.Fatalf("Unable to retrieve message: %v", err)
+	}
+	if err := markClaimed(db, []uint64{claimed.Id}, time.Now().Add(ClaimLease)); err != nil {
+		t.Fatalf("Unable to claim message: %v", err)
+	}
+
+	m := producer.NewMemory()
+	NewRelayTaskWithProvider(logrus.New(), db, time.Second, m.HeaderProvider).Run()
+
+	if vs := values(m.Messages(testTopic)); !slices.Equal(vs, []string{"b1"}) {
+		t.Errorf("Expected messages behind a claimed one to wait, got %v", vs)
+	}
+}
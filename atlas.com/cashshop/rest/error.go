@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	CodeInvalidParameter = "INVALID_PARAMETER"
+	CodeInvalidBody      = "INVALID_BODY"
+	CodeInvalidAttribute = "INVALID_ATTRIBUTE"
+	CodeNotFound         = "NOT_FOUND"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+// Error is a failure reported to a client as a JSON:API error object
+type Error struct {
+	status int
+	code   string
+	title  string
+	detail string
+	source *ErrorSource
+}
+
+// ErrorSource points at the part of the request which caused an error
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// ErrorObject describes one error of an ErrorDocument
+type ErrorObject struct {
+	Status string       `json:"status"`
+	Code   string       `json:"code"`
+	Title  string       `json:"title"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// ErrorDocument is the body of a failed response
+type ErrorDocument struct {
+	Errors []ErrorObject `json:"errors"`
+}
+
+func NewError(status int, code string, title string) Error {
+	return Error{status: status, code: code, title: title}
+}
+
+func (e Error) Error() string {
+	if e.detail != "" {
+		return e.title + ": " + e.detail
+	}
+	return e.title
+}
+
+func (e Error) Status() int {
+	return e.status
+}
+
+func (e Error) Code() string {
+	return e.code
+}
+
+// WithDetail explains this occurrence of the error
+func (e Error) WithDetail(detail string) Error {
+	e.detail = detail
+	return e
+}
+
+// WithPointer blames the member of the request document at the JSON pointer, such as /data/attributes/credit
+func (e Error) WithPointer(pointer string) Error {
+	e.source = &ErrorSource{Pointer: pointer}
+	return e
+}
+
+// WithParameter blames a path or query parameter
+func (e Error) WithParameter(parameter string) Error {
+	e.source = &ErrorSource{Parameter: parameter}
+	return e
+}
+
+func (e Error) object() ErrorObject {
+	return ErrorObject{
+		Status: strconv.Itoa(e.status),
+		Code:   e.code,
+		Title:  e.title,
+		Detail: e.detail,
+		Source: e.source,
+	}
+}
+
+var ErrNotFound = NewError(http.StatusNotFound, CodeNotFound, "Resource not found")
+var ErrInternal = NewError(http.StatusInternalServerError, CodeInternal, "Internal error")
+
+// InvalidParameter reports a path or query parameter which could not be parsed
+func InvalidParameter(parameter string) Error {
+	return NewError(http.StatusBadRequest, CodeInvalidParameter, "Invalid parameter").WithParameter(parameter)
+}
+
+// InvalidBody reports a request document which could not be read or decoded
+func InvalidBody(err error) Error {
+	return NewError(http.StatusBadRequest, CodeInvalidBody, "Invalid request body").WithDetail(err.Error())
+}
+
+// InvalidAttribute reports an attribute of the request document whose value cannot be accepted
+func InvalidAttribute(attribute string, detail string) Error {
+	return NewError(http.StatusUnprocessableEntity, CodeInvalidAttribute, "Invalid attribute").WithDetail(detail).WithPointer("/data/attributes/" + attribute)
+}
+
+type registeredError struct {
+	target error
+	e      Error
+}
+
+var registeredErrors []registeredError
+var registeredErrorsLock sync.RWMutex
+
+// RegisterError reports target, and any error wrapping it, to clients with the given status, code and title
+// Domain packages register their errors alongside their resources. Registering a target again replaces its mapping
+func RegisterError(target error, status int, code string, title string) {
+	registeredErrorsLock.Lock()
+	defer registeredErrorsLock.Unlock()
+	e := NewError(status, code, title)
+	for i, r := range registeredErrors {
+		if r.target == target {
+			registeredErrors[i].e = e
+			return
+		}
+	}
+	registeredErrors = append(registeredErrors, registeredError{target: target, e: e})
+}
+
+// ErrorFor translates err into the error reported to the client
+// Errors nobody registered are reported as internal errors without detail, so no internals are leaked
+func ErrorFor(err error) Error {
+	var e Error
+	if errors.As(err, &e) {
+		return e
+	}
+	registeredErrorsLock.RLock()
+	defer registeredErrorsLock.RUnlock()
+	for _, r := range registeredErrors {
+		if errors.Is(err, r.target) {
+			return r.e.WithDetail(r.target.Error())
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return ErrInternal
+}
+
+// WriteError writes the JSON:API error document for err with its status
+func WriteError(l logrus.FieldLogger) func(w http.ResponseWriter) func(err error) {
+	return func(w http.ResponseWriter) func(err error) {
+		return func(err error) {
+			e := ErrorFor(err)
+			if e.status >= http.StatusInternalServerError {
+				l.WithError(err).Errorf("Request failed with [%d].", e.status)
+			} else {
+				l.WithError(err).Debugf("Request rejected with [%d] [%s].", e.status, e.code)
+			}
+
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.WriteHeader(e.status)
+			if err := json.NewEncoder(w).Encode(ErrorDocument{Errors: []ErrorObject{e.object()}}); err != nil {
+				l.WithError(err).Errorf("Unable to write error document.")
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func writeError(t *testing.T, err error) (int, ErrorObject) {
+	w := httptest.NewRecorder()
+	WriteError(logrus.New())(w)(err)
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.api+json" {
+		t.Errorf("Expected JSON:API content type, got [%s]", ct)
+	}
+	var doc ErrorDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Unable to decode error document: %v", err)
+	}
+	if len(doc.Errors) != 1 {
+		t.Fatalf("Expected 1 error object, got [%d]", len(doc.Errors))
+	}
+	return w.Code, doc.Errors[0]
+}
+
+func TestWriteError(t *testing.T) {
+	errTaken := errors.New("name taken")
+	RegisterError(errTaken, http.StatusConflict, "NAME_TAKEN", "Name taken")
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"registered", errTaken, http.StatusConflict, "NAME_TAKEN", "name taken"},
+		{"wrapped", fmt.Errorf("creating widget: %w", errTaken), http.StatusConflict, "NAME_TAKEN", "name taken"},
+		{"not found", gorm.ErrRecordNotFound, http.StatusNotFound, CodeNotFound, ""},
+		{"unknown", errors.New("connection reset"), http.StatusInternalServerError, CodeInternal, ""},
+		{"rest error", InvalidAttribute("credit", "too much"), http.StatusUnprocessableEntity, CodeInvalidAttribute, "too much"},
+	}
+	for _, c := range cases {
+		status, o := writeError(t, c.err)
+		if status != c.status || o.Status != fmt.Sprint(c.status) || o.Code != c.code || o.Detail != c.detail {
+			t.Errorf("[%s] expected [%d] [%s] [%s], got [%d] %+v", c.name, c.status, c.code, c.detail, status, o)
+		}
+	}
+}
+
+func TestWriteErrorSource(t *testing.T) {
+	_, o := writeError(t, InvalidParameter("accountId"))
+	if o.Source == nil || o.Source.Parameter != "accountId" {
+		t.Errorf("Expected the accountId parameter to be blamed, got %+v", o.Source)
+	}
+	_, o = writeError(t, InvalidAttribute("credit", "too much"))
+	if o.Source == nil || o.Source.Pointer != "/data/attributes/credit" {
+		t.Errorf("Expected the credit attribute to be blamed, got %+v", o.Source)
+	}
+}
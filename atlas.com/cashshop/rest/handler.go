@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"atlas-cashshop/database"
 	"context"
 	"github.com/Chronicle20/atlas-rest/server"
 	"github.com/google/uuid"
@@ -43,7 +44,7 @@ func ParseInput[M any](d *HandlerDependency, c *HandlerContext, next InputHandle
 
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(d.l)(w)(InvalidBody(err))
 			return
 		}
 		defer r.Body.Close()
@@ -51,7 +52,7 @@ func ParseInput[M any](d *HandlerDependency, c *HandlerContext, next InputHandle
 		err = jsonapi.Unmarshal(body, &model)
 		if err != nil {
 			d.l.WithError(err).Errorln("Deserializing input", err)
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(d.l)(w)(InvalidBody(err))
 			return
 		}
 		next(d, c, model)(w, r)
@@ -64,7 +65,7 @@ func RegisterHandler(l logrus.FieldLogger) func(si jsonapi.ServerInformation) fu
 			return server.RetrieveSpan(l, handlerName, context.Background(), func(sl logrus.FieldLogger, sctx context.Context) http.HandlerFunc {
 				fl := sl.WithFields(logrus.Fields{"originator": handlerName, "type": "rest_handler"})
 				return server.ParseTenant(fl, sctx, func(tl logrus.FieldLogger, tctx context.Context) http.HandlerFunc {
-					return handler(&HandlerDependency{l: tl, ctx: tctx}, &HandlerContext{si: si})
+					return handler(&HandlerDependency{l: tl, ctx: database.WithReadYourWrites(tctx)}, &HandlerContext{si: si})
 				})
 			})
 		}
@@ -77,7 +78,7 @@ func RegisterInputHandler[M any](l logrus.FieldLogger) func(si jsonapi.ServerInf
 			return server.RetrieveSpan(l, handlerName, context.Background(), func(sl logrus.FieldLogger, sctx context.Context) http.HandlerFunc {
 				fl := sl.WithFields(logrus.Fields{"originator": handlerName, "type": "rest_handler"})
 				return server.ParseTenant(fl, sctx, func(tl logrus.FieldLogger, tctx context.Context) http.HandlerFunc {
-					return ParseInput[M](&HandlerDependency{l: tl, ctx: tctx}, &HandlerContext{si: si}, handler)
+					return ParseInput[M](&HandlerDependency{l: tl, ctx: database.WithReadYourWrites(tctx)}, &HandlerContext{si: si}, handler)
 				})
 			})
 		}
@@ -91,7 +92,7 @@ func ParseAccountId(l logrus.FieldLogger, next AccountIdHandler) http.HandlerFun
 		accountId, err := strconv.Atoi(mux.Vars(r)["accountId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse accountId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(l)(w)(InvalidParameter("accountId").WithDetail(err.Error()))
 			return
 		}
 		next(uint32(accountId))(w, r)
@@ -105,7 +106,7 @@ func ParseCharacterId(l logrus.FieldLogger, next CharacterIdHandler) http.Handle
 		characterId, err := strconv.Atoi(mux.Vars(r)["characterId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse characterId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(l)(w)(InvalidParameter("characterId").WithDetail(err.Error()))
 			return
 		}
 		next(uint32(characterId))(w, r)
@@ -119,7 +120,7 @@ func ParseWalletId(l logrus.FieldLogger, next WalletIdHandler) http.HandlerFunc
 		walletId, err := uuid.Parse(mux.Vars(r)["walletId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse walletId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(l)(w)(InvalidParameter("walletId").WithDetail(err.Error()))
 			return
 		}
 		next(walletId)(w, r)
@@ -133,7 +134,7 @@ func ParseItemId(l logrus.FieldLogger, next ItemIdHandler) http.HandlerFunc {
 		itemId, err := uuid.Parse(mux.Vars(r)["itemId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse itemId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(l)(w)(InvalidParameter("itemId").WithDetail(err.Error()))
 			return
 		}
 		next(itemId)(w, r)
@@ -147,7 +148,7 @@ func ParseCompartmentId(l logrus.FieldLogger, next CompartmentIdHandler) http.Ha
 		compartmentId, err := uuid.Parse(mux.Vars(r)["compartmentId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse compartmentId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(l)(w)(InvalidParameter("compartmentId").WithDetail(err.Error()))
 			return
 		}
 		next(compartmentId)(w, r)
@@ -161,9 +162,37 @@ func ParseAssetId(l logrus.FieldLogger, next AssetIdHandler) http.HandlerFunc {
 		assetId, err := uuid.Parse(mux.Vars(r)["assetId"])
 		if err != nil {
 			l.WithError(err).Errorf("Unable to properly parse assetId from path.")
-			w.WriteHeader(http.StatusBadRequest)
+			WriteError(l)(w)(InvalidParameter("assetId").WithDetail(err.Error()))
 			return
 		}
 		next(assetId)(w, r)
 	}
 }
+
+type ArchiveIdHandler func(archiveId uuid.UUID) http.HandlerFunc
+
+func ParseArchiveId(l logrus.FieldLogger, next ArchiveIdHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		archiveId, err := uuid.Parse(mux.Vars(r)["archiveId"])
+		if err != nil {
+			l.WithError(err).Errorf("Unable to properly parse archiveId from path.")
+			WriteError(l)(w)(InvalidParameter("archiveId").WithDetail(err.Error()))
+			return
+		}
+		next(archiveId)(w, r)
+	}
+}
+
+type DeadLetterIdHandler func(deadLetterId uuid.UUID) http.HandlerFunc
+
+func ParseDeadLetterId(l logrus.FieldLogger, next DeadLetterIdHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadLetterId, err := uuid.Parse(mux.Vars(r)["deadLetterId"])
+		if err != nil {
+			l.WithError(err).Errorf("Unable to properly parse deadLetterId from path.")
+			WriteError(l)(w)(InvalidParameter("deadLetterId").WithDetail(err.Error()))
+			return
+		}
+		next(deadLetterId)(w, r)
+	}
+}
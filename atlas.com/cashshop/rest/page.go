@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ParamPageNumber = "page[number]"
+	ParamPageSize   = "page[size]"
+
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Page is the window of a collection requested with the JSON:API page[number] and page[size] parameters
+// Page numbers start at 1
+type Page struct {
+	number int
+	size   int
+}
+
+// Number returns the requested page, starting at 1
+func (p Page) Number() int {
+	return p.number
+}
+
+// Size returns how many resources a page holds at most
+func (p Page) Size() int {
+	return p.size
+}
+
+// Offset returns how many resources precede the page
+func (p Page) Offset() int {
+	return (p.number - 1) * p.size
+}
+
+// ParsePage reads the page parameters of the request. Missing parameters select the first page of DefaultPageSize
+func ParsePage(r *http.Request) (Page, error) {
+	p := Page{number: 1, size: DefaultPageSize}
+	query := r.URL.Query()
+	if v := query.Get(ParamPageNumber); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return Page{}, InvalidParameter(ParamPageNumber).WithDetail("page number must be a positive integer")
+		}
+		p.number = n
+	}
+	if v := query.Get(ParamPageSize); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxPageSize {
+			return Page{}, InvalidParameter(ParamPageSize).WithDetail("page size must be between 1 and " + strconv.Itoa(MaxPageSize))
+		}
+		p.size = n
+	}
+	return p, nil
+}
+
+// MarshalPage writes a page of a collection, with the total in meta and links to the first, previous, next and last pages
+func MarshalPage[A any](l logrus.FieldLogger) func(w http.ResponseWriter) func(si jsonapi.ServerInformation) func(r *http.Request) func(p Page, total int64) func(slice A) {
+	return func(w http.ResponseWriter) func(si jsonapi.ServerInformation) func(r *http.Request) func(p Page, total int64) func(slice A) {
+		return func(si jsonapi.ServerInformation) func(r *http.Request) func(p Page, total int64) func(slice A) {
+			return func(r *http.Request) func(p Page, total int64) func(slice A) {
+				return func(p Page, total int64) func(slice A) {
+					return func(slice A) {
+						doc, err := jsonapi.MarshalToStruct(slice, si)
+						if err != nil {
+							l.WithError(err).Errorf("Marshalling page.")
+							WriteError(l)(w)(err)
+							return
+						}
+
+						last := int((total + int64(p.Size()) - 1) / int64(p.Size()))
+						if last < 1 {
+							last = 1
+						}
+						link := func(number int) jsonapi.Link {
+							query := r.URL.Query()
+							query.Set(ParamPageNumber, strconv.Itoa(number))
+							query.Set(ParamPageSize, strconv.Itoa(p.Size()))
+							return jsonapi.Link{Href: si.GetBaseURL() + r.URL.Path + "?" + query.Encode()}
+						}
+						doc.Links = jsonapi.Links{
+							"self":  link(p.Number()),
+							"first": link(1),
+							"last":  link(last),
+						}
+						if p.Number() > 1 {
+							doc.Links["prev"] = link(p.Number() - 1)
+						}
+						if p.Number() < last {
+							doc.Links["next"] = link(p.Number() + 1)
+						}
+						doc.Meta = map[string]interface{}{"total": total}
+
+						w.Header().Set("Content-Type", "application/json")
+						w.WriteHeader(http.StatusOK)
+						if err = json.NewEncoder(w).Encode(doc); err != nil {
+							l.WithError(err).Errorf("Writing page.")
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type pageServer struct{}
+
+func (s pageServer) GetBaseURL() string {
+	return ""
+}
+
+func (s pageServer) GetPrefix() string {
+	return "/api/"
+}
+
+type pageResource struct {
+	Id string `json:"-"`
+}
+
+func (r pageResource) GetName() string {
+	return "resources"
+}
+
+func (r pageResource) GetID() string {
+	return r.Id
+}
+
+func TestParsePage(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/resources?page[number]=3&page[size]=5", nil)
+	p, err := ParsePage(r)
+	if err != nil {
+		t.Fatalf("Unable to parse page: %v", err)
+	}
+	if p.Number() != 3 || p.Size() != 5 || p.Offset() != 10 {
+		t.Errorf("Unexpected page [%d] of size [%d] at offset [%d]", p.Number(), p.Size(), p.Offset())
+	}
+
+	for _, q := range []string{"page[number]=0", "page[size]=1000", "page[size]=x"} {
+		if _, err = ParsePage(httptest.NewRequest(http.MethodGet, "/resources?"+q, nil)); err == nil {
+			t.Errorf("Expected [%s] to be rejected", q)
+		}
+	}
+}
+
+func TestMarshalPage(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/resources?sort=-expiration&page[number]=2&page[size]=2", nil)
+	p, err := ParsePage(r)
+	if err != nil {
+		t.Fatalf("Unable to parse page: %v", err)
+	}
+	w := httptest.NewRecorder()
+	MarshalPage[[]pageResource](logrus.New())(w)(pageServer{})(r)(p, 5)([]pageResource{{Id: "3"}, {Id: "4"}})
+
+	var doc struct {
+		Data  []struct{ Id string } `json:"data"`
+		Links map[string]string     `json:"links"`
+		Meta  struct{ Total int64 } `json:"meta"`
+	}
+	if err = json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Unable to decode page: %v", err)
+	}
+	if len(doc.Data) != 2 || doc.Meta.Total != 5 {
+		t.Errorf("Expected 2 of 5 resources, got [%d] of [%d]", len(doc.Data), doc.Meta.Total)
+	}
+	for name, number := range map[string]string{"self": "2", "first": "1", "prev": "1", "next": "3", "last": "3"} {
+		u, err := url.Parse(doc.Links[name])
+		if err != nil || u.Query().Get("page[number]") != number || u.Query().Get("sort") != "-expiration" {
+			t.Errorf("Expected [%s] link to page [%s] keeping the sort, got [%s]", name, number, doc.Links[name])
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+)
+
+// MarshalCreated writes a resource created by a POST request with 201
+func MarshalCreated[A any](l logrus.FieldLogger) func(w http.ResponseWriter) func(si jsonapi.ServerInformation) func(slice A) {
+	return func(w http.ResponseWriter) func(si jsonapi.ServerInformation) func(slice A) {
+		return func(si jsonapi.ServerInformation) func(slice A) {
+			return func(slice A) {
+				doc, err := jsonapi.MarshalToStruct(slice, si)
+				if err != nil {
+					l.WithError(err).Errorf("Marshalling created resource.")
+					WriteError(l)(w)(err)
+					return
+				}
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				w.WriteHeader(http.StatusCreated)
+				if err = json.NewEncoder(w).Encode(doc); err != nil {
+					l.WithError(err).Errorf("Writing created resource.")
+				}
+			}
+		}
+	}
+}
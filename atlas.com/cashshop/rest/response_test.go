@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMarshalCreated(t *testing.T) {
+	w := httptest.NewRecorder()
+	MarshalCreated[pageResource](logrus.New())(w)(pageServer{})(pageResource{Id: "7"})
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status [%d], got [%d]", http.StatusCreated, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.api+json" {
+		t.Errorf("Expected JSON:API content type, got [%s]", ct)
+	}
+	var doc struct {
+		Data struct {
+			Id   string `json:"id"`
+			Type string `json:"type"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Unable to decode document: %v", err)
+	}
+	if doc.Data.Id != "7" || doc.Data.Type != "resources" {
+		t.Errorf("Unexpected created resource %+v", doc.Data)
+	}
+}
@@ -0,0 +1,70 @@
+package stub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/jtumidanski/api2go/jsonapi"
+)
+
+// Server stands in for a remote REST service such as CHARACTERS or DATA, serving the JSON:API documents put to it
+// Paths the server holds no document for answer 404, like a missing resource would
+type Server struct {
+	s         *httptest.Server
+	mu        sync.RWMutex
+	documents map[string][]byte
+}
+
+// NewServer starts a server, which is to be closed once no longer needed
+func NewServer() *Server {
+	s := &Server{documents: make(map[string][]byte)}
+	s.s = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// BaseUrl returns the root URL of the server, as the service's environment variable would hold it
+func (s *Server) BaseUrl() string {
+	return s.s.URL + "/"
+}
+
+// Put serves the document of a resource at the path, relative to the base URL
+func (s *Server) Put(path string, data interface{}) error {
+	b, err := jsonapi.Marshal(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[strings.TrimPrefix(path, "/")] = b
+	return nil
+}
+
+// Remove stops serving the resource at the path
+func (s *Server) Remove(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.documents, strings.TrimPrefix(path, "/"))
+}
+
+func (s *Server) Close() {
+	s.s.Close()
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.RLock()
+	b, ok := s.documents[strings.TrimPrefix(r.URL.Path, "/")]
+	s.mu.RUnlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
@@ -2,22 +2,73 @@ package retry
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
+var ErrMaxRetries = errors.New("max retry reached")
+
 type TryFunc func(attempt int) (retry bool, err error)
 
+// Config describes how many attempts are made and how long to wait between them
+type Config struct {
+	retries      int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	multiplier   float64
+}
+
+// NewConfig creates a Config which waits initialDelay after the first failure and multiplies the delay up to maxDelay
+func NewConfig(retries int, initialDelay time.Duration, maxDelay time.Duration, multiplier float64) Config {
+	return Config{
+		retries:      retries,
+		initialDelay: initialDelay,
+		maxDelay:     maxDelay,
+		multiplier:   multiplier,
+	}
+}
+
+func (c Config) Retries() int {
+	return c.retries
+}
+
+// Delay returns how long to wait after the given failed attempt
+func (c Config) Delay(attempt int) time.Duration {
+	d := c.initialDelay
+	for i := 1; i < attempt; i++ {
+		d = time.Duration(float64(d) * c.multiplier)
+		if d >= c.maxDelay {
+			return c.maxDelay
+		}
+	}
+	if d > c.maxDelay {
+		return c.maxDelay
+	}
+	return d
+}
+
+// Try calls fn until it succeeds, asks not to be retried, or retries attempts were made, waiting a second between attempts
+// Like TryWithConfig, an error fn asks not to retry is returned rather than treated as success
 func Try(fn TryFunc, retries int) error {
+	return TryWithConfig(fn, NewConfig(retries, time.Second, time.Second, 1))
+}
+
+// TryWithConfig calls fn until it succeeds, asks not to be retried, or the configured attempts were made
+// An error fn asks not to retry is returned as is. Exhausting the attempts returns ErrMaxRetries wrapping the last error
+func TryWithConfig(fn TryFunc, c Config) error {
 	attempt := 1
 	for {
 		cont, err := fn(attempt)
-		if !cont || err == nil {
+		if err == nil {
 			return nil
 		}
-		attempt++
-		if attempt > retries {
-			return errors.New("max retry reached")
+		if !cont {
+			return err
+		}
+		if attempt >= c.retries {
+			return fmt.Errorf("%w: %w", ErrMaxRetries, err)
 		}
-		time.Sleep(1 * time.Second)
+		time.Sleep(c.Delay(attempt))
+		attempt++
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfig_Delay(t *testing.T) {
+	c := NewConfig(5, 10*time.Millisecond, 50*time.Millisecond, 2)
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond}
+	for i, e := range expected {
+		if d := c.Delay(i + 1); d != e {
+			t.Errorf("Expected delay [%s] after attempt [%d], got [%s]", e, i+1, d)
+		}
+	}
+}
+
+func TestTryWithConfig(t *testing.T) {
+	c := NewConfig(3, time.Millisecond, time.Millisecond, 1)
+	cause := errors.New("transient")
+
+	attempts := 0
+	err := TryWithConfig(func(attempt int) (bool, error) {
+		attempts = attempt
+		if attempt < 2 {
+			return true, cause
+		}
+		return false, nil
+	}, c)
+	if err != nil || attempts != 2 {
+		t.Errorf("Expected success on attempt 2, got [%v] after [%d] attempts", err, attempts)
+	}
+
+	err = TryWithConfig(func(attempt int) (bool, error) {
+		attempts = attempt
+		return true, cause
+	}, c)
+	if !errors.Is(err, ErrMaxRetries) || !errors.Is(err, cause) || attempts != 3 {
+		t.Errorf("Expected max retries wrapping the cause after 3 attempts, got [%v] after [%d] attempts", err, attempts)
+	}
+
+	err = TryWithConfig(func(attempt int) (bool, error) {
+		attempts = attempt
+		return false, cause
+	}, c)
+	if !errors.Is(err, cause) || attempts != 1 {
+		t.Errorf("Expected permanent failure after 1 attempt, got [%v] after [%d] attempts", err, attempts)
+	}
+}
+
+func TestTry(t *testing.T) {
+	cause := errors.New("permanent")
+	err := Try(func(attempt int) (bool, error) {
+		return false, cause
+	}, 3)
+	if !errors.Is(err, cause) {
+		t.Errorf("Expected an error not to be retried to be returned, got [%v]", err)
+	}
+}
@@ -0,0 +1,36 @@
+package tasks
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+// Task is a unit of background work which is executed periodically.
+type Task interface {
+	Run()
+	SleepTime() time.Duration
+}
+
+// Register starts executing the task on its own goroutine until the context is cancelled.
+func Register(l logrus.FieldLogger, ctx context.Context, wg *sync.WaitGroup) func(t Task) {
+	return func(t Task) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(t.SleepTime())
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					l.Debugf("Stopping task execution.")
+					return
+				case <-ticker.C:
+					t.Run()
+				}
+			}
+		}()
+	}
+}
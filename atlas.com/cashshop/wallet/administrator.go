@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"context"
+	"errors"
 	"github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -17,6 +18,9 @@ func createEntity(db *gorm.DB, t tenant.Model, accountId uint32, credit uint32,
 	}
 
 	err := db.Create(e).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return Model{}, ErrWalletExists
+	}
 	if err != nil {
 		return Model{}, err
 	}
@@ -1,18 +1,15 @@
 package wallet
 
 import (
+	"atlas-cashshop/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-func Migration(db *gorm.DB) error {
-	return db.AutoMigrate(&Entity{})
-}
-
 type Entity struct {
 	Id        uuid.UUID `gorm:"primaryKey;type:uuid"`
-	TenantId  uuid.UUID `gorm:"not null"`
-	AccountId uint32    `gorm:"not null"`
+	TenantId  uuid.UUID `gorm:"not null;uniqueIndex:idx_accounts_tenant_account"`
+	AccountId uint32    `gorm:"not null;uniqueIndex:idx_accounts_tenant_account"`
 	Credit    uint32    `gorm:"not null;default=0"`
 	Points    uint32    `gorm:"not null;default=0"`
 	Prepaid   uint32    `gorm:"not null;default=0"`
@@ -22,11 +19,9 @@ func (e Entity) TableName() string {
 	return "accounts"
 }
 
-func (e *Entity) BeforeCreate(_ *gorm.DB) (err error) {
-	if e.Id == uuid.Nil {
-		e.Id = uuid.New()
-	}
-	return
+// BeforeCreate assigns the ID, so it does not depend on a database generated default
+func (e *Entity) BeforeCreate(_ *gorm.DB) error {
+	return database.AssignId(&e.Id)
 }
 
 func Make(e Entity) (Model, error) {
@@ -2,6 +2,16 @@ package wallet
 
 import "github.com/google/uuid"
 
+const (
+	CurrencyCredit = uint32(1)
+	CurrencyPoints = uint32(2)
+)
+
+// ValidCurrency reports whether a purchase may be paid in the currency. Prepaid balances are not spent on purchases
+func ValidCurrency(currency uint32) bool {
+	return currency == CurrencyCredit || currency == CurrencyPoints
+}
+
 type Model struct {
 	id        uuid.UUID
 	accountId uint32
@@ -31,9 +41,9 @@ func (m Model) Prepaid() uint32 {
 }
 
 func (m Model) Balance(currency uint32) uint32 {
-	if currency == 1 {
+	if currency == CurrencyCredit {
 		return m.credit
-	} else if currency == 2 {
+	} else if currency == CurrencyPoints {
 		return m.points
 	} else {
 		return m.prepaid
@@ -44,9 +54,9 @@ func (m Model) Purchase(currency uint32, amount uint32) Model {
 	newCredit := m.credit
 	newPoints := m.points
 	newPrepaid := m.prepaid
-	if currency == 1 {
+	if currency == CurrencyCredit {
 		newCredit -= amount
-	} else if currency == 2 {
+	} else if currency == CurrencyPoints {
 		newPoints -= amount
 	} else {
 		newPrepaid -= amount
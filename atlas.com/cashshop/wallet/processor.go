@@ -1,17 +1,23 @@
 package wallet
 
 import (
+	"atlas-cashshop/correlation"
+	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
 	"atlas-cashshop/kafka/message/wallet"
-	"atlas-cashshop/kafka/producer"
 	wallet2 "atlas-cashshop/kafka/producer/wallet"
+	"atlas-cashshop/outbox"
 	"context"
+	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// ErrWalletExists is returned when creating a wallet for an account which already has one
+var ErrWalletExists = errors.New("account already has a wallet")
+
 type Processor interface {
 	WithTransaction(tx *gorm.DB) Processor
 	ByAccountIdProvider(accountId uint32) model.Provider[Model]
@@ -29,7 +35,6 @@ type ProcessorImpl struct {
 	ctx context.Context
 	db  *gorm.DB
 	t   tenant.Model
-	p   producer.Provider
 }
 
 func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
@@ -38,7 +43,6 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 		ctx: ctx,
 		db:  db,
 		t:   tenant.MustFromContext(ctx),
-		p:   producer.ProviderImpl(l)(ctx),
 	}
 	return p
 }
@@ -49,12 +53,11 @@ func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
 		ctx: p.ctx,
 		db:  tx,
 		t:   p.t,
-		p:   p.p,
 	}
 }
 
 func (p *ProcessorImpl) ByAccountIdProvider(accountId uint32) model.Provider[Model] {
-	return model.Map(Make)(byAccountIdEntityProvider(p.t.Id(), accountId)(p.db))
+	return model.Map(Make)(byAccountIdEntityProvider(p.t.Id(), accountId)(database.Read(p.ctx, p.db)))
 }
 
 func (p *ProcessorImpl) GetByAccountId(accountId uint32) (Model, error) {
@@ -73,7 +76,7 @@ func (p *ProcessorImpl) Create(mb *message.Buffer) func(accountId uint32) func(c
 						return Model{}, err
 					}
 
-					_ = mb.Put(wallet.EnvEventTopicStatus, wallet2.CreateStatusEventProvider(accountId, credit, points, prepaid))
+					_ = mb.Put(wallet.EnvEventTopicStatus, wallet2.CreateStatusEventProvider(correlation.RequestId(p.ctx), accountId, credit, points, prepaid))
 					return c, err
 				}
 			}
@@ -82,7 +85,9 @@ func (p *ProcessorImpl) Create(mb *message.Buffer) func(accountId uint32) func(c
 }
 
 func (p *ProcessorImpl) CreateAndEmit(accountId uint32, credit uint32, points uint32, prepaid uint32) (Model, error) {
-	return message.EmitWithResult[Model, uint32](p.p)(model.Flip(model.Flip(model.Flip(p.Create)(accountId))(credit))(points))(prepaid)
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.WithTransaction(tx).Create(buf)(accountId)(credit)(points)(prepaid)
+	})
 }
 
 func (p *ProcessorImpl) Update(mb *message.Buffer) func(accountId uint32) func(credit uint32) func(points uint32) func(prepaid uint32) (Model, error) {
@@ -97,7 +102,7 @@ func (p *ProcessorImpl) Update(mb *message.Buffer) func(accountId uint32) func(c
 						return Model{}, err
 					}
 
-					_ = mb.Put(wallet.EnvEventTopicStatus, wallet2.UpdateStatusEventProvider(accountId, credit, points, prepaid))
+					_ = mb.Put(wallet.EnvEventTopicStatus, wallet2.UpdateStatusEventProvider(correlation.RequestId(p.ctx), accountId, credit, points, prepaid))
 					return c, err
 				}
 			}
@@ -106,7 +111,9 @@ func (p *ProcessorImpl) Update(mb *message.Buffer) func(accountId uint32) func(c
 }
 
 func (p *ProcessorImpl) UpdateAndEmit(accountId uint32, credit uint32, points uint32, prepaid uint32) (Model, error) {
-	return message.EmitWithResult[Model, uint32](p.p)(model.Flip(model.Flip(model.Flip(p.Update)(accountId))(credit))(points))(prepaid)
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.WithTransaction(tx).Update(buf)(accountId)(credit)(points)(prepaid)
+	})
 }
 
 func (p *ProcessorImpl) Delete(mb *message.Buffer) func(accountId uint32) error {
@@ -117,11 +124,13 @@ func (p *ProcessorImpl) Delete(mb *message.Buffer) func(accountId uint32) error
 			return err
 		}
 
-		_ = mb.Put(wallet.EnvEventTopicStatus, wallet2.DeleteStatusEventProvider(accountId))
+		_ = mb.Put(wallet.EnvEventTopicStatus, wallet2.DeleteStatusEventProvider(correlation.RequestId(p.ctx), accountId))
 		return nil
 	}
 }
 
 func (p *ProcessorImpl) DeleteAndEmit(accountId uint32) error {
-	return message.Emit(p.p)(model.Flip(p.Delete)(accountId))
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.WithTransaction(tx).Delete(buf)(accountId)
+	})
 }
@@ -2,7 +2,6 @@ package wallet
 
 import (
 	"atlas-cashshop/rest"
-	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/server"
 	"github.com/gorilla/mux"
@@ -15,6 +14,8 @@ import (
 func InitResource(si jsonapi.ServerInformation) func(db *gorm.DB) server.RouteInitializer {
 	return func(db *gorm.DB) server.RouteInitializer {
 		return func(router *mux.Router, l logrus.FieldLogger) {
+			rest.RegisterError(ErrWalletExists, http.StatusConflict, "WALLET_EXISTS", "Wallet already exists")
+
 			registerGet := rest.RegisterHandler(l)(si)
 			r := router.PathPrefix("/accounts/{accountId}/wallet").Subrouter()
 			r.HandleFunc("", registerGet("get_wallet", handleGetWallet(db))).Methods(http.MethodGet)
@@ -29,19 +30,15 @@ func handleGetWallet(db *gorm.DB) rest.GetHandler {
 		return rest.ParseAccountId(d.Logger(), func(accountId uint32) http.HandlerFunc {
 			return func(w http.ResponseWriter, r *http.Request) {
 				m, err := NewProcessor(d.Logger(), d.Context(), db).GetByAccountId(accountId)
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					w.WriteHeader(http.StatusNotFound)
-					return
-				}
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
 				res, err := model.Map(Transform)(model.FixedProvider(m))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -59,14 +56,14 @@ func handleCreateWallet(db *gorm.DB) rest.InputHandler[RestModel] {
 			return func(w http.ResponseWriter, r *http.Request) {
 				m, err := NewProcessor(d.Logger(), d.Context(), db).CreateAndEmit(accountId, input.Credit, input.Points, input.Prepaid)
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
 				res, err := model.Map(Transform)(model.FixedProvider(m))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -84,14 +81,14 @@ func handleUpdateWallet(db *gorm.DB) rest.InputHandler[RestModel] {
 			return func(w http.ResponseWriter, r *http.Request) {
 				m, err := NewProcessor(d.Logger(), d.Context(), db).UpdateAndEmit(accountId, input.Credit, input.Points, input.Prepaid)
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
 				res, err := model.Map(Transform)(model.FixedProvider(m))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -1,18 +1,15 @@
 package wishlist
 
 import (
+	"atlas-cashshop/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-func Migration(db *gorm.DB) error {
-	return db.AutoMigrate(&Entity{})
-}
-
 type Entity struct {
-	Id           uuid.UUID `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	TenantId     uuid.UUID `gorm:"not null"`
-	CharacterId  uint32    `gorm:"not null"`
+	Id           uuid.UUID `gorm:"primaryKey;type:uuid"`
+	TenantId     uuid.UUID `gorm:"not null;index:idx_wishlist_items_tenant_character"`
+	CharacterId  uint32    `gorm:"not null;index:idx_wishlist_items_tenant_character"`
 	SerialNumber uint32    `gorm:"not null"`
 }
 
@@ -20,6 +17,11 @@ func (e Entity) TableName() string {
 	return "wishlist_items"
 }
 
+// BeforeCreate assigns the ID, so it does not depend on a database generated default
+func (e *Entity) BeforeCreate(_ *gorm.DB) error {
+	return database.AssignId(&e.Id)
+}
+
 func Make(e Entity) (Model, error) {
 	return Model{
 		id:           e.Id,
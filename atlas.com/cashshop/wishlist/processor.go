@@ -1,10 +1,11 @@
 package wishlist
 
 import (
+	"atlas-cashshop/database"
 	"atlas-cashshop/kafka/message"
 	"atlas-cashshop/kafka/message/wishlist"
-	"atlas-cashshop/kafka/producer"
 	wishlist2 "atlas-cashshop/kafka/producer/wishlist"
+	"atlas-cashshop/outbox"
 	"context"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-tenant"
@@ -14,6 +15,7 @@ import (
 )
 
 type Processor interface {
+	WithTransaction(tx *gorm.DB) Processor
 	ByCharacterIdProvider(characterId uint32) model.Provider[[]Model]
 	GetByCharacterId(characterId uint32) ([]Model, error)
 	Add(mb *message.Buffer) func(characterId uint32) func(serialNumber uint32) (Model, error)
@@ -29,7 +31,6 @@ type ProcessorImpl struct {
 	ctx context.Context
 	db  *gorm.DB
 	t   tenant.Model
-	p   producer.Provider
 }
 
 func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Processor {
@@ -38,13 +39,21 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context, db *gorm.DB) Proces
 		ctx: ctx,
 		db:  db,
 		t:   tenant.MustFromContext(ctx),
-		p:   producer.ProviderImpl(l)(ctx),
 	}
 	return p
 }
 
+func (p *ProcessorImpl) WithTransaction(tx *gorm.DB) Processor {
+	return &ProcessorImpl{
+		l:   p.l,
+		ctx: p.ctx,
+		db:  tx,
+		t:   p.t,
+	}
+}
+
 func (p *ProcessorImpl) ByCharacterIdProvider(characterId uint32) model.Provider[[]Model] {
-	return model.SliceMap(Make)(byCharacterIdEntityProvider(p.t.Id(), characterId)(p.db))(model.ParallelMap())
+	return model.SliceMap(Make)(byCharacterIdEntityProvider(p.t.Id(), characterId)(database.Read(p.ctx, p.db)))(model.ParallelMap())
 }
 
 func (p *ProcessorImpl) GetByCharacterId(characterId uint32) ([]Model, error) {
@@ -68,7 +77,9 @@ func (p *ProcessorImpl) Add(mb *message.Buffer) func(characterId uint32) func(se
 }
 
 func (p *ProcessorImpl) AddAndEmit(characterId uint32, serialNumber uint32) (Model, error) {
-	return message.EmitWithResult[Model, uint32](p.p)(model.Flip(p.Add)(characterId))(serialNumber)
+	return outbox.EmitWithResult[Model](p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) (Model, error) {
+		return p.WithTransaction(tx).Add(buf)(characterId)(serialNumber)
+	})
 }
 
 func (p *ProcessorImpl) Delete(mb *message.Buffer) func(characterId uint32) func(itemId uuid.UUID) error {
@@ -87,7 +98,9 @@ func (p *ProcessorImpl) Delete(mb *message.Buffer) func(characterId uint32) func
 }
 
 func (p *ProcessorImpl) DeleteAndEmit(characterId uint32, itemId uuid.UUID) error {
-	return message.Emit(p.p)(model.Flip(model.Flip(p.Delete)(characterId))(itemId))
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.WithTransaction(tx).Delete(buf)(characterId)(itemId)
+	})
 }
 
 func (p *ProcessorImpl) DeleteAll(mb *message.Buffer) func(characterId uint32) error {
@@ -104,5 +117,7 @@ func (p *ProcessorImpl) DeleteAll(mb *message.Buffer) func(characterId uint32) e
 }
 
 func (p *ProcessorImpl) DeleteAllAndEmit(characterId uint32) error {
-	return message.Emit(p.p)(model.Flip(p.DeleteAll)(characterId))
+	return outbox.Emit(p.l, p.ctx, p.db)(func(tx *gorm.DB, buf *message.Buffer) error {
+		return p.WithTransaction(tx).DeleteAll(buf)(characterId)
+	})
 }
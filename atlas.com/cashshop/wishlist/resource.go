@@ -2,7 +2,6 @@ package wishlist
 
 import (
 	"atlas-cashshop/rest"
-	"errors"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/server"
 	"github.com/google/uuid"
@@ -31,19 +30,15 @@ func handleGetWishlist(db *gorm.DB) rest.GetHandler {
 		return rest.ParseCharacterId(d.Logger(), func(characterId uint32) http.HandlerFunc {
 			return func(w http.ResponseWriter, r *http.Request) {
 				ms, err := NewProcessor(d.Logger(), d.Context(), db).GetByCharacterId(characterId)
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					w.WriteHeader(http.StatusNotFound)
-					return
-				}
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
 				res, err := model.SliceMap(Transform)(model.FixedProvider(ms))(model.ParallelMap())()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -59,16 +54,21 @@ func handleAddToWishlist(db *gorm.DB) rest.InputHandler[RestModel] {
 	return func(d *rest.HandlerDependency, c *rest.HandlerContext, input RestModel) http.HandlerFunc {
 		return rest.ParseCharacterId(d.Logger(), func(characterId uint32) http.HandlerFunc {
 			return func(w http.ResponseWriter, r *http.Request) {
+				if input.SerialNumber == 0 {
+					rest.WriteError(d.Logger())(w)(rest.InvalidAttribute("serialNumber", "a wishlist item needs the serial number of a commodity"))
+					return
+				}
+
 				m, err := NewProcessor(d.Logger(), d.Context(), db).AddAndEmit(characterId, input.SerialNumber)
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
 				res, err := model.Map(Transform)(model.FixedProvider(m))()
 				if err != nil {
 					d.Logger().WithError(err).Errorf("Creating REST model.")
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 
@@ -86,7 +86,7 @@ func handleClearWishlist(db *gorm.DB) rest.GetHandler {
 			return func(w http.ResponseWriter, r *http.Request) {
 				err := NewProcessor(d.Logger(), d.Context(), db).DeleteAllAndEmit(characterId)
 				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
+					rest.WriteError(d.Logger())(w)(err)
 					return
 				}
 				w.WriteHeader(http.StatusNoContent)
@@ -102,7 +102,7 @@ func handleRemoveFromWishlist(db *gorm.DB) rest.GetHandler {
 				return func(w http.ResponseWriter, r *http.Request) {
 					err := NewProcessor(d.Logger(), d.Context(), db).DeleteAndEmit(characterId, itemId)
 					if err != nil {
-						w.WriteHeader(http.StatusInternalServerError)
+						rest.WriteError(d.Logger())(w)(err)
 						return
 					}
 					w.WriteHeader(http.StatusNoContent)